@@ -155,7 +155,7 @@ func ConfigWizard(base string) {
 		dres := msg.PromptUntilYorN()
 		if dres {
 			msg.Info("Writing updates to configuration file (%s)", glidefile)
-			if err := conf.WriteFile(glidefile); err != nil {
+			if err := conf.UpdateFile(glidefile); err != nil {
 				msg.Die("Could not save %s: %s", glidefile, err)
 			}
 			msg.Info("You can now edit the glide.yaml file.:")
@@ -0,0 +1,343 @@
+package action
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Masterminds/glide/cache"
+	"github.com/Masterminds/glide/cfg"
+	"github.com/Masterminds/glide/msg"
+	gpath "github.com/Masterminds/glide/path"
+	"github.com/Masterminds/glide/repo"
+	"github.com/Masterminds/semver"
+	"github.com/Masterminds/vcs"
+)
+
+const markdownFormat = "markdown"
+
+// Diff lists the dependencies added, removed, upgraded, and downgraded between
+// two lock files.
+//
+// Each lock file is given as a path or as a git revision the project's
+// glide.lock file is read from. The tags and commit subjects of changed
+// dependencies are read from the repositories in the Glide cache. Nothing is
+// fetched so dependencies missing from the cache are listed without them.
+//
+// Params:
+//  - from (string): The old lock file or revision. Defaults to HEAD
+//  - to (string): The new lock file or revision. Defaults to the glide.lock file
+//  - format (string): The format to output (text, markdown, json, json-pretty)
+func Diff(from, to, format string) {
+	base := "."
+	if yamlpath, err := gpath.Glide(); err == nil {
+		base = filepath.Dir(yamlpath)
+	}
+	if from == "" {
+		from = "HEAD"
+	}
+	if to == "" {
+		to = filepath.Join(base, gpath.LockFile)
+	}
+
+	old, err := loadDiffLock(from, base)
+	if err != nil {
+		msg.Die("Unable to load the lock file for %s: %s", from, err)
+	}
+	cur, err := loadDiffLock(to, base)
+	if err != nil {
+		msg.Die("Unable to load the lock file for %s: %s", to, err)
+	}
+
+	outputDiff(diffLocks(old, cur), format)
+}
+
+// LockDiff is the difference between two lock files. Dependencies that changed
+// version where the direction of the change could not be determined are listed
+// as changed.
+type LockDiff struct {
+	Added      []DepChange `json:"added"`
+	Removed    []DepChange `json:"removed"`
+	Upgraded   []DepChange `json:"upgraded"`
+	Downgraded []DepChange `json:"downgraded"`
+	Changed    []DepChange `json:"changed"`
+}
+
+// DepChange is a dependency that differs between two lock files. The commits
+// are the subjects of the commits between the two versions, newest first.
+type DepChange struct {
+	Name       string   `json:"name"`
+	OldVersion string   `json:"oldVersion,omitempty"`
+	NewVersion string   `json:"newVersion,omitempty"`
+	OldTag     string   `json:"oldTag,omitempty"`
+	NewTag     string   `json:"newTag,omitempty"`
+	Commits    []string `json:"commits,omitempty"`
+}
+
+// Empty returns true if the lock files lock the same versions.
+func (d *LockDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Upgraded) == 0 && len(d.Downgraded) == 0 && len(d.Changed) == 0
+}
+
+// loadDiffLock reads a lock file from a path or, when there is no such file,
+// from a git revision.
+func loadDiffLock(ref, base string) (*cfg.Lockfile, error) {
+	if fi, err := os.Stat(ref); err == nil {
+		if fi.IsDir() {
+			ref = filepath.Join(ref, gpath.LockFile)
+		}
+		return cfg.ReadLockFile(ref)
+	}
+
+	// The ./ makes the path relative to the directory of the project rather
+	// than the top of the repository.
+	cmd := exec.Command("git", "show", ref+":./"+gpath.LockFile)
+	cmd.Dir = base
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("%s is not a file or a git revision with a %s file: %s", ref, gpath.LockFile, strings.TrimSpace(string(out)))
+	}
+	return cfg.LockfileFromYaml(out)
+}
+
+// diffLocks compares the locked versions in every section of two lock files.
+func diffLocks(old, cur *cfg.Lockfile) *LockDiff {
+	d := &LockDiff{
+		Added:      []DepChange{},
+		Removed:    []DepChange{},
+		Upgraded:   []DepChange{},
+		Downgraded: []DepChange{},
+		Changed:    []DepChange{},
+	}
+
+	ol, nl := diffLockMap(old), diffLockMap(cur)
+	names := make([]string, 0, len(ol)+len(nl))
+	for n := range ol {
+		names = append(names, n)
+	}
+	for n := range nl {
+		if _, ok := ol[n]; !ok {
+			names = append(names, n)
+		}
+	}
+	sort.Strings(names)
+
+	for _, n := range names {
+		o, c := ol[n], nl[n]
+		switch {
+		case o == nil:
+			d.Added = append(d.Added, DepChange{Name: n, NewVersion: c.Version, NewTag: lockTag(c, c.Version)})
+		case c == nil:
+			d.Removed = append(d.Removed, DepChange{Name: n, OldVersion: o.Version, OldTag: lockTag(o, o.Version)})
+		case o.Version != c.Version:
+			ch := DepChange{Name: n, OldVersion: o.Version, NewVersion: c.Version}
+			switch diffDependency(o, c, &ch) {
+			case 1:
+				d.Upgraded = append(d.Upgraded, ch)
+			case -1:
+				d.Downgraded = append(d.Downgraded, ch)
+			default:
+				d.Changed = append(d.Changed, ch)
+			}
+		}
+	}
+	return d
+}
+
+func diffLockMap(lf *cfg.Lockfile) map[string]*cfg.Lock {
+	all := []cfg.Locks{lf.Imports, lf.DevImports}
+	for _, g := range lf.Groups {
+		all = append(all, g)
+	}
+	all = append(all, lf.Tools)
+
+	m := map[string]*cfg.Lock{}
+	for _, ls := range all {
+		for _, l := range ls {
+			if _, ok := m[l.Name]; !ok {
+				m[l.Name] = l
+			}
+		}
+	}
+	return m
+}
+
+// diffRepo returns the cached repository for a locked dependency, or nil when
+// it is not in the cache.
+func diffRepo(l *cfg.Lock) vcs.Repo {
+	if l.Path != "" {
+		return nil
+	}
+	dep := cfg.DependencyFromLock(l)
+	key, err := cache.Key(dep.Remote())
+	if err != nil {
+		return nil
+	}
+	cdir := filepath.Join(cache.Location(), "src", key)
+	if _, err := os.Stat(cdir); err != nil {
+		msg.Debug("%s is not in the cache", l.Name)
+		return nil
+	}
+	r, err := dep.GetRepo(cdir)
+	if err != nil {
+		msg.Debug("Unable to open the cached repository for %s: %s", l.Name, err)
+		return nil
+	}
+	return r
+}
+
+func lockTag(l *cfg.Lock, version string) string {
+	r := diffRepo(l)
+	if r == nil {
+		return ""
+	}
+	return repo.CommitTag(r, version)
+}
+
+// diffDependency fills in the tags and commits of a dependency that changed
+// version. It returns 1 for an upgrade, -1 for a downgrade, and 0 when the
+// direction is unknown.
+//
+// Semantic version tags are compared when both versions have them. Otherwise
+// the commit dates are.
+func diffDependency(o, c *cfg.Lock, ch *DepChange) int {
+	r := diffRepo(c)
+	if r == nil {
+		return 0
+	}
+	ch.OldTag = repo.CommitTag(r, o.Version)
+	ch.NewTag = repo.CommitTag(r, c.Version)
+
+	dir := 0
+	ov, err1 := semver.NewVersion(ch.OldTag)
+	nv, err2 := semver.NewVersion(ch.NewTag)
+	if err1 == nil && err2 == nil && !ov.Equal(nv) {
+		if nv.GreaterThan(ov) {
+			dir = 1
+		} else {
+			dir = -1
+		}
+	} else {
+		oi, err1 := r.CommitInfo(o.Version)
+		ni, err2 := r.CommitInfo(c.Version)
+		if err1 == nil && err2 == nil {
+			if ni.Date.After(oi.Date) {
+				dir = 1
+			} else if ni.Date.Before(oi.Date) {
+				dir = -1
+			}
+		}
+	}
+
+	// For a downgrade the commits listed are the ones being removed.
+	from, to := o.Version, c.Version
+	if dir == -1 {
+		from, to = to, from
+	}
+	commits, err := repo.CommitSubjects(r, from, to)
+	if err != nil {
+		msg.Debug("Unable to list the commits for %s: %s", c.Name, err)
+	}
+	ch.Commits = commits
+
+	return dir
+}
+
+// diffVersion formats a version along with its tag.
+func diffVersion(version, tag string) string {
+	if tag == "" || tag == version {
+		return version
+	}
+	return fmt.Sprintf("%s (%s)", tag, version)
+}
+
+func outputDiff(d *LockDiff, format string) {
+	sections := []struct {
+		title string
+		deps  []DepChange
+	}{
+		{"ADDED", d.Added},
+		{"REMOVED", d.Removed},
+		{"UPGRADED", d.Upgraded},
+		{"DOWNGRADED", d.Downgraded},
+		{"CHANGED", d.Changed},
+	}
+
+	switch format {
+	case textFormat:
+		if d.Empty() {
+			msg.Puts("No dependencies changed")
+			return
+		}
+		for _, s := range sections {
+			if len(s.deps) == 0 {
+				continue
+			}
+			msg.Puts("%s dependencies:", s.title)
+			for _, c := range s.deps {
+				msg.Puts("\t%s", diffLine(c))
+				for _, sub := range c.Commits {
+					msg.Puts("\t\t%s", sub)
+				}
+			}
+		}
+	case markdownFormat:
+		if d.Empty() {
+			msg.Puts("No dependencies changed.")
+			return
+		}
+		for _, s := range sections {
+			if len(s.deps) == 0 {
+				continue
+			}
+			msg.Puts("### %s%s\n", s.title[:1], strings.ToLower(s.title[1:]))
+			for _, c := range s.deps {
+				msg.Puts("- %s", diffMarkdownLine(c))
+				for _, sub := range c.Commits {
+					msg.Puts("  - %s", sub)
+				}
+			}
+			msg.Puts("")
+		}
+	case jsonFormat:
+		json.NewEncoder(msg.Default.Stdout).Encode(d)
+	case jsonPrettyFormat:
+		b, err := json.MarshalIndent(d, "", "  ")
+		if err != nil {
+			msg.Die("could not marshal diff: %s", err)
+		}
+		msg.Puts("%s", b)
+	default:
+		msg.Die("invalid output format: must be one of: json|json-pretty|markdown|text")
+	}
+}
+
+func diffLine(c DepChange) string {
+	switch {
+	case c.OldVersion == "":
+		return fmt.Sprintf("%s %s", c.Name, diffVersion(c.NewVersion, c.NewTag))
+	case c.NewVersion == "":
+		return fmt.Sprintf("%s %s", c.Name, diffVersion(c.OldVersion, c.OldTag))
+	}
+	return fmt.Sprintf("%s %s -> %s", c.Name, diffVersion(c.OldVersion, c.OldTag), diffVersion(c.NewVersion, c.NewTag))
+}
+
+func diffMarkdownLine(c DepChange) string {
+	v := func(version, tag string) string {
+		if tag == "" || tag == version {
+			return "`" + version + "`"
+		}
+		return fmt.Sprintf("%s (`%s`)", tag, version)
+	}
+	switch {
+	case c.OldVersion == "":
+		return fmt.Sprintf("**%s** %s", c.Name, v(c.NewVersion, c.NewTag))
+	case c.NewVersion == "":
+		return fmt.Sprintf("**%s** %s", c.Name, v(c.OldVersion, c.OldTag))
+	}
+	return fmt.Sprintf("**%s** %s -> %s", c.Name, v(c.OldVersion, c.OldTag), v(c.NewVersion, c.NewTag))
+}
@@ -0,0 +1,46 @@
+package action
+
+import (
+	"testing"
+
+	"github.com/Masterminds/glide/cfg"
+)
+
+func TestDiffLocks(t *testing.T) {
+	old := &cfg.Lockfile{
+		Imports: cfg.Locks{
+			{Name: "github.com/foo/same", Version: "a1"},
+			{Name: "github.com/foo/changed", Version: "b1"},
+			{Name: "github.com/foo/removed", Version: "c1"},
+		},
+	}
+	cur := &cfg.Lockfile{
+		Imports: cfg.Locks{
+			{Name: "github.com/foo/same", Version: "a1"},
+			{Name: "github.com/foo/changed", Version: "b2", Path: "../changed"},
+		},
+		Tools: cfg.Locks{
+			{Name: "github.com/foo/added", Version: "d1", Path: "../added"},
+		},
+	}
+
+	d := diffLocks(old, cur)
+	if len(d.Added) != 1 || d.Added[0].Name != "github.com/foo/added" || d.Added[0].NewVersion != "d1" {
+		t.Errorf("Unexpected added dependencies %v", d.Added)
+	}
+	if len(d.Removed) != 1 || d.Removed[0].Name != "github.com/foo/removed" || d.Removed[0].OldVersion != "c1" {
+		t.Errorf("Unexpected removed dependencies %v", d.Removed)
+	}
+
+	// Without a repository the direction of a change is not known.
+	if len(d.Changed) != 1 || d.Changed[0].OldVersion != "b1" || d.Changed[0].NewVersion != "b2" {
+		t.Errorf("Unexpected changed dependencies %v", d.Changed)
+	}
+	if len(d.Upgraded) != 0 || len(d.Downgraded) != 0 {
+		t.Error("Expected no upgrades or downgrades")
+	}
+
+	if !diffLocks(old, old).Empty() {
+		t.Error("Expected no differences between a lock file and itself")
+	}
+}
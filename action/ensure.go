@@ -35,6 +35,10 @@ func EnsureConfig() *cfg.Config {
 		msg.ExitCode(3)
 		msg.Die("Failed to parse %s: %s", yamlpath, err)
 	}
+	if err := conf.Extend(filepath.Dir(yamlpath), gpath.Home()); err != nil {
+		msg.ExitCode(3)
+		msg.Die("Failed to load the files extended by %s: %s", yamlpath, err)
+	}
 
 	b := filepath.Dir(yamlpath)
 	buildContext, err := util.GetBuildContext()
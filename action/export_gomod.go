@@ -0,0 +1,246 @@
+package action
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/glide/cache"
+	"github.com/Masterminds/glide/cfg"
+	"github.com/Masterminds/glide/gomod"
+	"github.com/Masterminds/glide/msg"
+	gpath "github.com/Masterminds/glide/path"
+	"github.com/Masterminds/semver"
+	"github.com/Masterminds/vcs"
+)
+
+// ExportGomod writes go.mod and go.sum files based on the glide.yaml and
+// glide.lock files.
+//
+// Locked commits are mapped to module versions using the tags and commit dates
+// of the repositories in the Glide cache. Nothing is fetched so a `glide
+// install` should be run first to make sure the cache is populated. To generate
+// the go.sum hashes each repository is checked out at its locked version and
+// then put back at the version it had before.
+//
+// Params:
+//  - goVersion (string): The version used for the go directive
+//  - skipSum (bool): Do not generate a go.sum file
+//  - force (bool): Overwrite existing go.mod and go.sum files
+func ExportGomod(goVersion string, skipSum, force bool) {
+	base := "."
+	conf := EnsureConfig()
+	if !gpath.HasLock(base) {
+		msg.Die("Lock file (%s) does not exist. Please run `glide up` first.", gpath.LockFile)
+	}
+	lock, err := cfg.ReadLockFile(filepath.Join(base, gpath.LockFile))
+	if err != nil {
+		msg.Die("Could not load lockfile: %s", err)
+	}
+
+	modpath := filepath.Join(base, "go.mod")
+	sumpath := filepath.Join(base, "go.sum")
+	if _, err := os.Stat(modpath); err == nil && !force {
+		msg.Die("%s already exists. Use --force to overwrite it.", modpath)
+	}
+
+	f := &gomod.File{
+		Module: conf.Name,
+		Go:     goVersion,
+	}
+	sum := gomod.Sum{}
+
+	locks := append(lock.Imports.Clone(), lock.DevImports.Clone()...)
+	for _, g := range lock.Groups {
+		locks = append(locks, g.Clone()...)
+	}
+	locks = append(locks, lock.Tools.Clone()...)
+	sort.Sort(locks)
+	seen := map[string]bool{}
+	for _, l := range locks {
+		if seen[l.Name] {
+			continue
+		}
+		seen[l.Name] = true
+
+		msg.Info("--> Exporting %s", l.Name)
+		dep := cfg.DependencyFromLock(l)
+		req, rep, err := exportGomodDep(dep, sum, skipSum)
+		if err != nil {
+			msg.Die("Unable to export %s: %s", l.Name, err)
+		}
+		req.Indirect = !conf.HasDependency(l.Name)
+		f.Require = append(f.Require, req)
+		if rep != nil {
+			f.Replace = append(f.Replace, rep)
+		}
+	}
+
+	if err := f.WriteFile(modpath); err != nil {
+		msg.Die("Failed to write %s: %s", modpath, err)
+	}
+	msg.Info("Wrote %s", modpath)
+
+	if skipSum {
+		return
+	}
+	if err := sum.WriteFile(sumpath); err != nil {
+		msg.Die("Failed to write %s: %s", sumpath, err)
+	}
+	msg.Info("Wrote %s", sumpath)
+}
+
+// exportGomodDep determines the module version of a locked dependency from its
+// repository in the cache and, unless skipSum is set, adds its hashes to sum.
+// A replace directive is returned when the dependency is fetched from an
+// alternate repository or a local path.
+func exportGomodDep(dep *cfg.Dependency, sum gomod.Sum, skipSum bool) (*gomod.Require, *gomod.Replace, error) {
+	// Local path overrides become directory replacements. The go tool has no
+	// hashes for these so nothing is added to the sum.
+	if dep.Path != "" {
+		return &gomod.Require{Path: dep.Name, Version: gomod.LocalVersion},
+			&gomod.Replace{Old: dep.Name, New: gomod.LocalPath(dep.Path)}, nil
+	}
+
+	key, err := cache.Key(dep.Remote())
+	if err != nil {
+		return nil, nil, err
+	}
+	cdir := filepath.Join(cache.Location(), "src", key)
+	if _, err := os.Stat(cdir); err != nil {
+		return nil, nil, fmt.Errorf("%s is not in the cache. Please run `glide install` first", dep.Name)
+	}
+
+	cache.Lock(key)
+	defer cache.Unlock(key)
+
+	repo, err := dep.GetRepo(cdir)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// The module path is the one the code is fetched from. For forks this
+	// differs from the name and a replace directive points to it.
+	modpath := dep.Name
+	var rep *gomod.Replace
+	if dep.Repository != "" {
+		if p := gomod.PathFromRepo(dep.Repository); p != "" && p != dep.Name {
+			modpath = p
+		} else if p == "" {
+			msg.Warn("Unable to determine a module path for %s (%s). Skipping the replace directive", dep.Name, dep.Repository)
+		}
+	}
+
+	// Prefer the highest semantic version tag on the locked commit and fall
+	// back to a pseudo-version.
+	var ver string
+	var best *semver.Version
+	tags, err := repo.TagsFromCommit(dep.Reference)
+	if err != nil {
+		msg.Debug("Unable to get tags for %s: %s", dep.Name, err)
+	}
+	for _, t := range tags {
+		mv, ok := gomod.TagVersion(modpath, t)
+		if !ok {
+			continue
+		}
+		sv, err := semver.NewVersion(t)
+		if err != nil {
+			continue
+		}
+		if best == nil || sv.GreaterThan(best) {
+			best = sv
+			ver = mv
+		}
+	}
+	if ver == "" {
+		ci, err := repo.CommitInfo(dep.Reference)
+		if err != nil {
+			return nil, nil, err
+		}
+		t, err := commitTime(repo, ci)
+		if err != nil {
+			return nil, nil, err
+		}
+		ver = gomod.PseudoVersion(modpath, t, ci.Commit)
+	}
+
+	if modpath != dep.Name {
+		rep = &gomod.Replace{Old: dep.Name, New: modpath, NewVersion: ver}
+	}
+
+	if !skipSum {
+		dh, mh, err := exportGomodHashes(repo, dep.Reference, modpath, ver)
+		if err != nil {
+			return nil, nil, err
+		}
+		sum.Add(modpath, ver, dh, mh)
+	}
+
+	return &gomod.Require{Path: dep.Name, Version: ver}, rep, nil
+}
+
+// commitTime returns the time a commit was committed, in UTC, which the go
+// tool checks pseudo-versions against. The date in the commit info of a Git
+// commit is the author date. It differs from the commit date for commits that
+// were rebased or cherry-picked.
+func commitTime(repo vcs.Repo, ci *vcs.CommitInfo) (time.Time, error) {
+	if repo.Vcs() != vcs.Git {
+		return ci.Date.UTC(), nil
+	}
+	out, err := repo.RunFromDir("git", "log", "-1", "--format=%ct", ci.Commit)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("Unable to read the commit time of %s: %s", ci.Commit, err)
+	}
+	sec, err := strconv.ParseInt(strings.TrimSpace(string(out)), 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("Unable to read the commit time of %s: %s", ci.Commit, err)
+	}
+	return time.Unix(sec, 0).UTC(), nil
+}
+
+// exportGomodHashes checks out a version in the cache, exports it, and
+// generates its go.sum hashes. The version checked out before is restored
+// afterwards.
+func exportGomodHashes(repo vcs.Repo, ref, modpath, ver string) (string, string, error) {
+	cur, err := repo.Version()
+	if err != nil {
+		return "", "", err
+	}
+	if err := repo.UpdateVersion(ref); err != nil {
+		return "", "", err
+	}
+	defer func() {
+		if err := repo.UpdateVersion(cur); err != nil {
+			msg.Warn("Unable to check out %s again after generating hashes: %s", cur, err)
+		}
+	}()
+
+	tmp, err := ioutil.TempDir("", "glide-gomod")
+	if err != nil {
+		return "", "", err
+	}
+	defer os.RemoveAll(tmp)
+
+	dest := filepath.Join(tmp, "src")
+	if err := repo.ExportDir(dest); err != nil {
+		return "", "", err
+	}
+
+	dh, err := gomod.HashDir(dest, modpath, ver)
+	if err != nil {
+		return "", "", err
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join(dest, "go.mod"))
+	if err != nil {
+		data = gomod.SynthesizeGoMod(modpath)
+	}
+
+	return dh, gomod.HashGoMod(data), nil
+}
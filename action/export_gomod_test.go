@@ -0,0 +1,112 @@
+package action
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/Masterminds/vcs"
+)
+
+func TestCommitTime(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	dir, err := ioutil.TempDir("", "glide-gomod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// A rebased commit keeps its author date and gets a new commit date.
+	env := append(os.Environ(),
+		"GIT_AUTHOR_NAME=glide", "GIT_AUTHOR_EMAIL=glide@example.com",
+		"GIT_COMMITTER_NAME=glide", "GIT_COMMITTER_EMAIL=glide@example.com",
+		"GIT_AUTHOR_DATE=2018-01-02T03:04:05+02:00",
+		"GIT_COMMITTER_DATE=2019-06-07T08:09:10+02:00",
+	)
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"commit", "-q", "--allow-empty", "-m", "rebased"},
+		{"remote", "add", "origin", dir},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		cmd.Env = env
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %s\n%s", args, err, out)
+		}
+	}
+
+	repo, err := vcs.NewGitRepo(dir, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := repo.Version()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ci, err := repo.CommitInfo(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ct, err := commitTime(repo, ci)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := time.Date(2019, 6, 7, 6, 9, 10, 0, time.UTC)
+	if !ct.Equal(expected) || ct.Location() != time.UTC {
+		t.Errorf("Expected the commit time %s but got %s", expected, ct)
+	}
+}
+
+func TestExportGomodHashesRestore(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	dir, err := ioutil.TempDir("", "glide-gomod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	env := append(os.Environ(),
+		"GIT_AUTHOR_NAME=glide", "GIT_AUTHOR_EMAIL=glide@example.com",
+		"GIT_COMMITTER_NAME=glide", "GIT_COMMITTER_EMAIL=glide@example.com",
+	)
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"commit", "-q", "--allow-empty", "-m", "first"},
+		{"tag", "v1.0.0"},
+		{"commit", "-q", "--allow-empty", "-m", "second"},
+		{"remote", "add", "origin", dir},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		cmd.Env = env
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %s\n%s", args, err, out)
+		}
+	}
+
+	repo, err := vcs.NewGitRepo(dir, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	before, err := repo.Version()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := exportGomodHashes(repo, "v1.0.0", "example.com/foo", "v1.0.0"); err != nil {
+		t.Fatal(err)
+	}
+	after, err := repo.Version()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if after != before {
+		t.Errorf("Expected %s to be checked out again but got %s", before, after)
+	}
+}
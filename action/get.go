@@ -76,7 +76,7 @@ func Get(names []string, installer *repo.Installer, insecure, skipRecursive, str
 	}
 
 	// Write YAML
-	if err := conf.WriteFile(glidefile); err != nil {
+	if err := conf.UpdateFile(glidefile); err != nil {
 		msg.Die("Failed to write glide YAML file: %s", err)
 	}
 	if !skipRecursive {
@@ -107,6 +107,8 @@ func writeLock(conf, confcopy *cfg.Config, base string) {
 	if err != nil {
 		msg.Die("Failed to generate lock file: %s", err)
 	}
+	lock.Groups = cfg.LocksFromGroups(confcopy.Groups)
+	lock.Tools = cfg.LocksFromDependencies(confcopy.Tools)
 	if err := lock.WriteFile(filepath.Join(base, gpath.LockFile)); err != nil {
 		msg.Die("Failed to write glide lock file: %s", err)
 	}
@@ -1,21 +1,44 @@
 package action
 
 import (
+	"github.com/Masterminds/glide/cfg"
 	"github.com/Masterminds/glide/gom"
 	"github.com/Masterminds/glide/msg"
 )
 
 // ImportGom imports a Gomfile.
+//
+// Dependencies in Gomfile groups other than development and production are
+// added to the dependency groups of the same name.
 func ImportGom(dest string) {
 	base := "."
 	config := EnsureConfig()
 	if !gom.Has(base) {
 		msg.Die("No gom data found.")
 	}
-	deps, err := gom.Parse(base)
+	deps, groups, err := gom.ParseGroups(base)
 	if err != nil {
 		msg.Die("Failed to extract Gomfile: %s", err)
 	}
 	appendImports(deps, config)
+	appendGroups(groups, config)
 	writeConfigToFileOrStdout(config, dest)
 }
+
+// appendGroups adds dependencies to the named groups of a config.
+func appendGroups(groups map[string][]*cfg.Dependency, config *cfg.Config) {
+	for name, deps := range groups {
+		if name == cfg.ImportGroup || name == cfg.TestImportGroup {
+			msg.Warn("Skipping the %s group because its name is reserved", name)
+			continue
+		}
+		if config.Groups == nil {
+			config.Groups = map[string]cfg.Dependencies{}
+		}
+		g, err := append(config.Groups[name], deps...).DeDupe()
+		if err != nil {
+			msg.Die("Failed to add dependencies to group %s: %s", name, err)
+		}
+		config.Groups[name] = g
+	}
+}
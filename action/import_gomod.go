@@ -0,0 +1,21 @@
+package action
+
+import (
+	"github.com/Masterminds/glide/gomod"
+	"github.com/Masterminds/glide/msg"
+)
+
+// ImportGomod imports a go.mod file.
+func ImportGomod(dest string) {
+	base := "."
+	config := EnsureConfig()
+	if !gomod.Has(base) {
+		msg.Die("No go.mod data found.")
+	}
+	deps, err := gomod.Parse(base)
+	if err != nil {
+		msg.Die("Failed to extract go.mod: %s", err)
+	}
+	appendImports(deps, config)
+	writeConfigToFileOrStdout(config, dest)
+}
@@ -0,0 +1,21 @@
+package action
+
+import (
+	"github.com/Masterminds/glide/govendor"
+	"github.com/Masterminds/glide/msg"
+)
+
+// ImportGovendor imports govendor's vendor/vendor.json file.
+func ImportGovendor(dest string) {
+	base := "."
+	config := EnsureConfig()
+	if !govendor.Has(base) {
+		msg.Die("No govendor data found.")
+	}
+	deps, err := govendor.Parse(base)
+	if err != nil {
+		msg.Die("Failed to extract vendor.json: %s", err)
+	}
+	appendImports(deps, config)
+	writeConfigToFileOrStdout(config, dest)
+}
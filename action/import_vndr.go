@@ -0,0 +1,21 @@
+package action
+
+import (
+	"github.com/Masterminds/glide/msg"
+	"github.com/Masterminds/glide/vndr"
+)
+
+// ImportVndr imports a vendor.conf file used by vndr and trash.
+func ImportVndr(dest string) {
+	base := "."
+	config := EnsureConfig()
+	if !vndr.Has(base) {
+		msg.Die("No vendor.conf data found.")
+	}
+	deps, err := vndr.Parse(base)
+	if err != nil {
+		msg.Die("Failed to extract vendor.conf: %s", err)
+	}
+	appendImports(deps, config)
+	writeConfigToFileOrStdout(config, dest)
+}
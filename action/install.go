@@ -1,6 +1,7 @@
 package action
 
 import (
+	"fmt"
 	"path/filepath"
 
 	"github.com/Masterminds/glide/cache"
@@ -11,7 +12,10 @@ import (
 )
 
 // Install installs a vendor directory based on an existing Glide configuration.
-func Install(installer *repo.Installer, stripVendor bool) {
+//
+// When groups are given only the dependencies in those groups are installed.
+// The import, testImport, and tools sections can be selected by those names.
+func Install(installer *repo.Installer, stripVendor bool, groups []string) {
 	cache.SystemLock()
 
 	base := "."
@@ -23,7 +27,7 @@ func Install(installer *repo.Installer, stripVendor bool) {
 	// Lockfile exists
 	if !gpath.HasLock(base) {
 		msg.Info("Lock file (glide.lock) does not exist. Performing update.")
-		Update(installer, false, stripVendor)
+		Update(installer, false, stripVendor, false)
 		return
 	}
 	// Load lockfile
@@ -39,6 +43,13 @@ func Install(installer *repo.Installer, stripVendor bool) {
 		msg.Warn("Lock file may be out of date. Hash check of YAML failed. You may need to run 'update'")
 	}
 
+	if len(groups) > 0 {
+		lock, err = selectGroups(conf, lock, groups)
+		if err != nil {
+			msg.Die("Unable to select groups: %s", err)
+		}
+	}
+
 	// Install
 	newConf, err := installer.Install(lock, conf)
 	if err != nil {
@@ -65,3 +76,92 @@ func Install(installer *repo.Installer, stripVendor bool) {
 		}
 	}
 }
+
+// selectGroups returns a copy of a lock file holding only the named groups.
+func selectGroups(conf *cfg.Config, lock *cfg.Lockfile, groups []string) (*cfg.Lockfile, error) {
+	n := &cfg.Lockfile{
+		Hash:    lock.Hash,
+		Updated: lock.Updated,
+		Groups:  map[string]cfg.Locks{},
+	}
+	for _, g := range groups {
+		switch g {
+		case cfg.ImportGroup:
+			n.Imports = lock.Imports.Clone()
+		case cfg.TestImportGroup:
+			n.DevImports = lock.DevImports.Clone()
+		case cfg.ToolsGroup:
+			n.Tools = lock.Tools.Clone()
+			// A group may also be named tools.
+			if hasGroup(conf, lock, g) {
+				n.Groups[g] = groupLocks(conf, lock, g)
+			}
+		default:
+			if !hasGroup(conf, lock, g) {
+				return nil, fmt.Errorf("%s does not have a group named %s", gpath.LockFile, g)
+			}
+			n.Groups[g] = groupLocks(conf, lock, g)
+		}
+	}
+	return n, nil
+}
+
+// hasGroup returns true if a group is in the config or the lock file. A group
+// whose dependencies are all imported is left out of the lock file.
+func hasGroup(conf *cfg.Config, lock *cfg.Lockfile, group string) bool {
+	if _, ok := lock.Groups[group]; ok {
+		return true
+	}
+	_, ok := conf.Groups[group]
+	return ok
+}
+
+// groupLocks returns a copy of the locks of the dependencies in a group.
+//
+// The lock file records a dependency in a group that is also in the import or
+// testImport sections only in those sections. The dependencies listed in the
+// group in the config belong to it, as do the dependencies they required in
+// turn.
+func groupLocks(conf *cfg.Config, lock *cfg.Lockfile, group string) cfg.Locks {
+	locks := lock.Groups[group].Clone()
+	members := map[string]bool{}
+	for _, d := range conf.Groups[group] {
+		members[d.Name] = true
+	}
+	for _, l := range locks {
+		members[l.Name] = true
+	}
+
+	imported := append(append(cfg.Locks{}, lock.Imports...), lock.DevImports...)
+	for changed := true; changed; {
+		changed = false
+		for _, l := range imported {
+			if members[l.Name] {
+				continue
+			}
+			for _, r := range l.RequiredBy {
+				if members[r.Package] {
+					members[l.Name] = true
+					changed = true
+					break
+				}
+			}
+		}
+	}
+
+	for _, l := range imported {
+		if members[l.Name] && !hasLock(locks, l.Name) {
+			locks = append(locks, l.Clone())
+		}
+	}
+	return locks
+}
+
+func hasLock(locks cfg.Locks, name string) bool {
+	for _, l := range locks {
+		if l.Name == name {
+			return true
+		}
+	}
+	return false
+}
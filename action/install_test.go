@@ -0,0 +1,94 @@
+package action
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Masterminds/glide/cfg"
+)
+
+func TestSelectGroups(t *testing.T) {
+	lock := &cfg.Lockfile{
+		Imports:    cfg.Locks{{Name: "github.com/foo/app", Version: "abc"}},
+		DevImports: cfg.Locks{{Name: "github.com/foo/assert", Version: "abc"}},
+		Groups: map[string]cfg.Locks{
+			"tools":       {{Name: "github.com/foo/lint", Version: "abc"}},
+			"integration": {{Name: "github.com/foo/db", Version: "abc"}},
+		},
+		Tools: cfg.Locks{{Name: "github.com/foo/mock", Version: "abc"}},
+	}
+
+	n, err := selectGroups(&cfg.Config{}, lock, []string{"tools"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(n.Tools) != 1 || n.Tools[0].Name != "github.com/foo/mock" {
+		t.Errorf("Expected the tools to be selected, got %v", n.Tools)
+	}
+	if len(n.Groups) != 1 || len(n.Groups["tools"]) != 1 {
+		t.Errorf("Expected the group named tools to be selected, got %v", n.Groups)
+	}
+	if len(n.Imports) != 0 || len(n.DevImports) != 0 {
+		t.Error("Expected the imports to be left out")
+	}
+
+	if _, err := selectGroups(&cfg.Config{}, lock, []string{"docs"}); err == nil {
+		t.Error("Expected an unknown group to fail")
+	}
+}
+
+func TestSelectGroupsImported(t *testing.T) {
+	conf := &cfg.Config{
+		Groups: map[string]cfg.Dependencies{
+			"integration": {{Name: "github.com/foo/db"}, {Name: "github.com/foo/cli"}},
+			"docs":        {{Name: "github.com/foo/app"}},
+		},
+	}
+	lock := &cfg.Lockfile{
+		Imports: cfg.Locks{
+			{Name: "github.com/foo/app", Version: "abc"},
+			{Name: "github.com/foo/cli", Version: "abc", RequiredBy: cfg.Requirements{{Package: "github.com/foo/app"}}},
+			{Name: "github.com/foo/pq", Version: "abc", RequiredBy: cfg.Requirements{{Package: "github.com/foo/db"}}},
+			{Name: "github.com/foo/yaml", Version: "abc", RequiredBy: cfg.Requirements{{Package: "github.com/foo/cli"}}},
+		},
+		Groups: map[string]cfg.Locks{
+			"integration": {
+				{Name: "github.com/foo/db", Version: "abc"},
+				{Name: "github.com/foo/cli", Version: "abc"},
+			},
+		},
+	}
+	// Writing the lock file records the dependencies also imported only once.
+	yml, err := lock.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	lock, err = cfg.LockfileFromYaml(yml)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lock.Groups["integration"]) != 1 {
+		t.Fatalf("Expected only github.com/foo/db in the integration group of the lock file, got %v", lock.Groups["integration"])
+	}
+
+	n, err := selectGroups(conf, lock, []string{"integration", "docs"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The docs group is only in the config as all of it is imported.
+	for g, expected := range map[string][]string{
+		"integration": {"github.com/foo/db", "github.com/foo/cli", "github.com/foo/pq", "github.com/foo/yaml"},
+		"docs":        {"github.com/foo/app", "github.com/foo/cli", "github.com/foo/yaml"},
+	} {
+		var names []string
+		for _, l := range n.Groups[g] {
+			names = append(names, l.Name)
+		}
+		if strings.Join(names, " ") != strings.Join(expected, " ") {
+			t.Errorf("Expected the %s group to be %v, got %v", g, expected, names)
+		}
+	}
+	if len(n.Imports) != 0 {
+		t.Error("Expected the imports to be left out")
+	}
+}
@@ -0,0 +1,83 @@
+package action
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/Masterminds/glide/lint"
+	"github.com/Masterminds/glide/mirrors"
+	"github.com/Masterminds/glide/msg"
+	gpath "github.com/Masterminds/glide/path"
+)
+
+// Lint validates the glide.yaml file and reports each problem with its line
+// and column.
+//
+// Params:
+//  - format (string): The format to output (text, json, json-pretty)
+func Lint(format string) {
+	yamlpath, err := gpath.Glide()
+	if err != nil {
+		msg.ExitCode(2)
+		msg.Die("Failed to find %s file in directory tree: %s", gpath.GlideFile, err)
+	}
+	yml, err := ioutil.ReadFile(yamlpath)
+	if err != nil {
+		msg.ExitCode(2)
+		msg.Die("Failed to load %s: %s", yamlpath, err)
+	}
+	if err := mirrors.Load(); err != nil {
+		msg.Err("Unable to load mirrors: %s", err)
+	}
+
+	diags := lint.Lint(yml, filepath.Dir(yamlpath))
+	outputLint(gpath.StripBasepath(yamlpath), diags, format)
+
+	errs := 0
+	for _, d := range diags {
+		if d.Severity == lint.Error {
+			errs++
+		}
+	}
+	if errs > 0 {
+		msg.Die("Found %d error(s) in %s", errs, gpath.GlideFile)
+	}
+}
+
+type lintResult struct {
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+	Column   int    `json:"column"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+}
+
+func outputLint(file string, diags []lint.Diagnostic, format string) {
+	switch format {
+	case textFormat:
+		if len(diags) == 0 {
+			msg.Puts("No problems found in %s", file)
+			return
+		}
+		for _, d := range diags {
+			msg.Puts("%s:%s", file, d)
+		}
+	case jsonFormat, jsonPrettyFormat:
+		r := make([]lintResult, 0, len(diags))
+		for _, d := range diags {
+			r = append(r, lintResult{File: file, Line: d.Line, Column: d.Column, Severity: string(d.Severity), Message: d.Message})
+		}
+		if format == jsonFormat {
+			json.NewEncoder(msg.Default.Stdout).Encode(r)
+			return
+		}
+		b, err := json.MarshalIndent(r, "", "  ")
+		if err != nil {
+			msg.Die("could not marshal lint results: %s", err)
+		}
+		msg.Puts("%s", b)
+	default:
+		msg.Die("invalid output format: must be one of: json|json-pretty|text")
+	}
+}
@@ -0,0 +1,271 @@
+package action
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/Masterminds/glide/cfg"
+	"github.com/Masterminds/glide/msg"
+	gpath "github.com/Masterminds/glide/path"
+)
+
+// LockMerge merges two versions of a glide.lock file that share a common base.
+// It is meant to be used as a git merge driver.
+//
+// The result is written to the ours file. Dependencies are merged one by one
+// and only those both sides locked to different versions conflict. The hash is
+// that of the merged glide.yaml file, which is merged from the versions git is
+// merging. The hash conflicts when the glide.yaml file does not merge cleanly.
+// Conflicts are marked in the written file, in the same way git marks them,
+// and Glide exits with a non-zero exit code.
+//
+// Params:
+//  - base (string): The path to the common base, %O to git
+//  - ours (string): The path to the current version, %A to git
+//  - theirs (string): The path to the other version, %B to git
+//  - name (string): The path of the lock file in the repository, %P to git. Optional
+func LockMerge(base, ours, theirs, name string) {
+	if name == "" {
+		name = gpath.LockFile
+	}
+	o := readMergeLock(base)
+	a := readMergeLock(ours)
+	b := readMergeLock(theirs)
+
+	merged, conflicts := cfg.MergeLockfiles(o, a, b)
+	hash, hashConflict := mergeHash(o, a, b, name)
+	merged.Hash = hash
+
+	yml, err := merged.Marshal()
+	if err != nil {
+		msg.Die("Unable to marshal the merged lock file: %s", err)
+	}
+	if len(conflicts) > 0 {
+		yml = markConflicts(yml, conflicts)
+	}
+	if hashConflict {
+		yml = markHashConflict(yml, a.Hash, b.Hash)
+	}
+	if err := ioutil.WriteFile(ours, yml, 0666); err != nil {
+		msg.Die("Unable to write the merged lock file: %s", err)
+	}
+
+	if hashConflict {
+		msg.Warn("Merge the %s file and then run `glide up` to update %s.", gpath.GlideFile, name)
+	}
+	if len(conflicts) > 0 {
+		msg.Err("Dependencies locked to different versions:")
+		for _, c := range conflicts {
+			section := c.Section
+			if c.Group {
+				section = "group " + c.Section
+			}
+			msg.Err("\t%s (%s): base %s, ours %s, theirs %s", c.Name, section, conflictVersion(c.Base), conflictVersion(c.Ours), conflictVersion(c.Theirs))
+		}
+	}
+	if hashConflict || len(conflicts) > 0 {
+		msg.Die("Unable to merge %s", name)
+	}
+}
+
+func readMergeLock(p string) *cfg.Lockfile {
+	yml, err := ioutil.ReadFile(p)
+	if os.IsNotExist(err) {
+		return &cfg.Lockfile{}
+	} else if err != nil {
+		msg.Die("Unable to read %s: %s", p, err)
+	}
+	lock, err := cfg.LockfileFromYaml(yml)
+	if err != nil {
+		msg.Die("Unable to parse %s: %s", p, err)
+	}
+	return lock
+}
+
+// mergeHash returns the hash for the merged lock file.
+//
+// When only one side changed the glide.yaml file the merged file is that
+// side's and its hash is used. When both sides changed it the hash is that of
+// the glide.yaml file next to the lock file named once merged. If it does not
+// merge cleanly the hash conflicts and ours is returned along with true.
+func mergeHash(base, ours, theirs *cfg.Lockfile, name string) (string, bool) {
+	switch {
+	case ours.Hash == theirs.Hash, theirs.Hash == base.Hash:
+		return ours.Hash, false
+	case ours.Hash == base.Hash:
+		return theirs.Hash, false
+	}
+
+	h, err := mergedConfigHash(name)
+	if err != nil {
+		msg.Warn("Unable to merge the %s file: %s", gpath.GlideFile, err)
+		return ours.Hash, true
+	}
+	return h, false
+}
+
+// mergedConfigHash merges the versions of the glide.yaml file next to a lock
+// file that git is merging and returns the hash of the result.
+//
+// Git runs merge drivers before it writes the merged files, and before it
+// writes the index when merging with the ort strategy, so the glide.yaml file
+// is merged here with git merge-file.
+func mergedConfigHash(name string) (string, error) {
+	p := path.Join(path.Dir(filepath.ToSlash(name)), gpath.GlideFile)
+	revs, err := mergeRevisions(p)
+	if err != nil {
+		return "", err
+	}
+
+	dir, err := ioutil.TempDir("", "glide-merge")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(dir)
+
+	// git merge-file takes the files in the order ours, base, and theirs.
+	var files []string
+	for i, rev := range []string{revs[1], revs[0], revs[2]} {
+		out, err := exec.Command("git", "show", rev+":"+p).Output()
+		if err != nil {
+			return "", fmt.Errorf("Unable to read %s at %s: %s", p, rev, err)
+		}
+		f := filepath.Join(dir, fmt.Sprintf("%d.yaml", i))
+		if err := ioutil.WriteFile(f, out, 0666); err != nil {
+			return "", err
+		}
+		files = append(files, f)
+	}
+
+	// git merge-file exits with the number of conflicts.
+	out, err := exec.Command("git", append([]string{"merge-file", "-p"}, files...)...).Output()
+	if _, ok := err.(*exec.ExitError); ok {
+		return "", fmt.Errorf("%s does not merge cleanly", p)
+	} else if err != nil {
+		return "", err
+	}
+
+	conf, err := cfg.ConfigFromYaml(out)
+	if err != nil {
+		return "", fmt.Errorf("Unable to parse the merged %s: %s", p, err)
+	}
+	if err := conf.Extend(filepath.Dir(filepath.FromSlash(p)), gpath.Home()); err != nil {
+		return "", err
+	}
+	return conf.Hash()
+}
+
+// mergeRevisions returns the base, ours, and theirs revisions git is merging
+// a file from.
+//
+// Older merge strategies leave the versions in the index. git merge names the
+// commit being merged in a GITHEAD_<commit> environment variable, and git
+// rebase lists the commit being picked last in its todo list of those done.
+func mergeRevisions(p string) ([3]string, error) {
+	if exec.Command("git", "rev-parse", "-q", "--verify", ":1:"+p).Run() == nil {
+		return [3]string{":1", ":2", ":3"}, nil
+	}
+
+	var theirs string
+	for _, e := range os.Environ() {
+		if strings.HasPrefix(e, "GITHEAD_") {
+			theirs = strings.SplitN(strings.TrimPrefix(e, "GITHEAD_"), "=", 2)[0]
+			break
+		}
+	}
+	if theirs != "" {
+		out, err := exec.Command("git", "merge-base", "HEAD", theirs).Output()
+		if err != nil {
+			return [3]string{}, fmt.Errorf("Unable to find the merge base of %s: %s", theirs, err)
+		}
+		return [3]string{strings.TrimSpace(string(out)), "HEAD", theirs}, nil
+	}
+
+	out, err := exec.Command("git", "rev-parse", "--git-dir").Output()
+	if err != nil {
+		return [3]string{}, errors.New("Not in a git repository")
+	}
+	done, err := ioutil.ReadFile(filepath.Join(strings.TrimSpace(string(out)), "rebase-merge", "done"))
+	if err == nil {
+		lines := strings.Split(strings.TrimSpace(string(done)), "\n")
+		f := strings.Fields(lines[len(lines)-1])
+		if len(f) > 1 {
+			return [3]string{f[1] + "^", "HEAD", f[1]}, nil
+		}
+	}
+	return [3]string{}, errors.New("Unable to find the commits being merged")
+}
+
+// markHashConflict marks the hash in a marshaled lock file as conflicting.
+func markHashConflict(yml []byte, ours, theirs string) []byte {
+	lines := strings.Split(string(yml), "\n")
+	for i, line := range lines {
+		if strings.HasPrefix(line, "hash: ") {
+			marked := []string{"<<<<<<< ours", "hash: " + ours, "=======", "hash: " + theirs, ">>>>>>> theirs"}
+			lines = append(lines[:i], append(marked, lines[i+1:]...)...)
+			break
+		}
+	}
+	return []byte(strings.Join(lines, "\n"))
+}
+
+// markConflicts marks the versions of conflicting dependencies in a marshaled
+// lock file with the conflict markers git uses.
+func markConflicts(yml []byte, conflicts []cfg.LockConflict) []byte {
+	find := func(section string, group bool, name string) *cfg.LockConflict {
+		for i, c := range conflicts {
+			if c.Section == section && c.Group == group && c.Name == name {
+				return &conflicts[i]
+			}
+		}
+		return nil
+	}
+
+	var out []string
+	var c *cfg.LockConflict
+	section, group := "", ""
+	for _, line := range strings.Split(string(yml), "\n") {
+		t := strings.TrimLeft(line, " ")
+		indent := line[:len(line)-len(t)]
+		switch {
+		case indent == "" && strings.HasSuffix(t, ":") && !strings.HasPrefix(t, "-"):
+			section, group = strings.TrimSuffix(t, ":"), ""
+		case section == "groups" && indent == "  " && strings.HasSuffix(t, ":") && !strings.HasPrefix(t, "-"):
+			group = strings.TrimSuffix(t, ":")
+		case strings.HasPrefix(t, "- name: "):
+			name := strings.TrimPrefix(t, "- name: ")
+			if group != "" {
+				c = find(group, true, name)
+			} else {
+				c = find(section, false, name)
+			}
+		case c != nil && strings.HasPrefix(t, "version: "):
+			pad := indent
+			out = append(out, "<<<<<<< ours", conflictLine(pad, c.Ours), "=======", conflictLine(pad, c.Theirs), ">>>>>>> theirs")
+			c = nil
+			continue
+		}
+		out = append(out, line)
+	}
+	return []byte(strings.Join(out, "\n"))
+}
+
+func conflictLine(pad, version string) string {
+	if version == "" {
+		return pad + "# removed"
+	}
+	return fmt.Sprintf("%sversion: %s", pad, version)
+}
+
+func conflictVersion(v string) string {
+	if v == "" {
+		return "(none)"
+	}
+	return v
+}
@@ -0,0 +1,202 @@
+package action
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Masterminds/glide/cfg"
+)
+
+func TestMarkConflicts(t *testing.T) {
+	yml := `hash: abc
+imports:
+- name: github.com/foo/a
+  version: a2
+- name: github.com/foo/b
+  version: b2
+groups:
+  linters:
+  - name: github.com/foo/b
+    version: b4
+`
+	conflicts := []cfg.LockConflict{
+		{Section: "imports", Name: "github.com/foo/b", Ours: "b2", Theirs: "b3"},
+		{Section: "linters", Group: true, Name: "github.com/foo/b", Ours: "b4"},
+	}
+	expected := `hash: abc
+imports:
+- name: github.com/foo/a
+  version: a2
+- name: github.com/foo/b
+<<<<<<< ours
+  version: b2
+=======
+  version: b3
+>>>>>>> theirs
+groups:
+  linters:
+  - name: github.com/foo/b
+<<<<<<< ours
+    version: b4
+=======
+    # removed
+>>>>>>> theirs
+`
+	if out := string(markConflicts([]byte(yml), conflicts)); out != expected {
+		t.Errorf("Unexpected conflict markers. Expected:\n%s\nGot:\n%s", expected, out)
+	}
+}
+
+func TestMergeHash(t *testing.T) {
+	lock := func(h string) *cfg.Lockfile {
+		return &cfg.Lockfile{Hash: h}
+	}
+	tests := []struct {
+		base, ours, theirs string
+		hash               string
+		conflict           bool
+	}{
+		{"a", "a", "a", "a", false},
+		{"a", "b", "a", "b", false},
+		{"a", "a", "c", "c", false},
+		{"a", "b", "b", "b", false},
+		{"a", "b", "c", "b", true},
+	}
+	// Outside of a git merge the merged glide.yaml file cannot be found.
+	dir, err := ioutil.TempDir("", "glide-merge")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	for _, tt := range tests {
+		h, c := mergeHash(lock(tt.base), lock(tt.ours), lock(tt.theirs), "glide.lock")
+		if h != tt.hash || c != tt.conflict {
+			t.Errorf("Expected %s and %t for %s, %s, %s, got %s and %t", tt.hash, tt.conflict, tt.base, tt.ours, tt.theirs, h, c)
+		}
+	}
+}
+
+func TestMarkHashConflict(t *testing.T) {
+	yml := `hash: b
+updated: 2016-01-01T00:00:00Z
+imports: []
+`
+	expected := `<<<<<<< ours
+hash: b
+=======
+hash: c
+>>>>>>> theirs
+updated: 2016-01-01T00:00:00Z
+imports: []
+`
+	if out := string(markHashConflict([]byte(yml), "b", "c")); out != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, out)
+	}
+}
+
+func TestMergedConfigHash(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	dir, err := ioutil.TempDir("", "glide-merge")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	env := append(os.Environ(),
+		"GIT_AUTHOR_NAME=glide", "GIT_AUTHOR_EMAIL=glide@example.com",
+		"GIT_COMMITTER_NAME=glide", "GIT_COMMITTER_EMAIL=glide@example.com",
+	)
+	git := func(args ...string) string {
+		cmd := exec.Command("git", args...)
+		cmd.Env = env
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v failed: %s\n%s", args, err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	commit := func(yml string) string {
+		if err := ioutil.WriteFile(filepath.Join(dir, "app", "glide.yaml"), []byte(yml), 0644); err != nil {
+			t.Fatal(err)
+		}
+		git("add", "-A")
+		git("commit", "-q", "-m", "change")
+		return git("rev-parse", "HEAD")
+	}
+
+	base := "package: example.com/app\nimport:\n- package: example.com/a\n  version: ^1.0.0\n- package: example.com/b\n- package: example.com/c\n"
+	ours := strings.Replace(base, "^1.0.0", "^1.1.0", 1)
+	theirs := base + "- package: example.com/d\n"
+	merged := strings.Replace(theirs, "^1.0.0", "^1.1.0", 1)
+
+	git("init", "-q")
+	if err := os.Mkdir(filepath.Join(dir, "app"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	commit(base)
+	git("checkout", "-q", "-b", "other")
+	other := commit(theirs)
+	git("checkout", "-q", "-")
+	commit(ours)
+
+	conf, err := cfg.ConfigFromYaml([]byte(merged))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected, err := conf.Hash()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// git merge names the commit being merged in the environment.
+	os.Setenv("GITHEAD_"+other, "other")
+	h, err := mergedConfigHash("app/glide.lock")
+	os.Unsetenv("GITHEAD_" + other)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if h != expected {
+		t.Errorf("Expected the hash of the merged glide.yaml %s, got %s", expected, h)
+	}
+
+	// git rebase lists the commit being picked in its todo list.
+	rebase := filepath.Join(dir, ".git", "rebase-merge")
+	if err := os.Mkdir(rebase, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(rebase, "done"), []byte("pick "+other+" change\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if h, err := mergedConfigHash("app/glide.lock"); err != nil || h != expected {
+		t.Errorf("Expected the hash of the merged glide.yaml %s while rebasing, got %s and %v", expected, h, err)
+	}
+
+	// Dependencies added in the same place do not merge.
+	commit(ours + "- package: example.com/e\n")
+	if _, err := mergedConfigHash("app/glide.lock"); err == nil || !strings.Contains(err.Error(), "does not merge cleanly") {
+		t.Errorf("Expected glide.yaml to not merge cleanly, got %v", err)
+	}
+}
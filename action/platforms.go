@@ -0,0 +1,242 @@
+package action
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Masterminds/glide/cfg"
+	"github.com/Masterminds/glide/dependency"
+	"github.com/Masterminds/glide/msg"
+	"github.com/Masterminds/glide/repo"
+	"github.com/Masterminds/glide/util"
+)
+
+// platform is an os and arch pair dependencies are resolved for.
+type platform struct {
+	os, arch string
+}
+
+func (p platform) String() string {
+	return p.os + "/" + p.arch
+}
+
+// parsePlatforms parses a list of os/arch pairs, such as linux/amd64.
+func parsePlatforms(list []string) ([]platform, error) {
+	var ps []platform
+	for _, s := range list {
+		s = strings.TrimSpace(s)
+		if s == "" {
+			continue
+		}
+		parts := strings.Split(s, "/")
+		if len(parts) != 2 || !dependency.IsSupportedOs(parts[0]) || !dependency.IsSupportedArch(parts[1]) {
+			return nil, fmt.Errorf("%s is not a supported os/arch pair", s)
+		}
+		p := platform{os: parts[0], arch: parts[1]}
+		dup := false
+		for _, pp := range ps {
+			dup = dup || pp == p
+		}
+		if !dup {
+			ps = append(ps, p)
+		}
+	}
+	if len(ps) == 0 {
+		return nil, fmt.Errorf("no platforms given")
+	}
+	return ps, nil
+}
+
+// updatePlatforms resolves the dependencies of the project once for each of
+// the platforms the installer lists and merges the results. Only the files
+// built on a platform are scanned while resolving for it.
+func updatePlatforms(installer *repo.Installer, conf *cfg.Config) *cfg.Config {
+	ps, err := parsePlatforms(installer.Platforms)
+	if err != nil {
+		msg.Die("Invalid platforms: %s", err)
+	}
+
+	rc, goos, goarch := util.ResolveCurrent, util.GOOS, util.GOARCH
+	defer func() {
+		util.ResolveCurrent, util.GOOS, util.GOARCH = rc, goos, goarch
+	}()
+	util.ResolveCurrent = true
+
+	// The imports on every platform are recorded in the graph of the
+	// installer, when it has one.
+	graph := installer.Graph
+	defer func() { installer.Graph = graph }()
+
+	confs := make([]*cfg.Config, len(ps))
+	imported := make([]map[string]bool, len(ps))
+	for i, p := range ps {
+		msg.Info("Resolving dependencies for %s", p)
+		util.GOOS, util.GOARCH = p.os, p.arch
+
+		// Dependencies limited to an os or arch by hand may only have been
+		// checked out now.
+		confs[i] = conf.Clone()
+		if err := installer.Checkout(confs[i]); err != nil {
+			msg.Die("Failed to check out the dependencies for %s: %s", p, err)
+		}
+		installer.Graph = dependency.NewImportGraph()
+		if err := installer.Update(confs[i]); err != nil {
+			msg.Die("Could not update packages for %s: %s", p, err)
+		}
+		imported[i] = graphPackages(installer.Graph)
+		if graph != nil {
+			mergeGraph(graph, installer.Graph)
+		}
+	}
+
+	return mergePlatforms(conf, confs, imported, ps)
+}
+
+// graphPackages returns every package scanned or imported in a graph.
+func graphPackages(g *dependency.ImportGraph) map[string]bool {
+	pkgs := map[string]bool{}
+	for _, m := range []map[string][]string{g.Imports, g.TestImports} {
+		for p, imps := range m {
+			pkgs[p] = true
+			for _, imp := range imps {
+				pkgs[imp] = true
+			}
+		}
+	}
+	return pkgs
+}
+
+// mergePlatforms merges the configs resolved for each of the platforms into
+// a clone of the config of the project. The packages imported on each are used
+// to tell which platforms a dependency is needed on.
+func mergePlatforms(conf *cfg.Config, confs []*cfg.Config, imported []map[string]bool, ps []platform) *cfg.Config {
+	merged := conf.Clone()
+	merge := func(get func(c *cfg.Config) cfg.Dependencies) cfg.Dependencies {
+		per := make([]cfg.Dependencies, len(confs))
+		for i, c := range confs {
+			per[i] = get(c)
+		}
+		return mergeDependencies(per, imported, ps)
+	}
+
+	merged.Imports = merge(func(c *cfg.Config) cfg.Dependencies { return c.Imports })
+	merged.DevImports = merge(func(c *cfg.Config) cfg.Dependencies { return c.DevImports })
+	merged.Tools = merge(func(c *cfg.Config) cfg.Dependencies { return c.Tools })
+	for _, c := range confs {
+		for name := range c.Groups {
+			if merged.Groups == nil {
+				merged.Groups = map[string]cfg.Dependencies{}
+			}
+			merged.Groups[name] = nil
+		}
+	}
+	for name := range merged.Groups {
+		merged.Groups[name] = merge(func(c *cfg.Config) cfg.Dependencies { return c.Groups[name] })
+	}
+	return merged
+}
+
+// mergeDependencies merges the dependencies resolved for each of the
+// platforms. A dependency is needed on a platform when one of its packages is
+// imported there. Those needed on only some of the platforms are limited to
+// them with their os and arch, unless those were set by hand. When the
+// platforms resolved a dependency to different versions the first is kept.
+func mergeDependencies(per []cfg.Dependencies, imported []map[string]bool, ps []platform) cfg.Dependencies {
+	var merged cfg.Dependencies
+	used := map[string][]bool{}
+	first := map[string]int{}
+	for i, deps := range per {
+		for _, d := range deps {
+			m := merged.Get(d.Name)
+			if m == nil {
+				m = d.Clone()
+				m.Subpackages = append([]string{}, d.Subpackages...)
+				merged = append(merged, m)
+				used[d.Name] = make([]bool, len(ps))
+				first[d.Name] = i
+			} else {
+				if m.Reference != d.Reference {
+					msg.Warn("%s was resolved to %s for %s and to %s for %s. Using %s.", d.Name, m.Reference, ps[first[d.Name]], d.Reference, ps[i], m.Reference)
+				}
+				for _, sp := range d.Subpackages {
+					if !m.HasSubpackage(sp) {
+						m.Subpackages = append(m.Subpackages, sp)
+					}
+				}
+				for _, r := range d.RequiredBy {
+					m.RequiredBy = m.RequiredBy.Add(r.Package, r.Version)
+				}
+			}
+			used[d.Name][i] = used[d.Name][i] || importsFrom(imported[i], d.Name)
+		}
+	}
+
+	for _, d := range merged {
+		sort.Strings(d.Subpackages)
+		if len(d.Os) == 0 && len(d.Arch) == 0 {
+			d.Os, d.Arch = platformLimits(used[d.Name], ps)
+		}
+	}
+	return merged
+}
+
+// platformLimits returns the os and arch lists that limit a dependency to the
+// platforms it is used on. Nothing is returned when it is used on all of them.
+// An os or arch alone is used when it is enough. Otherwise both are returned,
+// which also allow the pairs of them not in the platforms used.
+func platformLimits(used []bool, ps []platform) ([]string, []string) {
+	var oses, arches []string
+	for i, p := range ps {
+		if !used[i] {
+			continue
+		}
+		if !containsString(oses, p.os) {
+			oses = append(oses, p.os)
+		}
+		if !containsString(arches, p.arch) {
+			arches = append(arches, p.arch)
+		}
+	}
+	sort.Strings(oses)
+	sort.Strings(arches)
+
+	// covers returns true if every platform matched is used.
+	covers := func(match func(p platform) bool) bool {
+		for i, p := range ps {
+			if match(p) && !used[i] {
+				return false
+			}
+		}
+		return true
+	}
+	switch {
+	case covers(func(platform) bool { return true }):
+		return nil, nil
+	case covers(func(p platform) bool { return containsString(oses, p.os) }):
+		return oses, nil
+	case covers(func(p platform) bool { return containsString(arches, p.arch) }):
+		return nil, arches
+	}
+	return oses, arches
+}
+
+// importsFrom returns true if a package in a repository is in a set of
+// packages.
+func importsFrom(pkgs map[string]bool, root string) bool {
+	for p := range pkgs {
+		if p == root || strings.HasPrefix(p, root+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+func containsString(l []string, s string) bool {
+	for _, v := range l {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,116 @@
+package action
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Masterminds/glide/cfg"
+)
+
+func TestParsePlatforms(t *testing.T) {
+	ps, err := parsePlatforms([]string{"linux/amd64", " darwin/arm64", "linux/amd64"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	expected := []platform{{"linux", "amd64"}, {"darwin", "arm64"}}
+	if !reflect.DeepEqual(ps, expected) {
+		t.Errorf("Expected %v but got %v", expected, ps)
+	}
+
+	for _, bad := range [][]string{{"linux"}, {"linux/amd64/v2"}, {"nope/amd64"}, {"linux/nope"}, {""}} {
+		if _, err := parsePlatforms(bad); err == nil {
+			t.Errorf("Expected an error parsing %v", bad)
+		}
+	}
+}
+
+func TestMergeDependencies(t *testing.T) {
+	ps := []platform{{"linux", "amd64"}, {"darwin", "arm64"}, {"windows", "amd64"}}
+	per := []cfg.Dependencies{
+		{
+			{Name: "github.com/a/all", Reference: "^1.0.0", Subpackages: []string{"unix"}},
+			{Name: "github.com/a/unix"},
+		},
+		{
+			{Name: "github.com/a/all", Reference: "^1.0.0", Subpackages: []string{"unix", "darwin"}},
+			{Name: "github.com/a/unix"},
+			{Name: "github.com/a/arm", Arch: []string{"arm64"}},
+		},
+		{
+			{Name: "github.com/a/all", Reference: "^1.0.0", Subpackages: []string{"windows"}},
+			{Name: "github.com/a/amd64"},
+		},
+	}
+
+	// The dependencies listed in the glide.yaml file are resolved for every
+	// platform but a.unix is only imported on two.
+	per[2] = append(per[2], &cfg.Dependency{Name: "github.com/a/unix"})
+	imported := []map[string]bool{
+		{"github.com/a/all/unix": true, "github.com/a/unix": true},
+		{"github.com/a/all/unix": true, "github.com/a/all/darwin": true, "github.com/a/unix/sub": true, "github.com/a/arm": true},
+		{"github.com/a/all/windows": true, "github.com/a/amd64": true},
+	}
+
+	merged := mergeDependencies(per, imported, ps)
+	expected := map[string]struct {
+		subs, os, arch []string
+	}{
+		"github.com/a/all":   {subs: []string{"darwin", "unix", "windows"}},
+		"github.com/a/unix":  {subs: []string{}, os: []string{"darwin", "linux"}},
+		"github.com/a/arm":   {subs: []string{}, arch: []string{"arm64"}},
+		"github.com/a/amd64": {subs: []string{}, os: []string{"windows"}},
+	}
+	if len(merged) != len(expected) {
+		t.Fatalf("Expected %d dependencies but got %d", len(expected), len(merged))
+	}
+	for _, d := range merged {
+		e, ok := expected[d.Name]
+		if !ok {
+			t.Errorf("Unexpected dependency %s", d.Name)
+			continue
+		}
+		if !reflect.DeepEqual(d.Subpackages, e.subs) {
+			t.Errorf("Expected the subpackages of %s to be %v but got %v", d.Name, e.subs, d.Subpackages)
+		}
+		if !reflect.DeepEqual(d.Os, e.os) || !reflect.DeepEqual(d.Arch, e.arch) {
+			t.Errorf("Expected %s to be limited to os %v and arch %v but got %v and %v", d.Name, e.os, e.arch, d.Os, d.Arch)
+		}
+	}
+
+	// The dependencies resolved for a platform are not changed.
+	if len(per[0][0].Subpackages) != 1 {
+		t.Errorf("Expected the resolved subpackages to be left as they were but got %v", per[0][0].Subpackages)
+	}
+
+	// The packages requiring a dependency are listed once each, in order,
+	// keeping the version asked for first.
+	per = []cfg.Dependencies{
+		{{Name: "github.com/a/all", RequiredBy: cfg.Requirements{{Package: "github.com/b/x", Version: "^1.0.0"}}}},
+		{{Name: "github.com/a/all", RequiredBy: cfg.Requirements{{Package: "github.com/b/app"}, {Package: "github.com/b/x", Version: "^1.2.0"}}}},
+	}
+	merged = mergeDependencies(per, []map[string]bool{{}, {}}, ps[:2])
+	expectedReqs := cfg.Requirements{{Package: "github.com/b/app"}, {Package: "github.com/b/x", Version: "^1.0.0"}}
+	if !reflect.DeepEqual(merged[0].RequiredBy, expectedReqs) {
+		t.Errorf("Expected the requirements to be merged into %v, got %v", expectedReqs, merged[0].RequiredBy)
+	}
+}
+
+func TestPlatformLimits(t *testing.T) {
+	ps := []platform{{"linux", "amd64"}, {"linux", "arm64"}, {"darwin", "arm64"}, {"windows", "amd64"}}
+	tests := []struct {
+		used     []bool
+		os, arch []string
+	}{
+		{[]bool{true, true, true, true}, nil, nil},
+		{[]bool{true, true, false, false}, []string{"linux"}, nil},
+		{[]bool{false, true, true, false}, nil, []string{"arm64"}},
+		// No os or arch alone tells these apart so linux/arm64 is allowed too.
+		{[]bool{true, false, true, false}, []string{"darwin", "linux"}, []string{"amd64", "arm64"}},
+	}
+	for _, tt := range tests {
+		os, arch := platformLimits(tt.used, ps)
+		if !reflect.DeepEqual(os, tt.os) || !reflect.DeepEqual(arch, tt.arch) {
+			t.Errorf("Expected %v to be limited to os %v and arch %v but got %v and %v", tt.used, tt.os, tt.arch, os, arch)
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package action
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/Masterminds/glide/cfg"
+	"github.com/Masterminds/glide/dependency"
+	"github.com/Masterminds/glide/msg"
+	gpath "github.com/Masterminds/glide/path"
+	"github.com/Masterminds/glide/util"
+)
+
+// PruneConfig lists the dependencies in the glide.yaml file that no package of
+// the project imports, directly or through other dependencies, and optionally
+// removes them from the file.
+//
+// The imports are followed through the packages in the vendor directory so it
+// should be installed first. Tools and groups are not checked.
+//
+// Params:
+//  - remove (bool): Remove the unused dependencies from the glide.yaml file
+func PruneConfig(remove bool) {
+	conf := EnsureConfig()
+	base := "."
+	if yamlpath, err := gpath.Glide(); err == nil {
+		base = filepath.Dir(yamlpath)
+	}
+	base, err := filepath.Abs(base)
+	if err != nil {
+		msg.Die("Could not read directory: %s", err)
+	}
+
+	r, err := dependency.NewResolver(base)
+	if err != nil {
+		msg.Die("Could not create a resolver: %s", err)
+	}
+	h := &dependency.DefaultMissingPackageHandler{Missing: []string{}, Gopath: []string{}, Prefix: r.VendorDir}
+	// The resolver adds the packages it finds to its config so it gets a copy
+	// to keep them out of the glide.yaml file.
+	r.Config = conf.Clone()
+	r.ResolveTest = true
+	r.Handler = whyPackageHandler{h}
+	r.VersionHandler = whyVersionHandler{}
+	r.Graph = dependency.NewImportGraph()
+
+	if _, _, err := r.ResolveLocal(true); err != nil {
+		msg.Die("Error resolving the imports of the project: %s", err)
+	}
+	if len(h.Missing) > 0 {
+		msg.Warn("Not every package could be scanned so dependencies only imported by them are listed as unused. Run `glide install` to install missing packages.")
+	}
+
+	imps, devImps := unusedDependencies(conf, r.Graph, true)
+	if len(imps) == 0 && len(devImps) == 0 {
+		msg.Info("Every dependency in the glide.yaml file is imported.")
+		return
+	}
+	for _, d := range imps {
+		msg.Puts("%s", d.Name)
+	}
+	for _, d := range devImps {
+		msg.Puts("%s (testImport)", d.Name)
+	}
+
+	if !remove {
+		return
+	}
+	if len(h.Missing) > 0 {
+		msg.Die("Not removing dependencies while packages are missing from the vendor directory.")
+	}
+	glidefile, err := gpath.Glide()
+	if err != nil {
+		msg.Die("Could not find Glide file: %s", err)
+	}
+	conf.Imports = rmDeps(dependencyNames(imps), conf.Imports)
+	conf.DevImports = rmDeps(dependencyNames(devImps), conf.DevImports)
+	if err := conf.UpdateFile(glidefile); err != nil {
+		msg.Die("Failed to write glide YAML file: %s", err)
+	}
+	msg.Info("Removed %d dependencies from the glide.yaml file. Run `glide up` to update the lock file and vendor directory.", len(imps)+len(devImps))
+}
+
+// warnUnused warns about the dependencies in the config that no package of the
+// project reaches in the import graph of an update.
+func warnUnused(conf *cfg.Config, g *dependency.ImportGraph, tests bool) {
+	imps, devImps := unusedDependencies(conf, g, tests)
+	for _, d := range append(imps, devImps...) {
+		msg.Warn("%s is in the glide.yaml file but nothing imports it. Run `glide prune-config --remove` to remove unused dependencies.", d.Name)
+	}
+}
+
+// unusedDependencies returns the imports and test imports in the config no
+// package of the project reaches through the imports in a graph. The test
+// imports are only checked when tests were resolved. Dependencies limited to
+// other platforms are skipped when only the current one was resolved.
+func unusedDependencies(conf *cfg.Config, g *dependency.ImportGraph, tests bool) (cfg.Dependencies, cfg.Dependencies) {
+	reached := reachablePackages(g, conf.Name)
+	unused := func(deps cfg.Dependencies) cfg.Dependencies {
+		var res cfg.Dependencies
+		for _, d := range deps {
+			if util.ResolveCurrent && !d.ForPlatform(util.GOOS, util.GOARCH) {
+				continue
+			}
+			if conf.HasIgnore(d.Name) || importsFrom(reached, d.Name) {
+				continue
+			}
+			res = append(res, d)
+		}
+		return res
+	}
+
+	imps := unused(conf.Imports)
+	var devImps cfg.Dependencies
+	if tests {
+		devImps = unused(conf.DevImports)
+	}
+	return imps, devImps
+}
+
+// reachablePackages returns the packages reached from the packages of the
+// project in a graph. The test imports are only followed for the packages of
+// the project, as the go tool does.
+func reachablePackages(g *dependency.ImportGraph, name string) map[string]bool {
+	local := func(p string) bool {
+		return p == name || strings.HasPrefix(p, name+"/")
+	}
+
+	reached := map[string]bool{}
+	var queue []string
+	visit := func(imps []string) {
+		for _, imp := range imps {
+			if !reached[imp] {
+				reached[imp] = true
+				queue = append(queue, imp)
+			}
+		}
+	}
+	for p := range g.Imports {
+		if local(p) {
+			visit([]string{p})
+			visit(g.TestImports[p])
+		}
+	}
+	for len(queue) > 0 {
+		p := queue[0]
+		queue = queue[1:]
+		visit(g.Imports[p])
+	}
+	return reached
+}
+
+// mergeGraph adds the imports recorded in one graph to another.
+func mergeGraph(dst, src *dependency.ImportGraph) {
+	for _, m := range []struct{ dst, src map[string][]string }{
+		{dst.Imports, src.Imports},
+		{dst.TestImports, src.TestImports},
+	} {
+		for p, imps := range m.src {
+			for _, imp := range imps {
+				if !containsString(m.dst[p], imp) {
+					m.dst[p] = append(m.dst[p], imp)
+				}
+			}
+			if _, ok := m.dst[p]; !ok {
+				m.dst[p] = []string{}
+			}
+		}
+	}
+}
+
+func dependencyNames(deps cfg.Dependencies) []string {
+	names := make([]string, len(deps))
+	for i, d := range deps {
+		names[i] = d.Name
+	}
+	return names
+}
@@ -0,0 +1,62 @@
+package action
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Masterminds/glide/cfg"
+	"github.com/Masterminds/glide/dependency"
+)
+
+func TestUnusedDependencies(t *testing.T) {
+	conf := &cfg.Config{
+		Name: "example.com/app",
+		Imports: cfg.Dependencies{
+			{Name: "example.com/direct"},
+			{Name: "example.com/transitive"},
+			{Name: "example.com/unused"},
+			{Name: "example.com/scanned"},
+		},
+		DevImports: cfg.Dependencies{
+			{Name: "example.com/assert"},
+			{Name: "example.com/deptest"},
+		},
+	}
+	g := dependency.NewImportGraph()
+	g.Imports["example.com/app"] = []string{"fmt", "example.com/direct/sub"}
+	g.TestImports["example.com/app"] = []string{"example.com/assert"}
+	g.Imports["example.com/direct/sub"] = []string{"example.com/transitive"}
+	g.TestImports["example.com/direct/sub"] = []string{"example.com/deptest"}
+	// Scanned because it is in the config but nothing reaches it.
+	g.Imports["example.com/scanned"] = []string{"example.com/direct"}
+
+	imps, devImps := unusedDependencies(conf, g, true)
+	if names := dependencyNames(imps); !reflect.DeepEqual(names, []string{"example.com/unused", "example.com/scanned"}) {
+		t.Errorf("Expected example.com/unused and example.com/scanned to be unused but got %v", names)
+	}
+	if names := dependencyNames(devImps); !reflect.DeepEqual(names, []string{"example.com/deptest"}) {
+		t.Errorf("Expected example.com/deptest to be an unused test import but got %v", names)
+	}
+
+	if _, devImps := unusedDependencies(conf, g, false); len(devImps) != 0 {
+		t.Errorf("Expected test imports to be skipped but got %v", dependencyNames(devImps))
+	}
+}
+
+func TestMergeGraph(t *testing.T) {
+	dst := dependency.NewImportGraph()
+	dst.Imports["a"] = []string{"b"}
+	src := dependency.NewImportGraph()
+	src.Imports["a"] = []string{"b", "c"}
+	src.Imports["c"] = nil
+	src.TestImports["a"] = []string{"d"}
+
+	mergeGraph(dst, src)
+	expected := map[string][]string{"a": {"b", "c"}, "c": {}}
+	if !reflect.DeepEqual(dst.Imports, expected) {
+		t.Errorf("Expected imports %v but got %v", expected, dst.Imports)
+	}
+	if !reflect.DeepEqual(dst.TestImports, map[string][]string{"a": {"d"}}) {
+		t.Errorf("Expected test imports of a to be [d] but got %v", dst.TestImports)
+	}
+}
@@ -23,6 +23,10 @@ func Remove(packages []string, inst *repo.Installer) {
 	msg.Info("Preparing to remove %d packages.", len(packages))
 	conf.Imports = rmDeps(packages, conf.Imports)
 	conf.DevImports = rmDeps(packages, conf.DevImports)
+	for name, g := range conf.Groups {
+		conf.Groups[name] = rmDeps(packages, g)
+	}
+	conf.Tools = rmDeps(packages, conf.Tools)
 
 	// Copy used to generate locks.
 	confcopy := conf.Clone()
@@ -39,7 +43,7 @@ func Remove(packages []string, inst *repo.Installer) {
 	}
 
 	// Write glide.yaml
-	if err := conf.WriteFile(glidefile); err != nil {
+	if err := conf.UpdateFile(glidefile); err != nil {
 		msg.Die("Failed to write glide YAML file: %s", err)
 	}
 
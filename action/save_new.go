@@ -0,0 +1,128 @@
+package action
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/Masterminds/glide/cache"
+	"github.com/Masterminds/glide/cfg"
+	"github.com/Masterminds/glide/dependency"
+	"github.com/Masterminds/glide/msg"
+	"github.com/Masterminds/semver"
+)
+
+// saveNewImports adds the dependencies the packages of the project import directly
+// but the glide.yaml file does not list to the config and returns them. Those
+// only imported by tests are added as test imports. Each gets a range tracking
+// the minor releases of its latest semantic version tag, if it has one. The
+// glide.yaml file is written by updateWrite along with the lock file.
+//
+// Params:
+//  - conf (*cfg.Config): The config read from the glide.yaml file
+//  - resolved (*cfg.Config): The config holding the resolved dependencies
+//  - g (*dependency.ImportGraph): The imports recorded while resolving
+func saveNewImports(conf, resolved *cfg.Config, g *dependency.ImportGraph) cfg.Dependencies {
+	imps, devImps := newDependencies(conf, resolved, g)
+	if len(imps) == 0 && len(devImps) == 0 {
+		msg.Info("No new dependencies to add to the glide.yaml file")
+		return nil
+	}
+
+	for _, d := range append(append(cfg.Dependencies{}, imps...), devImps...) {
+		d.Reference = suggestVersion(d, resolved)
+		if d.Reference != "" {
+			msg.Info("--> Adding %s to your configuration with the version %s", d.Name, d.Reference)
+		} else {
+			msg.Info("--> Adding %s to your configuration", d.Name)
+		}
+	}
+	conf.Imports = append(conf.Imports, imps...)
+	conf.DevImports = append(conf.DevImports, devImps...)
+	msg.Info("Adding %d imports and %d test imports to the glide.yaml file", len(imps), len(devImps))
+
+	return append(imps, devImps...)
+}
+
+// newDependencies returns the resolved dependencies imported directly by the
+// packages of the project that the config does not list, split into imports
+// and those only imported by tests. Only the subpackages imported directly are
+// kept.
+func newDependencies(conf, resolved *cfg.Config, g *dependency.ImportGraph) (cfg.Dependencies, cfg.Dependencies) {
+	local := func(p string) bool {
+		return p == conf.Name || strings.HasPrefix(p, conf.Name+"/")
+	}
+	imported := map[string]bool{}
+	testImported := map[string]bool{}
+	for p, imps := range g.Imports {
+		if !local(p) {
+			continue
+		}
+		for _, imp := range imps {
+			imported[imp] = true
+		}
+		for _, imp := range g.TestImports[p] {
+			testImported[imp] = true
+		}
+	}
+
+	var imps, devImps cfg.Dependencies
+	for _, d := range append(append(cfg.Dependencies{}, resolved.Imports...), resolved.DevImports...) {
+		if conf.HasDependency(d.Name) || conf.HasIgnore(d.Name) || imps.Has(d.Name) || devImps.Has(d.Name) {
+			continue
+		}
+		nd := &cfg.Dependency{
+			Name:        d.Name,
+			Repository:  d.Repository,
+			VcsType:     d.VcsType,
+			Subpackages: []string{},
+		}
+		switch {
+		case importsFrom(imported, d.Name):
+			nd.Subpackages = directSubpackages(imported, d.Name)
+			imps = append(imps, nd)
+		case importsFrom(testImported, d.Name):
+			nd.Subpackages = directSubpackages(testImported, d.Name)
+			devImps = append(devImps, nd)
+		}
+	}
+	return imps, devImps
+}
+
+// directSubpackages returns the subpackages of a root package in a set of
+// packages.
+func directSubpackages(pkgs map[string]bool, root string) []string {
+	subs := []string{}
+	for p := range pkgs {
+		if strings.HasPrefix(p, root+"/") {
+			subs = append(subs, strings.TrimPrefix(p, root+"/"))
+		}
+	}
+	sort.Strings(subs)
+	return subs
+}
+
+// suggestVersion returns a range tracking the minor releases of the latest
+// semantic version tag of a dependency, as the wizard of glide get suggests.
+// When a dependency of the project asked for a range the latest tag is not in,
+// or there is no tag, that range is kept instead so the versions resolved do
+// not change.
+func suggestVersion(d *cfg.Dependency, resolved *cfg.Config) string {
+	var ref string
+	if r := resolved.Imports.Get(d.Name); r != nil {
+		ref = r.Reference
+	} else if r := resolved.DevImports.Get(d.Name); r != nil {
+		ref = r.Reference
+	}
+	c, err := semver.NewConstraint(ref)
+	if ref == "" || err != nil {
+		ref, c = "", nil
+	}
+
+	msg.Info("--> Gathering release information for %s", d.Name)
+	wizardFindVersions(d)
+	sv, err := semver.NewVersion(cache.MemLatest(d.Remote()))
+	if err != nil || (c != nil && !c.Check(sv)) {
+		return ref
+	}
+	return "^" + sv.String()
+}
@@ -0,0 +1,53 @@
+package action
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Masterminds/glide/cfg"
+	"github.com/Masterminds/glide/dependency"
+)
+
+func TestNewDependencies(t *testing.T) {
+	conf := &cfg.Config{
+		Name:    "example.com/app",
+		Imports: cfg.Dependencies{{Name: "example.com/listed"}},
+		Ignore:  []string{"example.com/ignored"},
+	}
+	resolved := &cfg.Config{
+		Name: "example.com/app",
+		Imports: cfg.Dependencies{
+			{Name: "example.com/listed"},
+			{Name: "example.com/new", Repository: "https://example.com/fork/new", Subpackages: []string{"a", "b", "c"}},
+			{Name: "example.com/transitive"},
+			{Name: "example.com/ignored"},
+		},
+		DevImports: cfg.Dependencies{
+			{Name: "example.com/assert"},
+		},
+	}
+	g := dependency.NewImportGraph()
+	g.Imports["example.com/app"] = []string{"fmt", "example.com/listed", "example.com/new/b", "example.com/ignored"}
+	g.Imports["example.com/app/cmd"] = []string{"example.com/app", "example.com/new/a"}
+	g.TestImports["example.com/app/cmd"] = []string{"example.com/assert", "example.com/new/c"}
+	g.Imports["example.com/new/a"] = []string{"example.com/transitive"}
+
+	imps, devImps := newDependencies(conf, resolved, g)
+	if len(imps) != 1 || imps[0].Name != "example.com/new" {
+		t.Fatalf("Expected example.com/new to be the only new import but got %v", dependencyNames(imps))
+	}
+	if !reflect.DeepEqual(imps[0].Subpackages, []string{"a", "b"}) {
+		t.Errorf("Expected the subpackages imported directly but got %v", imps[0].Subpackages)
+	}
+	if imps[0].Repository != "https://example.com/fork/new" {
+		t.Errorf("Expected the repository to be kept but got %q", imps[0].Repository)
+	}
+	if names := dependencyNames(devImps); !reflect.DeepEqual(names, []string{"example.com/assert"}) {
+		t.Errorf("Expected example.com/assert to be a new test import but got %v", names)
+	}
+
+	// The resolved config is not changed.
+	if len(resolved.Imports[1].Subpackages) != 3 {
+		t.Errorf("Expected the resolved subpackages to be left as they were but got %v", resolved.Imports[1].Subpackages)
+	}
+}
@@ -0,0 +1,185 @@
+package action
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"runtime"
+	"sort"
+
+	"github.com/Masterminds/glide/cfg"
+	"github.com/Masterminds/glide/gomod"
+	"github.com/Masterminds/glide/msg"
+	gpath "github.com/Masterminds/glide/path"
+	"gopkg.in/yaml.v2"
+)
+
+// toolsStateFile records, within the bin directory, the locked version each
+// tool was built from.
+const toolsStateFile = ".glide-tools"
+
+// ToolsInstall builds the tools listed in the glide.yaml file into the bin
+// directory of the project.
+//
+// Tools are built from the source in the vendor directory at the versions in
+// the glide.lock file. A tool is only rebuilt when its locked version changes
+// or its binary is missing.
+//
+// Params:
+//  - force (bool): Rebuild every tool
+func ToolsInstall(force bool) {
+	conf := EnsureConfig()
+	if len(conf.Tools) == 0 {
+		msg.Info("No tools found. Nothing built.")
+		return
+	}
+
+	yamlpath, err := gpath.Glide()
+	if err != nil {
+		msg.Die("Could not find %s: %s", gpath.GlideFile, err)
+	}
+	base := filepath.Dir(yamlpath)
+	if !gpath.HasLock(base) {
+		msg.Die("Lock file (%s) does not exist. Please run `glide install` first.", gpath.LockFile)
+	}
+	lock, err := cfg.ReadLockFile(filepath.Join(base, gpath.LockFile))
+	if err != nil {
+		msg.Die("Could not load lockfile: %s", err)
+	}
+
+	bin := filepath.Join(base, gpath.BinDir)
+	if err := os.MkdirAll(bin, 0755); err != nil {
+		msg.Die("Could not create %s: %s", bin, err)
+	}
+	state := readToolsState(bin)
+
+	built := map[string]string{}
+	for _, t := range conf.Tools {
+		l := findLock(lock, t.Name)
+		if l == nil {
+			msg.Die("%s is not in %s. Please run `glide up`.", t.Name, gpath.LockFile)
+		}
+
+		for _, pkg := range toolPackages(t) {
+			name := path.Base(pkg)
+			if runtime.GOOS == "windows" {
+				name += ".exe"
+			}
+			if other, ok := built[name]; ok {
+				msg.Die("The tools %s and %s both build %s", other, pkg, name)
+			}
+			built[name] = pkg
+
+			out := filepath.Join(bin, name)
+			if !force && toolUpToDate(out, pkg, l.Version, state) {
+				msg.Info("--> %s is up to date", pkg)
+				continue
+			}
+
+			if err := buildTool(base, pkg, out); err != nil {
+				msg.Die("Failed to build %s: %s", pkg, err)
+			}
+			state[pkg] = l.Version
+		}
+	}
+
+	// Tools no longer listed are forgotten so they are rebuilt if they return.
+	for pkg := range state {
+		found := false
+		for _, p := range built {
+			if p == pkg {
+				found = true
+			}
+		}
+		if !found {
+			delete(state, pkg)
+		}
+	}
+	if err := writeToolsState(bin, state); err != nil {
+		msg.Die("Failed to record the built tools: %s", err)
+	}
+}
+
+// findLock finds the locked version of a tool. Tools are normally in the tools
+// section but older lock files may only list them as imports.
+func findLock(lock *cfg.Lockfile, name string) *cfg.Lock {
+	for _, ls := range []cfg.Locks{lock.Tools, lock.Imports, lock.DevImports} {
+		for _, l := range ls {
+			if l.Name == name {
+				return l
+			}
+		}
+	}
+	return nil
+}
+
+// toolPackages returns the main packages of a tool dependency.
+func toolPackages(dep *cfg.Dependency) []string {
+	if len(dep.Subpackages) == 0 {
+		return []string{dep.Name}
+	}
+	pkgs := make([]string, 0, len(dep.Subpackages))
+	for _, sub := range dep.Subpackages {
+		pkgs = append(pkgs, path.Join(dep.Name, sub))
+	}
+	sort.Strings(pkgs)
+	return pkgs
+}
+
+// toolUpToDate returns true if the binary of a tool package exists and was
+// built from the locked version.
+func toolUpToDate(out, pkg, version string, state map[string]string) bool {
+	if _, err := os.Stat(out); err != nil {
+		return false
+	}
+	return version != "" && state[pkg] == version
+}
+
+// toolDir returns the directory, relative to the project, holding the source
+// of a tool package. The major version suffix of a Go module in a major branch
+// is not a directory when the module was vendored without it.
+func toolDir(base, pkg string) string {
+	vp := filepath.Join(base, gpath.VendorDir)
+	return filepath.Join(gpath.VendorDir, filepath.FromSlash(gomod.ImportDir(vp, pkg)))
+}
+
+func buildTool(base, pkg, out string) error {
+	dir := toolDir(base, pkg)
+	if _, err := os.Stat(filepath.Join(base, dir)); err != nil {
+		msg.Die("%s is not in the %s directory. Please run `glide install` first.", pkg, gpath.VendorDir)
+	}
+
+	msg.Info("--> Building %s", pkg)
+	// . in a filepath.Join is removed so it needs to be prepended separately.
+	p := "." + string(filepath.Separator) + dir
+	cmd := exec.Command(goExecutable(), "build", "-o", out, p)
+	cmd.Dir = base
+	o, err := cmd.CombinedOutput()
+	if err != nil {
+		msg.Err("go build output for %s:\n%s", pkg, o)
+	}
+	return err
+}
+
+func readToolsState(bin string) map[string]string {
+	state := map[string]string{}
+	b, err := ioutil.ReadFile(filepath.Join(bin, toolsStateFile))
+	if err != nil {
+		return state
+	}
+	if err := yaml.Unmarshal(b, &state); err != nil {
+		msg.Warn("Unable to read %s. Rebuilding all tools.", toolsStateFile)
+		return map[string]string{}
+	}
+	return state
+}
+
+func writeToolsState(bin string, state map[string]string) error {
+	b, err := yaml.Marshal(state)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(bin, toolsStateFile), b, 0644)
+}
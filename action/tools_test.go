@@ -0,0 +1,104 @@
+package action
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Masterminds/glide/cfg"
+)
+
+func TestToolPackages(t *testing.T) {
+	pkgs := toolPackages(&cfg.Dependency{Name: "github.com/golang/mock", Subpackages: []string{"mockgen", "."}})
+	if len(pkgs) != 2 || pkgs[0] != "github.com/golang/mock" || pkgs[1] != "github.com/golang/mock/mockgen" {
+		t.Errorf("Unexpected tool packages %v", pkgs)
+	}
+
+	pkgs = toolPackages(&cfg.Dependency{Name: "golang.org/x/tools"})
+	if len(pkgs) != 1 || pkgs[0] != "golang.org/x/tools" {
+		t.Errorf("Unexpected tool packages %v", pkgs)
+	}
+}
+
+func TestToolsState(t *testing.T) {
+	dir, err := ioutil.TempDir("", "glide-tools")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	if s := readToolsState(dir); len(s) != 0 {
+		t.Errorf("Expected no state without a state file but got %v", s)
+	}
+	if err := writeToolsState(dir, map[string]string{"github.com/golang/mock/mockgen": "abc123"}); err != nil {
+		t.Fatal(err)
+	}
+	if s := readToolsState(dir); s["github.com/golang/mock/mockgen"] != "abc123" {
+		t.Errorf("Unexpected state %v", s)
+	}
+}
+
+func TestToolUpToDate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "glide-tools")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	pkg := "github.com/golang/mock/mockgen"
+	out := filepath.Join(dir, "mockgen")
+	state := map[string]string{pkg: "abc123"}
+	if toolUpToDate(out, pkg, "abc123", state) {
+		t.Error("Expected a missing binary to be rebuilt")
+	}
+	if err := ioutil.WriteFile(out, []byte("bin"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if !toolUpToDate(out, pkg, "abc123", state) {
+		t.Error("Expected a binary built from the locked version to be skipped")
+	}
+	if toolUpToDate(out, pkg, "def456", state) {
+		t.Error("Expected a binary built from another version to be rebuilt")
+	}
+	if toolUpToDate(out, pkg, "", map[string]string{pkg: ""}) {
+		t.Error("Expected a tool without a locked version to be rebuilt")
+	}
+	if toolUpToDate(out, pkg, "abc123", map[string]string{}) {
+		t.Error("Expected a binary not recorded as built to be rebuilt")
+	}
+}
+
+func TestToolDir(t *testing.T) {
+	dir, err := ioutil.TempDir("", "glide-tools")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// example.com/foo is a major branch vendored without its suffix and
+	// example.com/bar was moved under it.
+	for p, content := range map[string]string{
+		"vendor/example.com/foo/go.mod":           "module example.com/foo/v2\n",
+		"vendor/example.com/foo/cmd/x/main.go":    "package main\n",
+		"vendor/example.com/bar/v2/go.mod":        "module example.com/bar/v2\n",
+		"vendor/example.com/bar/v2/cmd/y/main.go": "package main\n",
+	} {
+		f := filepath.Join(dir, filepath.FromSlash(p))
+		if err := os.MkdirAll(filepath.Dir(f), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(f, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for pkg, expected := range map[string]string{
+		"example.com/foo/v2/cmd/x": "vendor/example.com/foo/cmd/x",
+		"example.com/bar/v2/cmd/y": "vendor/example.com/bar/v2/cmd/y",
+	} {
+		if d := filepath.ToSlash(toolDir(dir, pkg)); d != expected {
+			t.Errorf("Expected %s to be built from %s, got %s", pkg, expected, d)
+		}
+	}
+}
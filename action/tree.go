@@ -2,9 +2,13 @@ package action
 
 import (
 	"container/list"
+	"encoding/json"
 	"os"
+	"path/filepath"
 
+	"github.com/Masterminds/glide/cfg"
 	"github.com/Masterminds/glide/msg"
+	gpath "github.com/Masterminds/glide/path"
 	"github.com/Masterminds/glide/tree"
 	"github.com/Masterminds/glide/util"
 )
@@ -31,3 +35,77 @@ func Tree(basedir string, showcore bool) {
 	l.PushBack(myName)
 	tree.Display(buildContext, basedir, myName, 1, showcore, l)
 }
+
+const (
+	dotFormat     = "dot"
+	graphmlFormat = "graphml"
+)
+
+// TreeGraph prints the import graph of a project, at the package level and
+// at the repository level, in a format other programs can read.
+//
+// The packages are annotated with where they were found and the repositories
+// with the versions locked in the glide.lock file, if there is one. Packages
+// and imports only reached through tests, and imports that lead to a cycle,
+// are marked.
+//
+// Params:
+//  - basedir (string): The directory of the project
+//  - showcore (bool): Whether to include the packages of the standard library
+//  - format (string): The format to output (dot, graphml, json, json-pretty)
+func TreeGraph(basedir string, showcore bool, format string) {
+	switch format {
+	case dotFormat, graphmlFormat, jsonFormat, jsonPrettyFormat:
+	default:
+		msg.Die("invalid output format: must be one of: dot|graphml|json|json-pretty|text")
+	}
+
+	buildContext, err := util.GetBuildContext()
+	if err != nil {
+		msg.Die("Failed to get a build context: %s", err)
+	}
+	myName := buildContext.PackageName(basedir)
+
+	basedir, err = filepath.Abs(basedir)
+	if err != nil {
+		msg.Die("Could not get working directory")
+	}
+
+	g := tree.Build(buildContext, basedir, myName, showcore)
+
+	roots := []string{myName}
+	versions := map[string]string{}
+	if gpath.HasLock(basedir) {
+		lock, err := cfg.ReadLockFile(filepath.Join(basedir, gpath.LockFile))
+		if err != nil {
+			msg.Die("Unable to read %s: %s", gpath.LockFile, err)
+		}
+		for name, l := range diffLockMap(lock) {
+			roots = append(roots, name)
+			versions[name] = l.Version
+		}
+	}
+	g.Group(func(pkg string) string {
+		return rootPackage(roots, pkg)
+	}, func(root string) string {
+		return versions[root]
+	})
+
+	switch format {
+	case dotFormat:
+		err = g.WriteDot(msg.Default.Stdout)
+	case graphmlFormat:
+		err = g.WriteGraphML(msg.Default.Stdout)
+	case jsonFormat:
+		err = json.NewEncoder(msg.Default.Stdout).Encode(g)
+	case jsonPrettyFormat:
+		var b []byte
+		b, err = json.MarshalIndent(g, "", "  ")
+		if err == nil {
+			msg.Puts("%s", b)
+		}
+	}
+	if err != nil {
+		msg.Die("Unable to write the graph: %s", err)
+	}
+}
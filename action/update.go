@@ -6,20 +6,29 @@ import (
 
 	"github.com/Masterminds/glide/cache"
 	"github.com/Masterminds/glide/cfg"
+	"github.com/Masterminds/glide/dependency"
 	"github.com/Masterminds/glide/msg"
 	gpath "github.com/Masterminds/glide/path"
 	"github.com/Masterminds/glide/repo"
 )
 
 // Update updates repos and the lock file from the main glide yaml.
-func Update(installer *repo.Installer, skipRecursive, stripVendor bool) {
+func Update(installer *repo.Installer, skipRecursive, stripVendor, saveNew bool) {
 	cache.SystemLock()
 
-	base := "."
 	EnsureGopath()
 	EnsureVendorDir()
 	conf := EnsureConfig()
 
+	confcopy, added := updateResolve(installer, conf, skipRecursive, saveNew)
+	updateWrite(installer, conf, confcopy, added, skipRecursive, stripVendor)
+}
+
+// updateResolve fetches and resolves the dependencies of the project in the
+// working directory. The returned config holds the resolved dependencies. With
+// saveNew the dependencies imported directly but not listed are added to conf
+// and returned so updateWrite can save them to the glide.yaml file.
+func updateResolve(installer *repo.Installer, conf *cfg.Config, skipRecursive, saveNew bool) (*cfg.Config, cfg.Dependencies) {
 	// Try to check out the initial dependencies.
 	if err := installer.Checkout(conf); err != nil {
 		msg.Die("Failed to do initial checkout of config: %s", err)
@@ -35,12 +44,24 @@ func Update(installer *repo.Installer, skipRecursive, stripVendor bool) {
 	// of the conf because we'll be making real changes to it.
 	confcopy := conf.Clone()
 
+	var added cfg.Dependencies
 	if !skipRecursive {
-		// Get all repos and update them.
-		err := installer.Update(confcopy)
-		if err != nil {
+		// Record the imports while resolving to find the dependencies in the
+		// glide.yaml file nothing uses any more.
+		installer.Graph = dependency.NewImportGraph()
+		defer func() { installer.Graph = nil }()
+
+		// Get all repos and update them, once for each platform when there
+		// are several to resolve for.
+		if len(installer.Platforms) > 0 {
+			confcopy = updatePlatforms(installer, confcopy)
+		} else if err := installer.Update(confcopy); err != nil {
 			msg.Die("Could not update packages: %s", err)
 		}
+		if saveNew {
+			added = saveNewImports(conf, confcopy, installer.Graph)
+		}
+		warnUnused(conf, installer.Graph, installer.ResolveTest)
 
 		// Set references. There may be no remaining references to set since the
 		// installer set them as it went to make sure it parsed the right imports
@@ -51,6 +72,14 @@ func Update(installer *repo.Installer, skipRecursive, stripVendor bool) {
 		}
 	}
 
+	return confcopy, added
+}
+
+// updateWrite exports resolved dependencies to the vendor directory of the
+// project in the working directory and writes its lock file. When dependencies
+// were added to conf while resolving, the glide.yaml file is written first.
+func updateWrite(installer *repo.Installer, conf, confcopy *cfg.Config, added cfg.Dependencies, skipRecursive, stripVendor bool) {
+	base := "."
 	err := installer.Export(confcopy)
 	if err != nil {
 		msg.Die("Unable to export dependencies to vendor directory: %s", err)
@@ -61,9 +90,19 @@ func Update(installer *repo.Installer, skipRecursive, stripVendor bool) {
 	// change anything important. It will just generate information about
 	// transative dependencies, all of which belongs exclusively in the lock
 	// file, not the glide.yaml file.
-	// TODO(mattfarina): Detect when a new dependency has been added or removed
-	// from the project. A removed dependency should warn and an added dependency
-	// should be added to the glide.yaml file. See issue #193.
+	// Dependencies nothing imports any more are warned about while resolving.
+	// The exception is the new imports saved with --save-new, which are only
+	// written once everything else has succeeded.
+	if len(added) > 0 {
+		glidefile, err := gpath.Glide()
+		if err != nil {
+			msg.Die("Could not find Glide file: %s", err)
+		}
+		if err := conf.UpdateFile(glidefile); err != nil {
+			msg.Die("Failed to write glide YAML file: %s", err)
+		}
+		msg.Info("Added %d dependencies to the glide.yaml file", len(added))
+	}
 
 	if !skipRecursive {
 		// Write lock
@@ -75,6 +114,8 @@ func Update(installer *repo.Installer, skipRecursive, stripVendor bool) {
 		if err != nil {
 			msg.Die("Failed to generate lock file: %s", err)
 		}
+		lock.Groups = cfg.LocksFromGroups(confcopy.Groups)
+		lock.Tools = cfg.LocksFromDependencies(confcopy.Tools)
 		wl := true
 		if gpath.HasLock(base) {
 			yml, err := ioutil.ReadFile(filepath.Join(base, gpath.LockFile))
@@ -0,0 +1,259 @@
+package action
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/Masterminds/glide/cfg"
+	"github.com/Masterminds/glide/msg"
+	gpath "github.com/Masterminds/glide/path"
+	"github.com/Masterminds/glide/repo"
+)
+
+// Verify audits the vendor/ directory against the glide.lock file.
+//
+// It does not contact any remote systems. Missing dependencies, unexpected
+// directories, VCS versions that differ from the lock file, and locally
+// modified dependencies are reported. When any drift is found Verify exits
+// with a non-zero exit code.
+//
+// Params:
+//  - format (string): The format to output (text, json, json-pretty)
+//  - skipTest (bool): Do not require test imports to be present
+func Verify(format string, skipTest bool) {
+	base := "."
+	if !gpath.HasLock(base) {
+		msg.ExitCode(2)
+		msg.Die("Lock file (%s) does not exist. Nothing to verify.", gpath.LockFile)
+	}
+	lock, err := cfg.ReadLockFile(filepath.Join(base, gpath.LockFile))
+	if err != nil {
+		msg.ExitCode(2)
+		msg.Die("Could not load lockfile: %s", err)
+	}
+
+	vpath, err := gpath.Vendor()
+	if err != nil {
+		msg.ExitCode(2)
+		msg.Die("Could not get vendor path: %s", err)
+	}
+
+	r, err := verifyVendor(vpath, lock, skipTest)
+	if err != nil {
+		msg.ExitCode(2)
+		msg.Die("Unable to verify %s: %s", gpath.VendorDir, err)
+	}
+
+	outputVerify(r, format)
+
+	if r.Drifted() {
+		msg.Die("The %s directory does not match %s", gpath.VendorDir, gpath.LockFile)
+	}
+}
+
+// VerifyReport describes the differences between vendor/ and glide.lock.
+type VerifyReport struct {
+	Missing  []string       `json:"missing"`
+	Extra    []string       `json:"extra"`
+	Versions []VersionDrift `json:"versions"`
+	Modified []string       `json:"modified"`
+}
+
+// VersionDrift is a dependency whose vendored VCS version differs from the
+// version in the lock file.
+type VersionDrift struct {
+	Name     string `json:"name"`
+	Locked   string `json:"locked"`
+	Vendored string `json:"vendored"`
+}
+
+// Drifted returns true if any difference was found.
+func (v *VerifyReport) Drifted() bool {
+	return len(v.Missing) > 0 || len(v.Extra) > 0 || len(v.Versions) > 0 || len(v.Modified) > 0
+}
+
+func verifyVendor(vpath string, lock *cfg.Lockfile, skipTest bool) (*VerifyReport, error) {
+	r := &VerifyReport{
+		Missing:  []string{},
+		Extra:    []string{},
+		Versions: []VersionDrift{},
+		Modified: []string{},
+	}
+
+	locks := lock.Imports.Clone()
+	for _, l := range lock.DevImports {
+		if skipTest {
+			continue
+		}
+		found := false
+		for _, ll := range locks {
+			if ll.Name == l.Name {
+				found = true
+			}
+		}
+		if !found {
+			locks = append(locks, l)
+		}
+	}
+	others := []cfg.Locks{lock.Tools}
+	for _, g := range lock.Groups {
+		others = append(others, g)
+	}
+	for _, g := range others {
+		for _, l := range g {
+			found := false
+			for _, ll := range locks {
+				if ll.Name == l.Name {
+					found = true
+				}
+			}
+			if !found {
+				locks = append(locks, l)
+			}
+		}
+	}
+	sort.Sort(locks)
+
+	// A vendor directory installed with pruning records what was removed
+	// from each dependency.
+	pruned := &cfg.PruneManifest{}
+	if _, err := os.Stat(filepath.Join(vpath, gpath.PruneFile)); err == nil {
+		if pruned, err = cfg.ReadPruneManifest(filepath.Join(vpath, gpath.PruneFile)); err != nil {
+			return r, err
+		}
+	}
+
+	names := make(map[string]bool, len(locks))
+	for _, l := range locks {
+		names[l.Name] = true
+		if !l.ForPlatform(runtime.GOOS, runtime.GOARCH) {
+			// Dependencies for other platforms are not installed.
+			continue
+		}
+		dir := repo.VendoredDir(vpath, l.Name, l.Subpackages)
+		fi, err := os.Stat(dir)
+		if err != nil || !fi.IsDir() {
+			r.Missing = append(r.Missing, l.Name)
+			continue
+		}
+
+		// When the vendored copy carries VCS metadata, such as with submodules,
+		// the checked out version can be compared to the lock.
+		checkedDirty := false
+		if rp, err := repo.LocalRepo(dir); err == nil {
+			ver, err := rp.Version()
+			if err == nil && ver != l.Version {
+				r.Versions = append(r.Versions, VersionDrift{Name: l.Name, Locked: l.Version, Vendored: ver})
+				continue
+			}
+			if rp.IsDirty() {
+				r.Modified = append(r.Modified, l.Name)
+				continue
+			}
+			checkedDirty = true
+		}
+
+		if l.Digest != "" {
+			// A pruned dependency must have been pruned from the locked tree
+			// and match what was left of it.
+			expected := l.Digest
+			if p := pruned.Get(l.Name); p != nil {
+				if p.Digest != l.Digest {
+					r.Modified = append(r.Modified, l.Name)
+					continue
+				}
+				expected = p.PrunedDigest
+			}
+			d, err := repo.Digest(dir)
+			if err != nil {
+				return r, err
+			}
+			if d != expected {
+				r.Modified = append(r.Modified, l.Name)
+			}
+		} else if !checkedDirty {
+			msg.Debug("No digest or VCS metadata for %s. Unable to detect local modifications", l.Name)
+		}
+	}
+
+	// Look for directories in vendor/ that no locked dependency accounts for.
+	err := filepath.Walk(vpath, func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !fi.IsDir() || path == vpath {
+			return nil
+		}
+		if strings.HasPrefix(fi.Name(), ".") {
+			return filepath.SkipDir
+		}
+		rel, err := filepath.Rel(vpath, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if names[rel] {
+			return filepath.SkipDir
+		}
+		for n := range names {
+			if strings.HasPrefix(n, rel+"/") {
+				return nil
+			}
+		}
+		r.Extra = append(r.Extra, rel)
+		return filepath.SkipDir
+	})
+	if err != nil && !os.IsNotExist(err) {
+		return r, err
+	}
+
+	return r, nil
+}
+
+func outputVerify(r *VerifyReport, format string) {
+	switch format {
+	case textFormat:
+		if !r.Drifted() {
+			msg.Puts("The %s directory matches %s", gpath.VendorDir, gpath.LockFile)
+			return
+		}
+		if len(r.Missing) > 0 {
+			msg.Puts("MISSING dependencies:")
+			for _, n := range r.Missing {
+				msg.Puts("\t%s", n)
+			}
+		}
+		if len(r.Extra) > 0 {
+			msg.Puts("EXTRA directories:")
+			for _, n := range r.Extra {
+				msg.Puts("\t%s", n)
+			}
+		}
+		if len(r.Versions) > 0 {
+			msg.Puts("VERSION mismatches:")
+			for _, v := range r.Versions {
+				msg.Puts("\t%s (locked %s, vendored %s)", v.Name, v.Locked, v.Vendored)
+			}
+		}
+		if len(r.Modified) > 0 {
+			msg.Puts("MODIFIED dependencies:")
+			for _, n := range r.Modified {
+				msg.Puts("\t%s", n)
+			}
+		}
+	case jsonFormat:
+		json.NewEncoder(msg.Default.Stdout).Encode(r)
+	case jsonPrettyFormat:
+		b, err := json.MarshalIndent(r, "", "  ")
+		if err != nil {
+			msg.Die("could not marshal verify report: %s", err)
+		}
+		msg.Puts("%s", b)
+	default:
+		msg.Die("invalid output format: must be one of: json|json-pretty|text")
+	}
+}
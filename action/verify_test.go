@@ -0,0 +1,198 @@
+package action
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Masterminds/glide/cfg"
+	gpath "github.com/Masterminds/glide/path"
+	"github.com/Masterminds/glide/repo"
+)
+
+func TestVerifyVendor(t *testing.T) {
+	vdir, err := ioutil.TempDir("", "glide-verify")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(vdir)
+
+	mk := func(pkg, content string) string {
+		p := filepath.Join(vdir, filepath.FromSlash(pkg))
+		if err := os.MkdirAll(p, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filepath.Join(p, "a.go"), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+
+	good := mk("github.com/foo/good", "package good\n")
+	gd, err := repo.Digest(good)
+	if err != nil {
+		t.Fatal(err)
+	}
+	changed := mk("github.com/foo/changed", "package changed\n")
+	cd, err := repo.Digest(changed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(changed, "a.go"), []byte("package changed // edited\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	mk("github.com/foo/extra", "package extra\n")
+
+	lock := &cfg.Lockfile{
+		Imports: cfg.Locks{
+			{Name: "github.com/foo/good", Version: "abc", Digest: gd},
+			{Name: "github.com/foo/changed", Version: "abc", Digest: cd},
+			{Name: "github.com/foo/missing", Version: "abc"},
+		},
+		DevImports: cfg.Locks{
+			{Name: "github.com/foo/testonly", Version: "abc"},
+		},
+	}
+
+	r, err := verifyVendor(vdir, lock, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !r.Drifted() {
+		t.Error("Expected drift to be detected")
+	}
+	if len(r.Missing) != 2 || r.Missing[0] != "github.com/foo/missing" || r.Missing[1] != "github.com/foo/testonly" {
+		t.Errorf("Unexpected missing dependencies %v", r.Missing)
+	}
+	if len(r.Extra) != 1 || r.Extra[0] != "github.com/foo/extra" {
+		t.Errorf("Unexpected extra directories %v", r.Extra)
+	}
+	if len(r.Modified) != 1 || r.Modified[0] != "github.com/foo/changed" {
+		t.Errorf("Unexpected modified dependencies %v", r.Modified)
+	}
+
+	r, err = verifyVendor(vdir, lock, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(r.Missing) != 1 {
+		t.Errorf("Expected test imports to be skipped, got missing %v", r.Missing)
+	}
+}
+
+func TestVerifyVendorPruned(t *testing.T) {
+	vdir, err := ioutil.TempDir("", "glide-verify")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(vdir)
+
+	dir := filepath.Join(vdir, "github.com", "foo", "pruned")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "a.go"), []byte("package pruned\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "a_test.go"), []byte("package pruned\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	full, err := repo.Digest(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filepath.Join(dir, "a_test.go")); err != nil {
+		t.Fatal(err)
+	}
+	pd, err := repo.Digest(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := &cfg.PruneManifest{Pruned: []*cfg.PrunedDependency{
+		{Name: "github.com/foo/pruned", Digest: full, PrunedDigest: pd, Removed: []string{"a_test.go"}},
+	}}
+	if err := m.WriteFile(filepath.Join(vdir, "glide.pruned.yaml")); err != nil {
+		t.Fatal(err)
+	}
+
+	lock := &cfg.Lockfile{
+		Imports: cfg.Locks{{Name: "github.com/foo/pruned", Version: "abc", Digest: full}},
+	}
+	r, err := verifyVendor(vdir, lock, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Drifted() {
+		t.Errorf("Expected the pruned dependency to match the lock file, got %+v", r)
+	}
+
+	// A lock file for another tree no longer matches what was pruned.
+	lock.Imports[0].Digest = pd
+	r, err = verifyVendor(vdir, lock, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(r.Modified) != 1 || r.Modified[0] != "github.com/foo/pruned" {
+		t.Errorf("Unexpected modified dependencies %v", r.Modified)
+	}
+}
+
+func TestVerifyVendorStripped(t *testing.T) {
+	base, err := ioutil.TempDir("", "glide-verify")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(base)
+
+	if err := ioutil.WriteFile(filepath.Join(base, "glide.yaml"), []byte("package: github.com/foo/app\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	vdir := filepath.Join(base, "vendor")
+	dir := filepath.Join(vdir, "github.com", "foo", "nested")
+	for p, content := range map[string]string{
+		"a.go":                                   "package nested\n",
+		"vendor/github.com/foo/bar/bar.go":       "package bar\n",
+		"Godeps/Godeps.json":                     "{}\n",
+		"Godeps/_workspace/src/github.com/x/x.c": "int x;\n",
+	} {
+		f := filepath.Join(dir, filepath.FromSlash(p))
+		if err := os.MkdirAll(filepath.Dir(f), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(f, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	d, err := repo.Digest(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(base); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+	if err := gpath.StripVendor(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "vendor")); !os.IsNotExist(err) {
+		t.Fatal("Expected the nested vendor directory to be stripped")
+	}
+
+	lock := &cfg.Lockfile{
+		Imports: cfg.Locks{{Name: "github.com/foo/nested", Version: "abc", Digest: d}},
+	}
+	r, err := verifyVendor(vdir, lock, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Drifted() {
+		t.Errorf("Expected the stripped dependency to match the lock file, got %+v", r)
+	}
+}
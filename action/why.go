@@ -0,0 +1,377 @@
+package action
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Masterminds/glide/cfg"
+	"github.com/Masterminds/glide/dependency"
+	"github.com/Masterminds/glide/importer"
+	"github.com/Masterminds/glide/msg"
+	gpath "github.com/Masterminds/glide/path"
+	"github.com/Masterminds/glide/util"
+)
+
+// Why prints every import chain from the packages of the project to a package.
+//
+// The chains are found by resolving the imports of the project and the
+// packages in its vendor directory. Chains that start in the tests of the
+// project are marked as such. For each step into another project the version
+// the configuration of the importing project asks for is shown.
+//
+// Params:
+//  - pkg (string): The package, or the root package of a repository, to explain
+//  - format (string): The format to output (text, json, json-pretty)
+func Why(pkg, format string) {
+	conf := EnsureConfig()
+	base := "."
+	if yamlpath, err := gpath.Glide(); err == nil {
+		base = filepath.Dir(yamlpath)
+	}
+
+	r, err := dependency.NewResolver(base)
+	if err != nil {
+		msg.Die("Could not create a resolver: %s", err)
+	}
+	r.Config = conf
+	r.ResolveTest = true
+	r.Handler = whyPackageHandler{&dependency.DefaultMissingPackageHandler{Missing: []string{}, Gopath: []string{}, Prefix: r.VendorDir}}
+	r.VersionHandler = whyVersionHandler{}
+	r.Graph = dependency.NewImportGraph()
+
+	w := &why{
+		conf:    conf,
+		graph:   r.Graph,
+		vendor:  r.VendorDir,
+		roots:   whyRoots(conf, base),
+		configs: map[string]cfg.Dependencies{},
+	}
+
+	if _, _, err := r.ResolveLocal(true); err != nil {
+		msg.Warn("Not every package could be scanned. Run `glide install` to install missing packages.")
+	}
+	w.resolveTestOnly(r)
+
+	outputWhy(w.chains(strings.TrimSuffix(pkg, "/")), format)
+}
+
+// WhyResult lists the import chains leading to a package.
+type WhyResult struct {
+	Package string     `json:"package"`
+	Chains  []WhyChain `json:"chains"`
+}
+
+// WhyChain is an import chain from a package of the project to the package
+// asked about. Test is set when the chain only exists through the tests of the
+// project.
+type WhyChain struct {
+	Test     bool      `json:"test"`
+	Packages []WhyStep `json:"packages"`
+}
+
+// WhyStep is a package in an import chain. The constraint is the version the
+// configuration of the project importing the package asks for when the package
+// is in another project.
+type WhyStep struct {
+	Package    string `json:"package"`
+	Constraint string `json:"constraint,omitempty"`
+}
+
+// whyPackageHandler does not report each package found in the vendor
+// directory.
+type whyPackageHandler struct {
+	*dependency.DefaultMissingPackageHandler
+}
+
+func (whyPackageHandler) InVendor(pkg string, addTest bool) error {
+	return nil
+}
+
+// whyVersionHandler leaves the versions of packages as they are in the vendor
+// directory without warning about each.
+type whyVersionHandler struct{}
+
+func (whyVersionHandler) Process(pkg string) error {
+	return nil
+}
+
+func (whyVersionHandler) SetVersion(pkg string, testDep bool) error {
+	return nil
+}
+
+// resolveTestOnly scans the imports of vendored packages only reached through
+// the tests of the project. The resolver only follows the test imports of
+// those.
+func (w *why) resolveTestOnly(r *dependency.Resolver) {
+	tried := map[string]bool{}
+	for {
+		visited := map[string]bool{}
+		var scan []string
+		for p, imps := range r.Graph.TestImports {
+			if !w.local(p) {
+				continue
+			}
+			for _, imp := range imps {
+				scan = append(scan, unscanned(r, imp, visited, tried)...)
+			}
+		}
+		if len(scan) == 0 {
+			return
+		}
+		for _, p := range scan {
+			tried[p] = true
+			r.Resolve(p, r.VendorDir)
+		}
+	}
+}
+
+// unscanned returns the vendored packages reachable from a package that are
+// not in the graph yet and have not been tried.
+func unscanned(r *dependency.Resolver, pkg string, visited, tried map[string]bool) []string {
+	if visited[pkg] {
+		return nil
+	}
+	visited[pkg] = true
+	imps, ok := r.Graph.Imports[pkg]
+	if !ok {
+		if tried[pkg] || r.FindPkg(pkg).Loc != dependency.LocVendor {
+			return nil
+		}
+		return []string{pkg}
+	}
+	var out []string
+	for _, imp := range imps {
+		out = append(out, unscanned(r, imp, visited, tried)...)
+	}
+	return out
+}
+
+// local returns true if a package is one of the project's own.
+func (w *why) local(pkg string) bool {
+	return w.root(pkg) == w.conf.Name
+}
+
+// whyRoots returns the root packages of the dependencies in the config and
+// the lock file, if there is one.
+func whyRoots(conf *cfg.Config, base string) []string {
+	roots := []string{conf.Name}
+	for _, d := range conf.Imports {
+		roots = append(roots, d.Name)
+	}
+	for _, d := range conf.DevImports {
+		roots = append(roots, d.Name)
+	}
+	if gpath.HasLock(base) {
+		if lock, err := cfg.ReadLockFile(filepath.Join(base, gpath.LockFile)); err == nil {
+			for _, l := range diffLockMap(lock) {
+				roots = append(roots, l.Name)
+			}
+		}
+	}
+	return roots
+}
+
+type why struct {
+	conf    *cfg.Config
+	graph   *dependency.ImportGraph
+	vendor  string
+	roots   []string
+	configs map[string]cfg.Dependencies
+}
+
+// chains finds every import chain from a package of the project to the
+// target.
+func (w *why) chains(target string) *WhyResult {
+	res := &WhyResult{Package: target, Chains: []WhyChain{}}
+	matches := func(p string) bool {
+		return p == target || strings.HasPrefix(p, target+"/")
+	}
+
+	// Find the packages the target can be reached from first so only chains
+	// that lead to it are followed.
+	reach := map[string]bool{}
+	for changed := true; changed; {
+		changed = false
+		for p, imps := range w.graph.Imports {
+			if reach[p] || w.local(p) {
+				continue
+			}
+			if matches(p) {
+				reach[p] = true
+				changed = true
+				continue
+			}
+			for _, imp := range imps {
+				if reach[imp] || (matches(imp) && !w.local(imp)) {
+					reach[p] = true
+					changed = true
+					break
+				}
+			}
+		}
+	}
+	leads := func(p string) bool {
+		return !w.local(p) && (reach[p] || matches(p))
+	}
+
+	seen := map[string]bool{}
+	var walk func(path []string, test bool)
+	walk = func(path []string, test bool) {
+		cur := path[len(path)-1]
+		if matches(cur) {
+			key := strings.Join(path, " ")
+			if !seen[key] {
+				seen[key] = true
+				res.Chains = append(res.Chains, WhyChain{Test: test, Packages: w.steps(path)})
+			}
+			return
+		}
+		for _, imp := range sortedImports(w.graph.Imports[cur]) {
+			if leads(imp) && !containsPkg(path, imp) {
+				walk(append(path[:len(path):len(path)], imp), test)
+			}
+		}
+	}
+
+	var local []string
+	for p := range w.graph.Imports {
+		if w.local(p) {
+			local = append(local, p)
+		}
+	}
+	sort.Strings(local)
+
+	// The chains through the code of the project are found before those
+	// through its tests so a chain through both is not marked as a test one.
+	for _, p := range local {
+		for _, imp := range sortedImports(w.graph.Imports[p]) {
+			if leads(imp) {
+				walk([]string{p, imp}, false)
+			}
+		}
+	}
+	for _, p := range local {
+		for _, imp := range sortedImports(w.graph.TestImports[p]) {
+			if leads(imp) {
+				walk([]string{p, imp}, true)
+			}
+		}
+	}
+	return res
+}
+
+// steps adds the constraints to the packages in a chain.
+func (w *why) steps(path []string) []WhyStep {
+	steps := make([]WhyStep, len(path))
+	for i, p := range path {
+		steps[i].Package = p
+		if i == 0 {
+			continue
+		}
+		from, to := w.root(path[i-1]), w.root(p)
+		if from == to {
+			continue
+		}
+		if d := w.config(from).Get(to); d != nil {
+			steps[i].Constraint = d.Reference
+		}
+	}
+	return steps
+}
+
+// root returns the root package of the project a package is in.
+func (w *why) root(pkg string) string {
+	return rootPackage(w.roots, pkg)
+}
+
+// rootPackage returns the longest of the known root packages a package is in.
+// When it is in none of them the root is worked out from the name.
+func rootPackage(roots []string, pkg string) string {
+	best := ""
+	for _, r := range roots {
+		if (pkg == r || strings.HasPrefix(pkg, r+"/")) && len(r) > len(best) {
+			best = r
+		}
+	}
+	if best != "" {
+		return best
+	}
+	return util.GetRootFromPackage(pkg)
+}
+
+// config returns the dependencies the configuration of a project lists.
+func (w *why) config(root string) cfg.Dependencies {
+	if root == w.conf.Name {
+		return append(append(cfg.Dependencies{}, w.conf.Imports...), w.conf.DevImports...)
+	}
+	if deps, ok := w.configs[root]; ok {
+		return deps
+	}
+
+	var deps cfg.Dependencies
+	imp, err := importer.New(w.conf.Importers)
+	if err == nil {
+		var found bool
+		found, deps, err = imp.Import(filepath.Join(w.vendor, filepath.FromSlash(root)))
+		if !found {
+			deps = nil
+		}
+	}
+	if err != nil {
+		msg.Debug("Unable to read the configuration of %s: %s", root, err)
+	}
+	w.configs[root] = deps
+	return deps
+}
+
+func sortedImports(imps []string) []string {
+	s := append([]string{}, imps...)
+	sort.Strings(s)
+	return s
+}
+
+func containsPkg(path []string, pkg string) bool {
+	for _, p := range path {
+		if p == pkg {
+			return true
+		}
+	}
+	return false
+}
+
+func outputWhy(res *WhyResult, format string) {
+	switch format {
+	case textFormat:
+		if len(res.Chains) == 0 {
+			msg.Puts("%s is not imported by the project", res.Package)
+			return
+		}
+		msg.Puts("%s is imported through:", res.Package)
+		for _, c := range res.Chains {
+			parts := make([]string, len(c.Packages))
+			for i, s := range c.Packages {
+				parts[i] = s.Package
+				if s.Constraint != "" {
+					parts[i] += " [" + s.Constraint + "]"
+				}
+			}
+			line := strings.Join(parts, " -> ")
+			if c.Test {
+				line += " (tests only)"
+			}
+			msg.Puts("\t%s", line)
+		}
+	case jsonFormat:
+		json.NewEncoder(msg.Default.Stdout).Encode(res)
+	case jsonPrettyFormat:
+		b, err := json.MarshalIndent(res, "", "  ")
+		if err != nil {
+			msg.Die("could not marshal import chains: %s", err)
+		}
+		msg.Puts("%s", b)
+	default:
+		msg.Die("invalid output format: must be one of: json|json-pretty|text")
+	}
+}
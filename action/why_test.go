@@ -0,0 +1,68 @@
+package action
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Masterminds/glide/cfg"
+	"github.com/Masterminds/glide/dependency"
+)
+
+func TestWhyChains(t *testing.T) {
+	g := dependency.NewImportGraph()
+	g.Imports = map[string][]string{
+		"example.com/app":            {"fmt", "example.com/app/internal", "example.com/a"},
+		"example.com/app/internal":   {"example.com/b/sub"},
+		"example.com/a":              {"example.com/b/sub", "example.com/c"},
+		"example.com/b/sub":          {"example.com/target"},
+		"example.com/c":              {"strings"},
+		"example.com/target":         {"example.com/target/inner"},
+		"example.com/target/inner":   {},
+		"example.com/testonly":       {"example.com/target/inner"},
+		"example.com/app/cmd/server": {"example.com/c"},
+	}
+	g.TestImports = map[string][]string{
+		"example.com/app":          {"example.com/testonly", "example.com/a"},
+		"example.com/app/internal": {},
+	}
+
+	w := &why{
+		conf: &cfg.Config{
+			Name:    "example.com/app",
+			Imports: cfg.Dependencies{{Name: "example.com/a", Reference: "^1.0.0"}},
+		},
+		graph: g,
+		roots: []string{"example.com/app", "example.com/a", "example.com/b", "example.com/target", "example.com/testonly"},
+		configs: map[string]cfg.Dependencies{
+			"example.com/a": {{Name: "example.com/b", Reference: "~2.1"}},
+			"example.com/b": {{Name: "example.com/target", Reference: "v3.0.0"}},
+		},
+	}
+
+	res := w.chains("example.com/target")
+	expected := []WhyChain{
+		{Packages: []WhyStep{
+			{Package: "example.com/app"},
+			{Package: "example.com/a", Constraint: "^1.0.0"},
+			{Package: "example.com/b/sub", Constraint: "~2.1"},
+			{Package: "example.com/target", Constraint: "v3.0.0"},
+		}},
+		{Packages: []WhyStep{
+			{Package: "example.com/app/internal"},
+			{Package: "example.com/b/sub"},
+			{Package: "example.com/target", Constraint: "v3.0.0"},
+		}},
+		{Test: true, Packages: []WhyStep{
+			{Package: "example.com/app"},
+			{Package: "example.com/testonly"},
+			{Package: "example.com/target/inner"},
+		}},
+	}
+	if !reflect.DeepEqual(res.Chains, expected) {
+		t.Errorf("Expected the chains %+v but got %+v", expected, res.Chains)
+	}
+
+	if res := w.chains("example.com/missing"); len(res.Chains) != 0 {
+		t.Errorf("Expected no chains to a package not imported but got %+v", res.Chains)
+	}
+}
@@ -0,0 +1,183 @@
+package action
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Masterminds/glide/cache"
+	"github.com/Masterminds/glide/cfg"
+	"github.com/Masterminds/glide/msg"
+	gpath "github.com/Masterminds/glide/path"
+	"github.com/Masterminds/glide/repo"
+)
+
+// workspaceProject is a project in a workspace along with its resolved
+// dependencies.
+type workspaceProject struct {
+	name     string
+	dir      string
+	conf     *cfg.Config
+	resolved *cfg.Config
+	added    cfg.Dependencies
+}
+
+// UpdateWorkspace updates every project listed in the workspace file.
+//
+// All of the projects are resolved before any of them are written so the
+// cache and the record of fetched repositories are shared between them. When
+// projects resolve a package to different versions it is reported. If a single
+// version is required the update stops before any vendor directory, lock file
+// or glide.yaml file is written.
+//
+// Params:
+//  - installer (*repo.Installer): The installer shared by all the projects
+//  - skipRecursive (bool): Only update the dependencies in the glide.yaml files
+//  - stripVendor (bool): Remove nested vendor directories
+//  - singleVersion (bool): Fail when a package resolves to more than one version
+//  - saveNew (bool): Add the dependencies imported but not listed to the glide.yaml files
+func UpdateWorkspace(installer *repo.Installer, skipRecursive, stripVendor, singleVersion, saveNew bool) {
+	cache.SystemLock()
+	EnsureGopath()
+
+	wpath, err := gpath.Workspace()
+	if err != nil {
+		msg.ExitCode(2)
+		msg.Die("Failed to find the workspace: %s", err)
+	}
+	ws, err := cfg.ReadWorkspaceFile(wpath)
+	if err != nil {
+		msg.ExitCode(3)
+		msg.Die("Failed to parse %s: %s", wpath, err)
+	}
+	singleVersion = singleVersion || ws.SingleVersion
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		msg.Die("Unable to get the current working directory: %s", err)
+	}
+	defer os.Chdir(cwd)
+
+	projects := make([]*workspaceProject, len(ws.Projects))
+	for i, p := range ws.Projects {
+		dir := filepath.Join(filepath.Dir(wpath), filepath.FromSlash(p))
+		if _, err := os.Stat(filepath.Join(dir, gpath.GlideFile)); err != nil {
+			msg.Die("Workspace project %s does not have a %s file", p, gpath.GlideFile)
+		}
+		projects[i] = &workspaceProject{name: p, dir: dir}
+	}
+
+	for _, p := range projects {
+		msg.Info("Resolving workspace project %s", p.name)
+		chdir(p.dir)
+		EnsureVendorDir()
+		p.conf = EnsureConfig()
+		p.resolved, p.added = updateResolve(installer, p.conf, skipRecursive, saveNew)
+	}
+
+	conflicts := workspaceConflicts(projects)
+	reportConflicts(conflicts, singleVersion)
+	if singleVersion && len(conflicts) > 0 {
+		msg.Die("Packages resolve to more than one version in the workspace")
+	}
+
+	for _, p := range projects {
+		msg.Info("Installing workspace project %s", p.name)
+		chdir(p.dir)
+
+		// The cache holds the versions resolved for the last project. Each
+		// project's versions are checked out again before exporting.
+		if len(projects) > 1 {
+			if err := checkoutResolved(p.resolved); err != nil {
+				msg.Die("Failed to check out the versions for %s: %s", p.name, err)
+			}
+		}
+		updateWrite(installer, p.conf, p.resolved, p.added, skipRecursive, stripVendor)
+	}
+}
+
+// versionConflict is a package resolved to different versions by projects in
+// a workspace. Versions maps each version to the projects using it.
+type versionConflict struct {
+	name     string
+	versions map[string][]string
+}
+
+// workspaceConflicts finds the packages resolved to more than one version.
+func workspaceConflicts(projects []*workspaceProject) []versionConflict {
+	found := map[string]map[string][]string{}
+	for _, p := range projects {
+		seen := map[string]bool{}
+		for _, d := range workspaceDependencies(p.resolved) {
+			if d.Pin == "" || seen[d.Name] {
+				continue
+			}
+			seen[d.Name] = true
+			if found[d.Name] == nil {
+				found[d.Name] = map[string][]string{}
+			}
+			found[d.Name][d.Pin] = append(found[d.Name][d.Pin], p.name)
+		}
+	}
+
+	var conflicts []versionConflict
+	for name, vers := range found {
+		if len(vers) > 1 {
+			conflicts = append(conflicts, versionConflict{name: name, versions: vers})
+		}
+	}
+	sort.Slice(conflicts, func(i, j int) bool { return conflicts[i].name < conflicts[j].name })
+	return conflicts
+}
+
+func reportConflicts(conflicts []versionConflict, singleVersion bool) {
+	report := msg.Warn
+	if singleVersion {
+		report = msg.Err
+	}
+	for _, c := range conflicts {
+		report("%s resolves to different versions in the workspace:", c.name)
+		vers := make([]string, 0, len(c.versions))
+		for v := range c.versions {
+			vers = append(vers, v)
+		}
+		sort.Strings(vers)
+		for _, v := range vers {
+			report("\t%s: %s", v, strings.Join(c.versions[v], ", "))
+		}
+	}
+}
+
+// checkoutResolved checks out the resolved version of each dependency in the
+// cache.
+func checkoutResolved(conf *cfg.Config) error {
+	for _, d := range workspaceDependencies(conf) {
+		if d.Pin == "" || d.Path != "" {
+			continue
+		}
+		dd := d.Clone()
+		dd.Reference = d.Pin
+		dd.Pin = ""
+		if err := repo.VcsVersion(dd); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// workspaceDependencies returns the dependencies in every section of a
+// resolved config, including the tools.
+func workspaceDependencies(conf *cfg.Config) cfg.Dependencies {
+	deps := append(append(cfg.Dependencies{}, conf.Imports...), conf.DevImports...)
+	for _, name := range conf.GroupNames() {
+		deps = append(deps, conf.Groups[name]...)
+	}
+	return append(deps, conf.Tools...)
+}
+
+func chdir(dir string) {
+	if err := os.Chdir(dir); err != nil {
+		msg.Die("Unable to change to %s: %s", dir, err)
+	}
+}
@@ -0,0 +1,36 @@
+package action
+
+import (
+	"testing"
+
+	"github.com/Masterminds/glide/cfg"
+)
+
+func TestWorkspaceConflicts(t *testing.T) {
+	projects := []*workspaceProject{
+		{name: "app", resolved: &cfg.Config{
+			Imports: cfg.Dependencies{{Name: "github.com/foo/a", Pin: "a1"}},
+			Groups: map[string]cfg.Dependencies{
+				"integration": {{Name: "github.com/foo/db", Pin: "db1"}},
+			},
+		}},
+		{name: "api", resolved: &cfg.Config{
+			Imports: cfg.Dependencies{{Name: "github.com/foo/a", Pin: "a1"}},
+			Groups: map[string]cfg.Dependencies{
+				"docs": {{Name: "github.com/foo/db", Pin: "db2"}},
+			},
+			Tools: cfg.Dependencies{{Name: "github.com/foo/mock", Pin: "m2"}},
+		}},
+	}
+	projects[0].resolved.Tools = cfg.Dependencies{{Name: "github.com/foo/mock", Pin: "m1"}}
+
+	conflicts := workspaceConflicts(projects)
+	if len(conflicts) != 2 || conflicts[0].name != "github.com/foo/db" || conflicts[1].name != "github.com/foo/mock" {
+		t.Fatalf("Expected github.com/foo/db and github.com/foo/mock to conflict, got %v", conflicts)
+	}
+	for v, p := range map[string]string{"db1": "app", "db2": "api"} {
+		if ps := conflicts[0].versions[v]; len(ps) != 1 || ps[0] != p {
+			t.Errorf("Expected %s to be used by %s, got %v", v, p, ps)
+		}
+	}
+}
@@ -20,6 +20,10 @@ type Config struct {
 	// Name is the name of the package or application.
 	Name string `yaml:"package"`
 
+	// Extends lists base files whose configuration is inherited. See Extend
+	// for how they are found and merged.
+	Extends []string `yaml:"extends,omitempty"`
+
 	// Description is a short description for a package, application, or library.
 	// This description is similar but different to a Go package description as
 	// it is for marketing and presentation purposes rather than technical ones.
@@ -47,6 +51,13 @@ type Config struct {
 	// exclude from scanning for dependencies.
 	Exclude []string `yaml:"excludeDirs,omitempty"`
 
+	// Importers lists, by name, the importers used to read the configuration
+	// of dependencies and the order to consult them in. Configuration from an
+	// earlier importer wins when more than one lists a dependency. When empty
+	// the registered importers are tried in their default order and the first
+	// to find configuration is used.
+	Importers []string `yaml:"importers,omitempty"`
+
 	// Imports contains a list of all non-development imports for a project. For
 	// more detail on how these are captured see the Dependency type.
 	Imports Dependencies `yaml:"import"`
@@ -54,19 +65,51 @@ type Config struct {
 	// DevImports contains the test or other development imports for a project.
 	// See the Dependency type for more details on how this is recorded.
 	DevImports Dependencies `yaml:"testImport,omitempty"`
+
+	// Groups contains named sets of dependencies beyond the imports and test
+	// imports, such as tools or integration test dependencies. Each group is
+	// recorded separately in the lock file and can be installed on its own.
+	Groups map[string]Dependencies `yaml:"groups,omitempty"`
+
+	// Tools contains the main packages of tools, such as code generators and
+	// linters, the project uses. They are resolved and locked like imports and
+	// `glide tools install` builds them into the bin directory.
+	Tools Dependencies `yaml:"tools,omitempty"`
+
+	// Prune holds the rules `glide install --prune` uses to remove files from
+	// the vendor directory. When nil every rule is used.
+	Prune *Prune `yaml:"prune,omitempty"`
+
+	// base is the merged configuration of the files in Extends.
+	base *Config
 }
 
+// The names of the sections holding imports, test imports, and tools. They can
+// be used alongside group names when selecting what to install. The import and
+// testImport names cannot be used as group names. A group named tools is
+// allowed, as it was before the tools section, and is selected along with it.
+const (
+	ImportGroup     = "import"
+	TestImportGroup = "testImport"
+	ToolsGroup      = "tools"
+)
+
 // A transitive representation of a dependency for importing and exporting to yaml.
 type cf struct {
-	Name        string       `yaml:"package"`
-	Description string       `yaml:"description,omitempty"`
-	Home        string       `yaml:"homepage,omitempty"`
-	License     string       `yaml:"license,omitempty"`
-	Owners      Owners       `yaml:"owners,omitempty"`
-	Ignore      []string     `yaml:"ignore,omitempty"`
-	Exclude     []string     `yaml:"excludeDirs,omitempty"`
-	Imports     Dependencies `yaml:"import"`
-	DevImports  Dependencies `yaml:"testImport,omitempty"`
+	Name        string                  `yaml:"package"`
+	Extends     []string                `yaml:"extends,omitempty"`
+	Description string                  `yaml:"description,omitempty"`
+	Home        string                  `yaml:"homepage,omitempty"`
+	License     string                  `yaml:"license,omitempty"`
+	Owners      Owners                  `yaml:"owners,omitempty"`
+	Ignore      []string                `yaml:"ignore,omitempty"`
+	Exclude     []string                `yaml:"excludeDirs,omitempty"`
+	Importers   []string                `yaml:"importers,omitempty"`
+	Imports     Dependencies            `yaml:"import"`
+	DevImports  Dependencies            `yaml:"testImport,omitempty"`
+	Groups      map[string]Dependencies `yaml:"groups,omitempty"`
+	Tools       Dependencies            `yaml:"tools,omitempty"`
+	Prune       *Prune                  `yaml:"prune,omitempty"`
 }
 
 // ConfigFromYaml returns an instance of Config from YAML
@@ -92,14 +135,25 @@ func (c *Config) UnmarshalYAML(unmarshal func(interface{}) error) error {
 		return err
 	}
 	c.Name = newConfig.Name
+	c.Extends = newConfig.Extends
 	c.Description = newConfig.Description
 	c.Home = newConfig.Home
 	c.License = newConfig.License
 	c.Owners = newConfig.Owners
 	c.Ignore = newConfig.Ignore
 	c.Exclude = newConfig.Exclude
+	c.Importers = newConfig.Importers
 	c.Imports = newConfig.Imports
 	c.DevImports = newConfig.DevImports
+	c.Groups = newConfig.Groups
+	c.Tools = newConfig.Tools
+	c.Prune = newConfig.Prune
+
+	for name := range c.Groups {
+		if name == "" || name == ImportGroup || name == TestImportGroup {
+			return fmt.Errorf("%q cannot be used as a group name", name)
+		}
+	}
 
 	// Cleanup the Config object now that we have it.
 	err := c.DeDupe()
@@ -109,14 +163,18 @@ func (c *Config) UnmarshalYAML(unmarshal func(interface{}) error) error {
 
 // MarshalYAML is a hook for gopkg.in/yaml.v2 in the marshaling process
 func (c *Config) MarshalYAML() (interface{}, error) {
+	// Settings inherited through extends are not written out.
+	c = c.ownConfig()
 	newConfig := &cf{
 		Name:        c.Name,
+		Extends:     c.Extends,
 		Description: c.Description,
 		Home:        c.Home,
 		License:     c.License,
 		Owners:      c.Owners,
 		Ignore:      c.Ignore,
 		Exclude:     c.Exclude,
+		Importers:   c.Importers,
 	}
 	i, err := c.Imports.Clone().DeDupe()
 	if err != nil {
@@ -131,10 +189,29 @@ func (c *Config) MarshalYAML() (interface{}, error) {
 	newConfig.Imports = i
 	newConfig.DevImports = di
 
+	if len(c.Groups) > 0 {
+		newConfig.Groups = make(map[string]Dependencies, len(c.Groups))
+		for name, g := range c.Groups {
+			gi, err := g.Clone().DeDupe()
+			if err != nil {
+				return newConfig, err
+			}
+			newConfig.Groups[name] = gi
+		}
+	}
+
+	t, err := c.Tools.Clone().DeDupe()
+	if err != nil {
+		return newConfig, err
+	}
+	newConfig.Tools = t
+	newConfig.Prune = c.Prune
+
 	return newConfig, nil
 }
 
-// HasDependency returns true if the given name is listed as an import or dev import.
+// HasDependency returns true if the given name is listed as an import, dev
+// import, tool, or in a group.
 func (c *Config) HasDependency(name string) bool {
 	for _, d := range c.Imports {
 		if d.Name == name {
@@ -146,7 +223,42 @@ func (c *Config) HasDependency(name string) bool {
 			return true
 		}
 	}
-	return false
+	for _, g := range c.Groups {
+		if g.Has(name) {
+			return true
+		}
+	}
+	return c.Tools.Has(name)
+}
+
+// GroupNames returns the names of the dependency groups in sorted order.
+func (c *Config) GroupNames() []string {
+	names := make([]string, 0, len(c.Groups))
+	for name := range c.Groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// GroupDependencies returns the dependencies in the groups and tools that are
+// not listed as imports or dev imports. A dependency in more than one group is
+// returned once, from the first group by name, and tools come last.
+func (c *Config) GroupDependencies() Dependencies {
+	var deps Dependencies
+	add := func(g Dependencies) {
+		for _, d := range g {
+			if c.Imports.Has(d.Name) || c.DevImports.Has(d.Name) || deps.Has(d.Name) {
+				continue
+			}
+			deps = append(deps, d)
+		}
+	}
+	for _, name := range c.GroupNames() {
+		add(c.Groups[name])
+	}
+	add(c.Tools)
+	return deps
 }
 
 // HasIgnore returns true if the given name is listed on the ignore list.
@@ -179,14 +291,25 @@ func (c *Config) HasExclude(ex string) bool {
 func (c *Config) Clone() *Config {
 	n := &Config{}
 	n.Name = c.Name
+	n.Extends = c.Extends
 	n.Description = c.Description
 	n.Home = c.Home
 	n.License = c.License
 	n.Owners = c.Owners.Clone()
 	n.Ignore = c.Ignore
 	n.Exclude = c.Exclude
+	n.Importers = c.Importers
 	n.Imports = c.Imports.Clone()
 	n.DevImports = c.DevImports.Clone()
+	if c.Groups != nil {
+		n.Groups = make(map[string]Dependencies, len(c.Groups))
+		for name, g := range c.Groups {
+			n.Groups[name] = g.Clone()
+		}
+	}
+	n.Tools = c.Tools.Clone()
+	n.Prune = c.Prune.Clone()
+	n.base = c.base
 	return n
 }
 
@@ -215,6 +338,20 @@ func (c *Config) DeDupe() error {
 	if err != nil {
 		return err
 	}
+	for name, g := range c.Groups {
+		g, err = g.DeDupe()
+		if err != nil {
+			return err
+		}
+		c.Groups[name] = g.Remove(c.Name)
+		for _, v := range c.Ignore {
+			c.Groups[name] = c.Groups[name].Remove(v)
+		}
+	}
+	c.Tools, err = c.Tools.DeDupe()
+	if err != nil {
+		return err
+	}
 
 	// If the name on the config object is part of the imports remove it.
 	found := -1
@@ -275,9 +412,12 @@ func (c *Config) AddImport(deps ...*Dependency) error {
 	return nil
 }
 
-// Hash generates a sha256 hash for a given Config
+// Hash generates a sha256 hash for a given Config. For a config that has been
+// extended the hash covers the merged configuration.
 func (c *Config) Hash() (string, error) {
-	yml, err := c.Marshal()
+	e := *c
+	e.base = nil
+	yml, err := e.Marshal()
 	if err != nil {
 		return "", err
 	}
@@ -357,6 +497,9 @@ func (d Dependencies) DeDupe() (Dependencies, error) {
 			if dep.Repository != v.Repository || dep.VcsType != v.VcsType {
 				return d, fmt.Errorf("Import %s repeated with different Repository details", dep.Name)
 			}
+			if dep.Path != v.Path {
+				return d, fmt.Errorf("Import %s repeated with different local paths", dep.Name)
+			}
 			if !reflect.DeepEqual(dep.Os, v.Os) || !reflect.DeepEqual(dep.Arch, v.Arch) {
 				return d, fmt.Errorf("Import %s repeated with different OS or Architecture filtering", dep.Name)
 			}
@@ -377,6 +520,29 @@ type Dependency struct {
 	Subpackages []string `yaml:"subpackages,omitempty"`
 	Arch        []string `yaml:"arch,omitempty"`
 	Os          []string `yaml:"os,omitempty"`
+
+	// Path points the dependency at a local directory, such as a working copy
+	// of a library being developed alongside the application. Relative paths
+	// are relative to the glide.yaml file. When set the directory is used in
+	// place of the repository in the cache.
+	Path string `yaml:"path,omitempty"`
+
+	// Digest is the content digest of the exported package tree. It is
+	// carried between the lock file and the installer and never written to
+	// the glide.yaml file.
+	Digest string `yaml:"-"`
+
+	// ImportedFrom is the manifest, such as a dependency's Godeps.json file,
+	// the dependency was read from by an importer. It is empty for
+	// dependencies listed in the project's own glide.yaml file.
+	ImportedFrom string `yaml:"-"`
+
+	// Rule is how the version was chosen, one of the Rule constants, and
+	// RequiredBy lists the packages that asked for the dependency along with
+	// the version each asked for. They are set while resolving dependencies
+	// and recorded in the lock file.
+	Rule       string       `yaml:"-"`
+	RequiredBy Requirements `yaml:"-"`
 }
 
 // A transitive representation of a dependency for importing and exploting to yaml.
@@ -389,6 +555,7 @@ type dep struct {
 	Subpackages []string `yaml:"subpackages,omitempty"`
 	Arch        []string `yaml:"arch,omitempty"`
 	Os          []string `yaml:"os,omitempty"`
+	Path        string   `yaml:"path,omitempty"`
 }
 
 // DependencyFromLock converts a Lock to a Dependency
@@ -401,6 +568,10 @@ func DependencyFromLock(lock *Lock) *Dependency {
 		Subpackages: lock.Subpackages,
 		Arch:        lock.Arch,
 		Os:          lock.Os,
+		Path:        lock.Path,
+		Digest:      lock.Digest,
+		Rule:        lock.Rule,
+		RequiredBy:  lock.RequiredBy.Clone(),
 	}
 }
 
@@ -418,6 +589,7 @@ func (d *Dependency) UnmarshalYAML(unmarshal func(interface{}) error) error {
 	d.Subpackages = newDep.Subpackages
 	d.Arch = newDep.Arch
 	d.Os = newDep.Os
+	d.Path = newDep.Path
 
 	if d.Reference == "" && newDep.Ref != "" {
 		d.Reference = newDep.Ref
@@ -455,6 +627,7 @@ func (d *Dependency) MarshalYAML() (interface{}, error) {
 		Subpackages: d.Subpackages,
 		Arch:        d.Arch,
 		Os:          d.Os,
+		Path:        d.Path,
 	}
 
 	return newDep, nil
@@ -530,14 +703,19 @@ func (d *Dependency) GetRepo(dest string) (vcs.Repo, error) {
 // Clone creates a clone of a Dependency
 func (d *Dependency) Clone() *Dependency {
 	return &Dependency{
-		Name:        d.Name,
-		Reference:   d.Reference,
-		Pin:         d.Pin,
-		Repository:  d.Repository,
-		VcsType:     d.VcsType,
-		Subpackages: d.Subpackages,
-		Arch:        d.Arch,
-		Os:          d.Os,
+		Name:         d.Name,
+		Reference:    d.Reference,
+		Pin:          d.Pin,
+		Repository:   d.Repository,
+		VcsType:      d.VcsType,
+		Subpackages:  d.Subpackages,
+		Arch:         d.Arch,
+		Os:           d.Os,
+		Path:         d.Path,
+		Digest:       d.Digest,
+		ImportedFrom: d.ImportedFrom,
+		Rule:         d.Rule,
+		RequiredBy:   d.RequiredBy.Clone(),
 	}
 }
 
@@ -553,6 +731,22 @@ func (d *Dependency) HasSubpackage(sub string) bool {
 	return false
 }
 
+// ForPlatform returns true if the dependency is used on a platform. A
+// dependency without an os or arch is used on every platform.
+func (d *Dependency) ForPlatform(goos, goarch string) bool {
+	return forPlatform(d.Os, d.Arch, goos, goarch)
+}
+
+func forPlatform(oses, arches []string, goos, goarch string) bool {
+	if len(oses) > 0 && !containsString(oses, goos) {
+		return false
+	}
+	if len(arches) > 0 && !containsString(arches, goarch) {
+		return false
+	}
+	return true
+}
+
 // Owners is a list of owners for a project.
 type Owners []*Owner
 
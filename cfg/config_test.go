@@ -1,6 +1,7 @@
 package cfg
 
 import (
+	"strings"
 	"testing"
 
 	"gopkg.in/yaml.v2"
@@ -176,3 +177,80 @@ func TestOwners(t *testing.T) {
 		t.Error("Unable to parse owners from yaml")
 	}
 }
+
+func TestGroups(t *testing.T) {
+	yml := `package: fake/testing
+import:
+- package: github.com/kylelemons/go-gypsy
+groups:
+  tools:
+  - package: github.com/golang/lint
+  - package: github.com/kylelemons/go-gypsy
+  integration:
+  - package: github.com/stretchr/testify
+  - package: github.com/golang/lint
+`
+	c, err := ConfigFromYaml([]byte(yml))
+	if err != nil {
+		t.Fatalf("Unable to parse groups: %s", err)
+	}
+
+	names := c.GroupNames()
+	if len(names) != 2 || names[0] != "integration" || names[1] != "tools" {
+		t.Errorf("Unexpected group names %v", names)
+	}
+	if !c.HasDependency("github.com/stretchr/testify") {
+		t.Error("Expected a dependency in a group to be found")
+	}
+
+	// Dependencies are listed once and never when they are already imported.
+	deps := c.GroupDependencies()
+	if len(deps) != 2 || deps[0].Name != "github.com/stretchr/testify" || deps[1].Name != "github.com/golang/lint" {
+		t.Errorf("Unexpected group dependencies %v", deps)
+	}
+
+	n := c.Clone()
+	n.Groups["tools"][0].Name = "github.com/golang/vet"
+	if c.Groups["tools"][0].Name != "github.com/golang/lint" {
+		t.Error("Expected cloning to copy the groups")
+	}
+
+	if _, err := ConfigFromYaml([]byte("package: fake/testing\ngroups:\n  import:\n  - package: github.com/golang/lint\n")); err == nil {
+		t.Error("Expected a reserved group name to fail")
+	}
+}
+
+func TestToolsGroup(t *testing.T) {
+	// A group named tools predates the tools section and is kept apart from it.
+	yml := `package: fake/testing
+groups:
+  tools:
+  - package: github.com/golang/lint
+tools:
+- package: github.com/golang/mock
+  subpackages:
+  - mockgen
+`
+	c, err := ConfigFromYaml([]byte(yml))
+	if err != nil {
+		t.Fatalf("Expected a group named tools to be allowed: %s", err)
+	}
+	if len(c.Groups[ToolsGroup]) != 1 || c.Groups[ToolsGroup][0].Name != "github.com/golang/lint" {
+		t.Errorf("Unexpected tools group %v", c.Groups[ToolsGroup])
+	}
+	if len(c.Tools) != 1 || c.Tools[0].Name != "github.com/golang/mock" {
+		t.Errorf("Unexpected tools %v", c.Tools)
+	}
+	deps := c.GroupDependencies()
+	if len(deps) != 2 || deps[0].Name != "github.com/golang/lint" || deps[1].Name != "github.com/golang/mock" {
+		t.Errorf("Unexpected group dependencies %v", deps)
+	}
+
+	out, err := c.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(out), "groups:\n  tools:\n  - package: github.com/golang/lint\n") || !strings.Contains(string(out), "\ntools:\n- package: github.com/golang/mock\n") {
+		t.Errorf("Expected the group and the tools section to be written apart, got:\n%s", out)
+	}
+}
@@ -0,0 +1,553 @@
+package cfg
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/Masterminds/glide/msg"
+	"gopkg.in/yaml.v2"
+)
+
+// errNotEditable is returned when a file cannot be updated in place, such as
+// when it uses a style of YAML that is not understood.
+var errNotEditable = errors.New("the file cannot be updated in place")
+
+// UpdateFile writes the config to an existing Glide YAML file, changing only
+// the parts of the file that differ from the config.
+//
+// Comments, the order of keys, and the formatting of unchanged entries are
+// kept. Changed entries are updated field by field, removed entries are
+// removed along with the comments directly above them, and new entries are
+// added after the entry that comes before them in the config, above any blank
+// lines and comments that end a list or mapping. When the file does not
+// exist, or cannot be updated in place, the whole file is written as with
+// WriteFile. A warning is shown in the latter case as the comments and
+// formatting of the file are lost.
+func (c *Config) UpdateFile(glidepath string) error {
+	orig, err := ioutil.ReadFile(glidepath)
+	if os.IsNotExist(err) {
+		return c.WriteFile(glidepath)
+	} else if err != nil {
+		return err
+	}
+
+	o, err := c.updateYaml(orig)
+	if err == errNotEditable {
+		msg.Warn("Unable to update %s in place. Writing the whole file, its comments and formatting are not kept.", glidepath)
+		return c.WriteFile(glidepath)
+	} else if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(glidepath, o, 0666)
+}
+
+// updateYaml applies the differences between the config in orig and c to the
+// text of orig.
+func (c *Config) updateYaml(orig []byte) ([]byte, error) {
+	want, err := c.Marshal()
+	if err != nil {
+		return nil, err
+	}
+	old, err := ConfigFromYaml(orig)
+	if err != nil {
+		return nil, errNotEditable
+	}
+	have, err := old.Marshal()
+	if err != nil {
+		return nil, err
+	}
+	if bytes.Equal(have, want) {
+		return orig, nil
+	}
+	if bytes.Contains(orig, []byte("\r")) || bytes.Contains(orig, []byte("\t")) {
+		return nil, errNotEditable
+	}
+
+	var om, nm yaml.MapSlice
+	if err := yaml.Unmarshal(have, &om); err != nil {
+		return nil, err
+	}
+	if err := yaml.Unmarshal(want, &nm); err != nil {
+		return nil, err
+	}
+
+	text := strings.TrimSuffix(string(orig), "\n")
+	lines, err := editMapping(strings.Split(text, "\n"), 0, om, nm)
+	if err != nil {
+		return nil, err
+	}
+	o := []byte(strings.Join(lines, "\n") + "\n")
+
+	// The edits are only used when they result in the same config as writing
+	// the whole file would.
+	check, err := ConfigFromYaml(o)
+	if err != nil {
+		return nil, errNotEditable
+	}
+	got, err := check.Marshal()
+	if err != nil || !bytes.Equal(got, want) {
+		return nil, errNotEditable
+	}
+	return o, nil
+}
+
+// block is an entry in a mapping or an item in a sequence along with the
+// comment lines directly above it.
+type block struct {
+	key  string
+	lead []string
+	body []string
+}
+
+// indentOf returns the indentation of a line and whether the line has content
+// other than a comment.
+func indentOf(line string) (int, bool) {
+	t := strings.TrimLeft(line, " ")
+	return len(line) - len(t), t != "" && !strings.HasPrefix(t, "#")
+}
+
+func isComment(line string) bool {
+	return strings.HasPrefix(strings.TrimLeft(line, " "), "#")
+}
+
+func isItem(line string, indent int) bool {
+	t := line[indent:]
+	return t == "-" || strings.HasPrefix(t, "- ")
+}
+
+// splitBlocks splits lines into the blocks starting at the given indent. The
+// lines before the first block, and the blank and comment lines after the
+// last one, are returned separately.
+func splitBlocks(lines []string, indent int, start func(string) (string, bool)) ([]string, []*block, []string, error) {
+	var head []string
+	var blocks []*block
+	for _, line := range lines {
+		in, content := indentOf(line)
+		if !content || in > indent || (in == indent && blocks != nil && !isBlockStart(line, indent, start)) {
+			if blocks == nil {
+				if content {
+					return nil, nil, nil, errNotEditable
+				}
+				head = append(head, line)
+			} else {
+				b := blocks[len(blocks)-1]
+				b.body = append(b.body, line)
+			}
+			continue
+		}
+		if in < indent {
+			return nil, nil, nil, errNotEditable
+		}
+		key, ok := start(line[indent:])
+		if !ok {
+			return nil, nil, nil, errNotEditable
+		}
+
+		// The comments directly above the block belong to it.
+		b := &block{key: key}
+		if len(blocks) > 0 {
+			prev := blocks[len(blocks)-1]
+			i := len(prev.body)
+			for i > 1 && isComment(prev.body[i-1]) {
+				i--
+			}
+			b.lead = append(b.lead, prev.body[i:]...)
+			prev.body = prev.body[:i]
+		} else {
+			i := len(head)
+			for i > 0 && isComment(head[i-1]) {
+				i--
+			}
+			b.lead = append(b.lead, head[i:]...)
+			head = head[:i]
+		}
+		b.body = append(b.body, line)
+		blocks = append(blocks, b)
+	}
+
+	// The lines after the last block separate it from what follows, so blocks
+	// added at the end go above them.
+	var tail []string
+	if len(blocks) > 0 {
+		last := blocks[len(blocks)-1]
+		tail = trailing(last.body[1:])
+		last.body = last.body[:len(last.body)-len(tail)]
+	}
+	return head, blocks, tail, nil
+}
+
+func isBlockStart(line string, indent int, start func(string) (string, bool)) bool {
+	_, ok := start(line[indent:])
+	return ok
+}
+
+// mappingKey is the start of an entry in a mapping. Sequence items at the
+// same indent are part of the value of the entry above them.
+func mappingKey(s string) (string, bool) {
+	if s == "-" || strings.HasPrefix(s, "- ") {
+		return "", false
+	}
+	k, _, ok := splitKey(s)
+	return k, ok
+}
+
+func sequenceItem(s string) (string, bool) {
+	return "", s == "-" || strings.HasPrefix(s, "- ")
+}
+
+// editMapping updates the lines of a block style mapping whose keys are at the
+// given indent from the values in om to those in nm.
+func editMapping(lines []string, indent int, om, nm yaml.MapSlice) ([]string, error) {
+	head, blocks, tail, err := splitBlocks(lines, indent, mappingKey)
+	if err != nil {
+		return nil, err
+	}
+
+	found := map[string]bool{}
+	out := []*block{}
+	for _, b := range blocks {
+		if found[b.key] {
+			return nil, errNotEditable
+		}
+		found[b.key] = true
+		ov, inOld := mapValue(om, b.key)
+		nv, inNew := mapValue(nm, b.key)
+		switch {
+		case !inOld && !inNew:
+			// Settings that are not part of the config are left alone.
+			out = append(out, b)
+		case !inNew:
+			// Removed
+		case inOld && reflect.DeepEqual(ov, nv):
+			out = append(out, b)
+		default:
+			body, err := editValue(b.body, indent, b.key, ov, nv)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, &block{key: b.key, lead: b.lead, body: body})
+		}
+	}
+	for _, item := range om {
+		if !found[keyString(item.Key)] {
+			return nil, errNotEditable
+		}
+	}
+
+	// New keys are added after the key that comes before them in nm.
+	for i, item := range nm {
+		k := keyString(item.Key)
+		if found[k] {
+			continue
+		}
+		at := 0
+		for j := i - 1; j >= 0; j-- {
+			if p := indexBlock(out, keyString(nm[j].Key)); p >= 0 {
+				at = p + 1
+				break
+			}
+		}
+		body, err := render(yaml.MapSlice{item}, indent)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out[:at], append([]*block{{key: k, body: body}}, out[at:]...)...)
+		found[k] = true
+	}
+
+	return joinBlocks(head, out, tail), nil
+}
+
+// editValue updates the lines of a mapping entry.
+func editValue(body []string, indent int, key string, ov, nv interface{}) ([]string, error) {
+	first := body[0]
+	_, vcol, _ := splitKey(first[indent:])
+	inline := strings.TrimSpace(first[indent+vcol:])
+	if inline == "" || strings.HasPrefix(inline, "#") {
+		// Block style values can be edited in place.
+		rest := body[1:]
+		switch n := nv.(type) {
+		case yaml.MapSlice:
+			if o, ok := ov.(yaml.MapSlice); ok {
+				if in, ok := firstIndent(rest); ok && in > indent {
+					lines, err := editMapping(rest, in, o, n)
+					if err != nil {
+						return nil, err
+					}
+					return append([]string{first}, lines...), nil
+				}
+			}
+		case []interface{}:
+			if o, ok := ov.([]interface{}); ok && len(n) > 0 {
+				if in, ok := firstIndent(rest); ok && in >= indent && isItem(firstContent(rest), in) {
+					lines, err := editSequence(rest, in, o, n)
+					if err != nil {
+						return nil, err
+					}
+					return append([]string{first}, lines...), nil
+				}
+			}
+		}
+	} else if len(body) == 1 {
+		// A single line is replaced keeping any comment at the end of it.
+		lines, err := render(yaml.MapSlice{{Key: key, Value: nv}}, indent)
+		if err != nil {
+			return nil, err
+		}
+		if len(lines) == 1 {
+			return []string{lines[0] + lineComment(first[indent+vcol:])}, nil
+		}
+		return lines, nil
+	}
+
+	// Trailing lines that are not part of the value, such as blank lines, are
+	// kept.
+	lines, err := render(yaml.MapSlice{{Key: key, Value: nv}}, indent)
+	if err != nil {
+		return nil, err
+	}
+	return append(lines, trailing(body[1:])...), nil
+}
+
+// editSequence updates the lines of a block style sequence whose items start
+// at the given indent from the items in olds to those in news.
+func editSequence(lines []string, indent int, olds, news []interface{}) ([]string, error) {
+	head, blocks, tail, err := splitBlocks(lines, indent, sequenceItem)
+	if err != nil {
+		return nil, err
+	}
+	if len(blocks) != len(olds) {
+		return nil, errNotEditable
+	}
+
+	// Items are matched by the package they are for, or by value for lists
+	// of strings.
+	next := map[string]interface{}{}
+	for _, v := range news {
+		next[itemKey(v)] = v
+	}
+	found := map[string]bool{}
+	out := []*block{}
+	for i, b := range blocks {
+		k := itemKey(olds[i])
+		if found[k] {
+			return nil, errNotEditable
+		}
+		found[k] = true
+		b.key = k
+		nv, ok := next[k]
+		switch {
+		case !ok:
+			// Removed
+		case reflect.DeepEqual(olds[i], nv):
+			out = append(out, b)
+		default:
+			body, err := editItem(b.body, indent, olds[i], nv)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, &block{key: k, lead: b.lead, body: body})
+		}
+	}
+
+	for i, v := range news {
+		k := itemKey(v)
+		if found[k] {
+			continue
+		}
+		at := 0
+		for j := i - 1; j >= 0; j-- {
+			if p := indexBlock(out, itemKey(news[j])); p >= 0 {
+				at = p + 1
+				break
+			}
+		}
+		body, err := render([]interface{}{v}, indent)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out[:at], append([]*block{{key: k, body: body}}, out[at:]...)...)
+		found[k] = true
+	}
+
+	if len(out) == 0 {
+		return nil, errNotEditable
+	}
+	return joinBlocks(head, out, tail), nil
+}
+
+// editItem updates the lines of a sequence item.
+func editItem(body []string, indent int, ov, nv interface{}) ([]string, error) {
+	o, ok1 := ov.(yaml.MapSlice)
+	n, ok2 := nv.(yaml.MapSlice)
+	if ok1 && ok2 {
+		first := body[0]
+		rest := strings.TrimLeft(first[indent+1:], " ")
+		in := len(first) - len(rest)
+		if _, ok := mappingKey(rest); ok {
+			// The mapping starts on the line of the dash. The dash is replaced
+			// by a space while the mapping is edited and then put back.
+			lines := append([]string{first[:indent] + " " + first[indent+1:]}, body[1:]...)
+			lines, err := editMapping(lines, in, o, n)
+			if err != nil {
+				return nil, err
+			}
+			for i, l := range lines {
+				if li, content := indentOf(l); content {
+					if li != in {
+						return nil, errNotEditable
+					}
+					lines[i] = l[:indent] + "-" + l[indent+1:]
+					return lines, nil
+				}
+			}
+			return nil, errNotEditable
+		}
+	}
+
+	lines, err := render([]interface{}{nv}, indent)
+	if err != nil {
+		return nil, err
+	}
+	return append(lines, trailing(body[1:])...), nil
+}
+
+func mapValue(m yaml.MapSlice, key string) (interface{}, bool) {
+	for _, item := range m {
+		if keyString(item.Key) == key {
+			return item.Value, true
+		}
+	}
+	return nil, false
+}
+
+func keyString(k interface{}) string {
+	return fmt.Sprint(k)
+}
+
+func itemKey(v interface{}) string {
+	if m, ok := v.(yaml.MapSlice); ok {
+		if p, ok := mapValue(m, "package"); ok {
+			return "package:" + keyString(p)
+		}
+	}
+	b, _ := yaml.Marshal(v)
+	return string(b)
+}
+
+func indexBlock(blocks []*block, key string) int {
+	for i, b := range blocks {
+		if b.key == key {
+			return i
+		}
+	}
+	return -1
+}
+
+func joinBlocks(head []string, blocks []*block, tail []string) []string {
+	out := append([]string{}, head...)
+	for _, b := range blocks {
+		out = append(out, b.lead...)
+		out = append(out, b.body...)
+	}
+	return append(out, tail...)
+}
+
+// render marshals a value to lines with the given indent.
+func render(v interface{}, indent int) ([]string, error) {
+	b, err := yaml.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	pad := strings.Repeat(" ", indent)
+	lines := strings.Split(strings.TrimSuffix(string(b), "\n"), "\n")
+	for i, l := range lines {
+		lines[i] = pad + l
+	}
+	return lines, nil
+}
+
+// firstIndent returns the indent of the first line with content.
+func firstIndent(lines []string) (int, bool) {
+	for _, l := range lines {
+		if in, content := indentOf(l); content {
+			return in, true
+		}
+	}
+	return 0, false
+}
+
+func firstContent(lines []string) string {
+	for _, l := range lines {
+		if _, content := indentOf(l); content {
+			return l
+		}
+	}
+	return ""
+}
+
+// trailing returns the blank and comment lines at the end of lines.
+func trailing(lines []string) []string {
+	i := len(lines)
+	for i > 0 {
+		if _, content := indentOf(lines[i-1]); content {
+			break
+		}
+		i--
+	}
+	return lines[i:]
+}
+
+// lineComment returns the comment at the end of a single line value,
+// including the space before it.
+func lineComment(v string) string {
+	var quote byte
+	for i := 0; i < len(v); i++ {
+		switch {
+		case quote != 0:
+			if v[i] == quote {
+				quote = 0
+			}
+		case v[i] == '"' || v[i] == '\'':
+			quote = v[i]
+		case v[i] == '#' && i > 0 && v[i-1] == ' ':
+			j := i
+			for j > 0 && v[j-1] == ' ' {
+				j--
+			}
+			return v[j:]
+		}
+	}
+	return ""
+}
+
+// splitKey splits "key: value" returning the key and the offset of the value.
+func splitKey(s string) (string, int, bool) {
+	if s == "" {
+		return "", 0, false
+	}
+	if s[0] == '"' || s[0] == '\'' {
+		end := strings.IndexByte(s[1:], s[0])
+		if end < 0 || !strings.HasPrefix(s[end+2:], ":") {
+			return "", 0, false
+		}
+		return s[1 : end+1], end + 3, true
+	}
+	if s[0] == '[' || s[0] == '{' || s[0] == '#' {
+		return "", 0, false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] == ':' && (i+1 == len(s) || s[i+1] == ' ') {
+			return s[:i], i + 1, true
+		}
+		if s[i] == '#' && i > 0 && s[i-1] == ' ' {
+			return "", 0, false
+		}
+	}
+	return "", 0, false
+}
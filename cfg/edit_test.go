@@ -0,0 +1,169 @@
+package cfg
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Masterminds/glide/msg"
+)
+
+const editYaml = `# The example application.
+package: github.com/example/app
+import:
+# Pinned until the API change is handled.
+- package: github.com/Masterminds/vcs
+  version: 1.0.0 # see issue 12
+- package: github.com/Masterminds/semver
+  version: ^1.0.0
+
+- package: github.com/codegangsta/cli
+ignore:
+- appengine
+`
+
+func TestUpdateYaml(t *testing.T) {
+	c, err := ConfigFromYaml([]byte(editYaml))
+	if err != nil {
+		t.Fatalf("Unable to parse config: %s", err)
+	}
+
+	c.Imports.Get("github.com/Masterminds/vcs").Reference = "^1.2.0"
+	c.Imports = c.Imports.Remove("github.com/Masterminds/semver")
+	c.Imports = append(c.Imports, &Dependency{Name: "github.com/kylelemons/go-gypsy", Reference: "^1.0.0"})
+	c.DevImports = append(c.DevImports, &Dependency{Name: "github.com/arschles/assert"})
+
+	o, err := c.updateYaml([]byte(editYaml))
+	if err != nil {
+		t.Fatalf("Unable to update config: %s", err)
+	}
+
+	expected := `# The example application.
+package: github.com/example/app
+import:
+# Pinned until the API change is handled.
+- package: github.com/Masterminds/vcs
+  version: ^1.2.0 # see issue 12
+- package: github.com/codegangsta/cli
+- package: github.com/kylelemons/go-gypsy
+  version: ^1.0.0
+testImport:
+- package: github.com/arschles/assert
+ignore:
+- appengine
+`
+	if string(o) != expected {
+		t.Errorf("Unexpected update. Expected:\n%s\nGot:\n%s", expected, o)
+	}
+
+	// Files that cannot be edited in place are rewritten.
+	flow := "{package: github.com/example/app, import: [{package: github.com/Masterminds/vcs}]}\n"
+	c, err = ConfigFromYaml([]byte(flow))
+	if err != nil {
+		t.Fatalf("Unable to parse config: %s", err)
+	}
+	c.Imports = append(c.Imports, &Dependency{Name: "github.com/Masterminds/semver"})
+	if _, err := c.updateYaml([]byte(flow)); err != errNotEditable {
+		t.Errorf("Expected a flow style file to not be editable, got %v", err)
+	}
+}
+
+func TestUpdateFileNotEditable(t *testing.T) {
+	dir, err := ioutil.TempDir("", "glide-edit")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	p := filepath.Join(dir, "glide.yaml")
+	flow := "{package: github.com/example/app, import: [{package: github.com/Masterminds/vcs}]}\n"
+	if err := ioutil.WriteFile(p, []byte(flow), 0644); err != nil {
+		t.Fatal(err)
+	}
+	c, err := ConfigFromYaml([]byte(flow))
+	if err != nil {
+		t.Fatalf("Unable to parse config: %s", err)
+	}
+	c.Imports = append(c.Imports, &Dependency{Name: "github.com/Masterminds/semver"})
+
+	var buf bytes.Buffer
+	o := msg.Default.Stderr
+	msg.Default.Stderr = &buf
+	defer func() { msg.Default.Stderr = o }()
+
+	if err := c.UpdateFile(p); err != nil {
+		t.Fatalf("Unable to update config: %s", err)
+	}
+	if !strings.Contains(buf.String(), "Unable to update "+p+" in place") {
+		t.Errorf("Expected a warning the file was rewritten, got %q", buf.String())
+	}
+	yml, err := ioutil.ReadFile(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := c.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(yml) != string(want) {
+		t.Errorf("Expected the whole file to be written. Expected:\n%s\nGot:\n%s", want, yml)
+	}
+}
+
+func TestUpdateYamlTrailingLines(t *testing.T) {
+	tests := []struct {
+		name, yml, expected string
+	}{
+		{
+			name: "blank line and comment before the next section",
+			yml: `package: github.com/example/app
+import:
+- package: github.com/Masterminds/vcs
+
+# test deps below
+testImport:
+- package: github.com/arschles/assert
+`,
+			expected: `package: github.com/example/app
+import:
+- package: github.com/Masterminds/vcs
+- package: github.com/Masterminds/semver
+
+# test deps below
+testImport:
+- package: github.com/arschles/assert
+`,
+		},
+		{
+			name: "trailing comment",
+			yml: `package: github.com/example/app
+import:
+- package: github.com/Masterminds/vcs
+# trailing comment
+`,
+			expected: `package: github.com/example/app
+import:
+- package: github.com/Masterminds/vcs
+- package: github.com/Masterminds/semver
+# trailing comment
+`,
+		},
+	}
+	for _, tt := range tests {
+		c, err := ConfigFromYaml([]byte(tt.yml))
+		if err != nil {
+			t.Fatalf("Unable to parse config: %s", err)
+		}
+		c.Imports = append(c.Imports, &Dependency{Name: "github.com/Masterminds/semver"})
+		o, err := c.updateYaml([]byte(tt.yml))
+		if err != nil {
+			t.Fatalf("Unable to update config with a %s: %s", tt.name, err)
+		}
+		if string(o) != tt.expected {
+			t.Errorf("Unexpected update with a %s. Expected:\n%s\nGot:\n%s", tt.name, tt.expected, o)
+		}
+	}
+}
@@ -0,0 +1,236 @@
+package cfg
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Extend merges the base files listed in Extends into the config.
+//
+// Relative paths are resolved against dir, the directory holding the file the
+// config was read from. When no file exists there they are resolved against
+// the Glide home directory. Base files may extend other files.
+//
+// Bases are applied in order, with later bases taking precedence over earlier
+// ones, and the config itself takes precedence over all of them:
+//  - The package name and extends list are never inherited.
+//  - Scalar settings, owners, importers, and prune rules are inherited when
+//    not set.
+//  - Ignore and excludeDirs lists are combined.
+//  - Dependencies are combined. When a package is listed by more than one file
+//    the entry from the file with the highest precedence is used as is.
+//
+// Marshaling a config that has been extended only writes the settings that
+// differ from its bases. The hash covers the merged config.
+func (c *Config) Extend(dir, home string) error {
+	return c.extend(dir, home, map[string]bool{})
+}
+
+func (c *Config) extend(dir, home string, seen map[string]bool) error {
+	if len(c.Extends) == 0 {
+		return nil
+	}
+
+	var base *Config
+	for _, e := range c.Extends {
+		p, err := ExtendsPath(e, dir, home)
+		if err != nil {
+			return err
+		}
+		if seen[p] {
+			return fmt.Errorf("%s extends itself", p)
+		}
+		seen[p] = true
+
+		yml, err := ioutil.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		b, err := ConfigFromYaml(yml)
+		if err != nil {
+			return fmt.Errorf("Failed to parse %s: %s", p, err)
+		}
+		if err := b.extend(filepath.Dir(p), home, seen); err != nil {
+			return err
+		}
+		delete(seen, p)
+
+		// The base's own bases are already merged into it.
+		b.base = nil
+		if base == nil {
+			base = b
+		} else {
+			base = mergeConfig(base, b)
+		}
+	}
+
+	m := mergeConfig(base, c)
+	*c = *m
+	c.base = base
+	return c.DeDupe()
+}
+
+// ExtendsPath finds the file for an entry in an extends list.
+func ExtendsPath(e, dir, home string) (string, error) {
+	e = filepath.FromSlash(e)
+	if filepath.IsAbs(e) {
+		if _, err := os.Stat(e); err != nil {
+			return "", fmt.Errorf("Unable to find the extended file %s", e)
+		}
+		return e, nil
+	}
+	for _, d := range []string{dir, home} {
+		p := filepath.Join(d, e)
+		if fi, err := os.Stat(p); err == nil && !fi.IsDir() {
+			return filepath.Abs(p)
+		}
+	}
+	return "", fmt.Errorf("Unable to find the extended file %s in %s or %s", e, dir, home)
+}
+
+// mergeConfig returns a new config with over applied on top of base.
+func mergeConfig(base, over *Config) *Config {
+	n := over.Clone()
+	if n.Description == "" {
+		n.Description = base.Description
+	}
+	if n.Home == "" {
+		n.Home = base.Home
+	}
+	if n.License == "" {
+		n.License = base.License
+	}
+	if len(n.Owners) == 0 {
+		n.Owners = base.Owners.Clone()
+	}
+	if len(n.Importers) == 0 {
+		n.Importers = base.Importers
+	}
+	if n.Prune == nil {
+		n.Prune = base.Prune.Clone()
+	}
+	n.Ignore = mergeStrings(base.Ignore, over.Ignore)
+	n.Exclude = mergeStrings(base.Exclude, over.Exclude)
+	n.Imports = mergeDependencies(base.Imports, over.Imports)
+	n.DevImports = mergeDependencies(base.DevImports, over.DevImports)
+	n.Tools = mergeDependencies(base.Tools, over.Tools)
+	for name, g := range base.Groups {
+		if n.Groups == nil {
+			n.Groups = map[string]Dependencies{}
+		}
+		n.Groups[name] = mergeDependencies(g, over.Groups[name])
+	}
+	return n
+}
+
+func mergeStrings(base, over []string) []string {
+	if len(base) == 0 {
+		return over
+	}
+	n := append([]string{}, base...)
+	for _, s := range over {
+		if !containsString(n, s) {
+			n = append(n, s)
+		}
+	}
+	return n
+}
+
+func mergeDependencies(base, over Dependencies) Dependencies {
+	n := base.Clone()
+	for _, d := range over {
+		found := false
+		for i, b := range n {
+			if b.Name == d.Name {
+				n[i] = d.Clone()
+				found = true
+			}
+		}
+		if !found {
+			n = append(n, d.Clone())
+		}
+	}
+	return n
+}
+
+// ownConfig returns the parts of an extended config that are not inherited
+// from its bases.
+func (c *Config) ownConfig() *Config {
+	if c.base == nil {
+		return c
+	}
+	b := c.base
+	n := c.Clone()
+	n.base = nil
+	if n.Description == b.Description {
+		n.Description = ""
+	}
+	if n.Home == b.Home {
+		n.Home = ""
+	}
+	if n.License == b.License {
+		n.License = ""
+	}
+	if reflect.DeepEqual(n.Owners, b.Owners) {
+		n.Owners = nil
+	}
+	if reflect.DeepEqual(n.Importers, b.Importers) {
+		n.Importers = nil
+	}
+	if reflect.DeepEqual(n.Prune, b.Prune) {
+		n.Prune = nil
+	}
+	n.Ignore = subtractStrings(n.Ignore, b.Ignore)
+	n.Exclude = subtractStrings(n.Exclude, b.Exclude)
+	n.Imports = subtractDependencies(n.Imports, b.Imports)
+	n.DevImports = subtractDependencies(n.DevImports, b.DevImports)
+	n.Tools = subtractDependencies(n.Tools, b.Tools)
+	for name, g := range n.Groups {
+		n.Groups[name] = subtractDependencies(g, b.Groups[name])
+		if len(n.Groups[name]) == 0 && len(b.Groups[name]) > 0 {
+			delete(n.Groups, name)
+		}
+	}
+	return n
+}
+
+func subtractStrings(s, base []string) []string {
+	var n []string
+	for _, v := range s {
+		if !containsString(base, v) {
+			n = append(n, v)
+		}
+	}
+	return n
+}
+
+// subtractDependencies removes the dependencies that are the same as in base.
+// Only the settings written to a glide.yaml file are compared.
+func subtractDependencies(deps, base Dependencies) Dependencies {
+	n := make(Dependencies, 0, len(deps))
+	for _, d := range deps {
+		if b := base.Get(d.Name); b != nil {
+			by, err1 := yaml.Marshal(b)
+			dy, err2 := yaml.Marshal(d)
+			if err1 == nil && err2 == nil && string(by) == string(dy) {
+				continue
+			}
+		}
+		n = append(n, d)
+	}
+	return n
+}
+
+func containsString(s []string, v string) bool {
+	for _, x := range s {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
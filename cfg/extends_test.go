@@ -0,0 +1,107 @@
+package cfg
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const baseYaml = `license: MIT
+ignore:
+- appengine
+import:
+- package: github.com/Masterminds/semver
+  version: ^1.0.0
+- package: github.com/Masterminds/vcs
+  version: ^1.0.0
+prune:
+  goTests: true
+`
+
+const childYaml = `package: github.com/example/app
+extends:
+- base.yaml
+ignore:
+- context
+import:
+- package: github.com/Masterminds/vcs
+  version: ^1.2.0
+- package: github.com/kylelemons/go-gypsy
+`
+
+func TestExtend(t *testing.T) {
+	dir, err := ioutil.TempDir("", "glide-extends")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	home := filepath.Join(dir, "home")
+
+	if err := ioutil.WriteFile(filepath.Join(dir, "base.yaml"), []byte(baseYaml), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := ConfigFromYaml([]byte(childYaml))
+	if err != nil {
+		t.Fatalf("Unable to parse config: %s", err)
+	}
+	own, err := c.Hash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Extend(dir, home); err != nil {
+		t.Fatalf("Unable to extend config: %s", err)
+	}
+
+	if c.Name != "github.com/example/app" || c.License != "MIT" {
+		t.Errorf("Unexpected name %q or license %q", c.Name, c.License)
+	}
+	if len(c.Ignore) != 2 || c.Ignore[0] != "appengine" || c.Ignore[1] != "context" {
+		t.Errorf("Expected the ignore lists to be combined, got %v", c.Ignore)
+	}
+	if len(c.Imports) != 3 {
+		t.Fatalf("Expected 3 imports, got %d", len(c.Imports))
+	}
+	if d := c.Imports.Get("github.com/Masterminds/vcs"); d == nil || d.Reference != "^1.2.0" {
+		t.Error("Expected the config to take precedence over its base")
+	}
+	if r := c.PruneRules("github.com/Masterminds/vcs"); r != (PruneRules{GoTests: true}) {
+		t.Errorf("Expected the prune rules to be inherited, got %+v", r)
+	}
+
+	merged, err := c.Hash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if merged == own {
+		t.Error("Expected the hash to cover the extended files")
+	}
+
+	// Only the settings not inherited from the base are written.
+	out, err := c.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	o := string(out)
+	if strings.Contains(o, "appengine") || strings.Contains(o, "semver") || strings.Contains(o, "license") || strings.Contains(o, "prune") {
+		t.Errorf("Expected inherited settings to be left out, got:\n%s", o)
+	}
+	if !strings.Contains(o, "base.yaml") || !strings.Contains(o, "^1.2.0") || !strings.Contains(o, "go-gypsy") {
+		t.Errorf("Expected the config's own settings to be kept, got:\n%s", o)
+	}
+
+	// A base that extends the file extending it is a cycle.
+	cycle := "extends:\n- base.yaml\n"
+	if err := ioutil.WriteFile(filepath.Join(dir, "base.yaml"), []byte(cycle), 0644); err != nil {
+		t.Fatal(err)
+	}
+	c, err = ConfigFromYaml([]byte(childYaml))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Extend(dir, home); err == nil {
+		t.Error("Expected a config extending itself to fail")
+	}
+}
@@ -13,10 +13,12 @@ import (
 
 // Lockfile represents a glide.lock file.
 type Lockfile struct {
-	Hash       string    `yaml:"hash"`
-	Updated    time.Time `yaml:"updated"`
-	Imports    Locks     `yaml:"imports"`
-	DevImports Locks     `yaml:"testImports"`
+	Hash       string           `yaml:"hash"`
+	Updated    time.Time        `yaml:"updated"`
+	Imports    Locks            `yaml:"imports"`
+	DevImports Locks            `yaml:"testImports"`
+	Groups     map[string]Locks `yaml:"groups,omitempty"`
+	Tools      Locks            `yaml:"tools,omitempty"`
 }
 
 // LockfileFromYaml returns an instance of Lockfile from YAML
@@ -65,6 +67,39 @@ func (lf *Lockfile) MarshalYAML() (interface{}, error) {
 	for _, imp := range lf.DevImports {
 		sort.Strings(imp.Subpackages)
 	}
+
+	// Elements in a group that are already imported or test imported are
+	// only recorded once.
+	for name, g := range lf.Groups {
+		var ng Locks
+		for _, imp := range g {
+			found = false
+			for _, l := range append(lf.Imports, lf.DevImports...) {
+				if l.Name == imp.Name {
+					found = true
+					if l.Version != imp.Version {
+						return lf, fmt.Errorf("Generating lock YAML produced conflicting versions of %s. group %s (%s), import (%s)", imp.Name, name, imp.Version, l.Version)
+					}
+				}
+			}
+			if !found {
+				sort.Strings(imp.Subpackages)
+				ng = append(ng, imp)
+			}
+		}
+		lf.Groups[name] = ng
+	}
+
+	// Tools are all kept, even when imported, as they name the packages to
+	// build. The versions must still agree.
+	for _, imp := range lf.Tools {
+		for _, l := range append(lf.Imports, lf.DevImports...) {
+			if l.Name == imp.Name && l.Version != imp.Version {
+				return lf, fmt.Errorf("Generating lock YAML produced conflicting versions of %s. tools (%s), import (%s)", imp.Name, imp.Version, l.Version)
+			}
+		}
+		sort.Strings(imp.Subpackages)
+	}
 	return lf, nil
 }
 
@@ -87,6 +122,13 @@ func (lf *Lockfile) Clone() *Lockfile {
 	n.Updated = lf.Updated
 	n.Imports = lf.Imports.Clone()
 	n.DevImports = lf.DevImports.Clone()
+	if lf.Groups != nil {
+		n.Groups = make(map[string]Locks, len(lf.Groups))
+		for name, g := range lf.Groups {
+			n.Groups[name] = g.Clone()
+		}
+	}
+	n.Tools = lf.Tools.Clone()
 
 	return n
 }
@@ -98,6 +140,10 @@ func (lf *Lockfile) Fingerprint() ([32]byte, error) {
 	c.Updated = time.Time{} // Set the time to be the nil equivalent
 	sort.Sort(c.Imports)
 	sort.Sort(c.DevImports)
+	for _, g := range c.Groups {
+		sort.Sort(g)
+	}
+	sort.Sort(c.Tools)
 	yml, err := c.Marshal()
 	if err != nil {
 		return [32]byte{}, err
@@ -162,6 +208,13 @@ type Lock struct {
 	Subpackages []string `yaml:"subpackages,omitempty"`
 	Arch        []string `yaml:"arch,omitempty"`
 	Os          []string `yaml:"os,omitempty"`
+	Path        string   `yaml:"path,omitempty"`
+	Digest      string   `yaml:"digest,omitempty"`
+
+	// Rule and RequiredBy record why the dependency is at its version. See
+	// Dependency for details.
+	Rule       string       `yaml:"rule,omitempty"`
+	RequiredBy Requirements `yaml:"requiredBy,omitempty"`
 }
 
 // Clone creates a clone of a Lock.
@@ -174,9 +227,87 @@ func (l *Lock) Clone() *Lock {
 		Subpackages: l.Subpackages,
 		Arch:        l.Arch,
 		Os:          l.Os,
+		Path:        l.Path,
+		Digest:      l.Digest,
+		Rule:        l.Rule,
+		RequiredBy:  l.RequiredBy.Clone(),
 	}
 }
 
+// ForPlatform returns true if the locked dependency is used on a platform. See
+// Dependency.ForPlatform.
+func (l *Lock) ForPlatform(goos, goarch string) bool {
+	return forPlatform(l.Os, l.Arch, goos, goarch)
+}
+
+// The rules by which the version of a dependency is chosen.
+const (
+	// RuleConfig is a version set in the glide.yaml file.
+	RuleConfig = "config"
+
+	// RulePath is a dependency overridden by a local path.
+	RulePath = "path"
+
+	// RuleFirst is the version asked for by the first dependency found that
+	// asked for one.
+	RuleFirst = "first"
+
+	// RuleFits is a version that fits the constraint another package asked
+	// for.
+	RuleFits = "fits"
+
+	// RuleCombined is the combination of the semantic version constraints
+	// asked for by more than one package.
+	RuleCombined = "combined"
+
+	// RuleKept is a version kept even though another package asked for a
+	// version that conflicts with it.
+	RuleKept = "kept"
+
+	// RuleDefault is used when no version was asked for and the default
+	// branch is used.
+	RuleDefault = "default"
+
+	// RuleSolved is a version chosen by the backtracking solver to satisfy
+	// every package that asked for the dependency.
+	RuleSolved = "solved"
+)
+
+// Requirement is a package that asked for a dependency along with the version
+// it asked for, if any.
+type Requirement struct {
+	Package string `yaml:"package"`
+	Version string `yaml:"version,omitempty"`
+}
+
+// Requirements is a list of requirements.
+type Requirements []*Requirement
+
+// Clone creates a clone of a list of requirements.
+func (r Requirements) Clone() Requirements {
+	if r == nil {
+		return nil
+	}
+	n := make(Requirements, len(r))
+	for i, v := range r {
+		n[i] = &Requirement{Package: v.Package, Version: v.Version}
+	}
+	return n
+}
+
+// Add adds a requirement keeping the list sorted by package. When the package
+// is already listed the version it asked for first is kept.
+func (r Requirements) Add(pkg, version string) Requirements {
+	i := sort.Search(len(r), func(i int) bool { return r[i].Package >= pkg })
+	if i < len(r) && r[i].Package == pkg {
+		return r
+	}
+	r = append(r, nil)
+	copy(r[i+1:], r[i:])
+	r[i] = &Requirement{Package: pkg, Version: version}
+	return r
+}
+
 // LockFromDependency converts a Dependency to a Lock
 func LockFromDependency(dep *Dependency) *Lock {
 	return &Lock{
@@ -187,7 +318,36 @@ func LockFromDependency(dep *Dependency) *Lock {
 		Subpackages: dep.Subpackages,
 		Arch:        dep.Arch,
 		Os:          dep.Os,
+		Path:        dep.Path,
+		Digest:      dep.Digest,
+		Rule:        dep.Rule,
+		RequiredBy:  dep.RequiredBy.Clone(),
+	}
+}
+
+// LocksFromGroups converts dependency groups into the groups of a lock file.
+func LocksFromGroups(groups map[string]Dependencies) map[string]Locks {
+	if len(groups) == 0 {
+		return nil
+	}
+	lg := make(map[string]Locks, len(groups))
+	for name, g := range groups {
+		lg[name] = LocksFromDependencies(g)
+	}
+	return lg
+}
+
+// LocksFromDependencies converts dependencies into a sorted list of locks.
+func LocksFromDependencies(deps Dependencies) Locks {
+	if len(deps) == 0 {
+		return nil
+	}
+	l := make(Locks, len(deps))
+	for i, d := range deps {
+		l[i] = LockFromDependency(d)
 	}
+	sort.Sort(l)
+	return l
 }
 
 // NewLockfile is used to create an instance of Lockfile.
@@ -110,3 +110,62 @@ func TestSortSubpackages(t *testing.T) {
 		t.Errorf("Expected %q\n to contain\n%q", string(out), expectSubpkgYaml)
 	}
 }
+
+func TestLockGroups(t *testing.T) {
+	lf := &Lockfile{
+		Imports: Locks{{Name: "github.com/foo/bar", Version: "abc"}},
+		Groups: map[string]Locks{
+			"tools": {
+				{Name: "github.com/foo/bar", Version: "abc"},
+				{Name: "github.com/foo/lint", Version: "def"},
+			},
+		},
+	}
+	out, err := lf.Marshal()
+	if err != nil {
+		t.Fatalf("Unable to marshal a lock with groups: %s", err)
+	}
+	if !strings.Contains(string(out), "groups:\n  tools:\n  - name: github.com/foo/lint\n") {
+		t.Errorf("Expected imports to be removed from groups, got:\n%s", out)
+	}
+
+	lf.Groups["tools"] = Locks{{Name: "github.com/foo/bar", Version: "123"}}
+	if _, err := lf.Marshal(); err == nil {
+		t.Error("Expected conflicting versions in a group to fail")
+	}
+}
+
+func TestLockProvenance(t *testing.T) {
+	var reqs Requirements
+	reqs = reqs.Add("github.com/foo/lib", "^1.2.0")
+	reqs = reqs.Add("github.com/example/app", "~1.2.3")
+	reqs = reqs.Add("github.com/foo/lib", "^2.0.0")
+	if len(reqs) != 2 || reqs[0].Package != "github.com/example/app" || reqs[1].Version != "^1.2.0" {
+		t.Errorf("Unexpected requirements %v", reqs)
+	}
+
+	d := &Dependency{Name: "github.com/foo/bar", Pin: "abc", Rule: RuleCombined, RequiredBy: reqs}
+	lf := &Lockfile{Imports: Locks{LockFromDependency(d)}}
+	out, err := lf.Marshal()
+	if err != nil {
+		t.Fatalf("Unable to marshal a lock with provenance: %s", err)
+	}
+	expected := `  rule: combined
+  requiredBy:
+  - package: github.com/example/app
+    version: ~1.2.3
+  - package: github.com/foo/lib
+    version: ^1.2.0
+`
+	if !strings.Contains(string(out), expected) {
+		t.Errorf("Expected the provenance to be recorded, got:\n%s", out)
+	}
+
+	l2, err := LockfileFromYaml(out)
+	if err != nil {
+		t.Fatalf("Unable to read a lock with provenance: %s", err)
+	}
+	if l := l2.Imports[0]; l.Rule != RuleCombined || len(l.RequiredBy) != 2 {
+		t.Errorf("Expected the provenance to be read back, got %+v", l)
+	}
+}
@@ -0,0 +1,216 @@
+package cfg
+
+import (
+	"reflect"
+	"sort"
+)
+
+// LockConflict is a dependency both sides of a merge changed to different
+// versions. A version is empty on a side that removed the dependency.
+type LockConflict struct {
+	// Section is the section of the lock file the dependency is in, such as
+	// imports or testImports. For groups it is the name of the group.
+	Section string
+	Group   bool
+
+	Name   string
+	Base   string
+	Ours   string
+	Theirs string
+}
+
+// MergeLockfiles does a three-way merge of the locked dependencies in two lock
+// files that share a common base.
+//
+// Dependencies changed on only one side take that side's change. When both
+// sides lock a dependency to the same version their other details, such as
+// subpackages, are merged. When both sides lock a dependency to different
+// versions, or one side removes a dependency the other changed, it is a
+// conflict and the version from ours is kept in the returned lock file.
+//
+// The hash is left for the caller to set as it depends on the merged
+// glide.yaml file. The updated time is the later of the two.
+func MergeLockfiles(base, ours, theirs *Lockfile) (*Lockfile, []LockConflict) {
+	n := &Lockfile{
+		Hash:    ours.Hash,
+		Updated: ours.Updated,
+	}
+	if theirs.Updated.After(ours.Updated) {
+		n.Updated = theirs.Updated
+	}
+
+	var conflicts []LockConflict
+	var c []LockConflict
+	n.Imports, c = mergeLocks("imports", base.Imports, ours.Imports, theirs.Imports)
+	conflicts = append(conflicts, c...)
+	n.DevImports, c = mergeLocks("testImports", base.DevImports, ours.DevImports, theirs.DevImports)
+	conflicts = append(conflicts, c...)
+
+	names := map[string]bool{}
+	for _, lf := range []*Lockfile{base, ours, theirs} {
+		for name := range lf.Groups {
+			names[name] = true
+		}
+	}
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+	for _, name := range sorted {
+		g, c := mergeLocks(name, base.Groups[name], ours.Groups[name], theirs.Groups[name])
+		for i := range c {
+			c[i].Group = true
+		}
+		conflicts = append(conflicts, c...)
+		if len(g) > 0 {
+			if n.Groups == nil {
+				n.Groups = map[string]Locks{}
+			}
+			n.Groups[name] = g
+		}
+	}
+
+	n.Tools, c = mergeLocks("tools", base.Tools, ours.Tools, theirs.Tools)
+	conflicts = append(conflicts, c...)
+
+	return n, conflicts
+}
+
+func mergeLocks(section string, base, ours, theirs Locks) (Locks, []LockConflict) {
+	find := func(l Locks, name string) *Lock {
+		for _, v := range l {
+			if v.Name == name {
+				return v
+			}
+		}
+		return nil
+	}
+
+	names := map[string]bool{}
+	for _, l := range []Locks{base, ours, theirs} {
+		for _, v := range l {
+			names[v.Name] = true
+		}
+	}
+
+	n := Locks{}
+	var conflicts []LockConflict
+	for name := range names {
+		o, a, b := find(base, name), find(ours, name), find(theirs, name)
+		var l *Lock
+		switch {
+		case sameLock(a, b), sameLock(b, o):
+			l = a
+		case sameLock(a, o):
+			l = b
+		case a != nil && b != nil && a.Version == b.Version:
+			l = mergeLock(o, a, b)
+		default:
+			c := LockConflict{Section: section, Name: name}
+			if o != nil {
+				c.Base = o.Version
+			}
+			if a != nil {
+				c.Ours = a.Version
+				l = a
+			}
+			if b != nil {
+				c.Theirs = b.Version
+				if l == nil {
+					l = b
+				}
+			}
+			conflicts = append(conflicts, c)
+		}
+		if l != nil {
+			n = append(n, l.Clone())
+		}
+	}
+
+	sort.Sort(n)
+	sort.Slice(conflicts, func(i, j int) bool { return conflicts[i].Name < conflicts[j].Name })
+	return n, conflicts
+}
+
+func sameLock(a, b *Lock) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	x, y := a.Clone(), b.Clone()
+	sort.Strings(x.Subpackages)
+	sort.Strings(y.Subpackages)
+	return reflect.DeepEqual(x, y)
+}
+
+// mergeLock merges the details of a dependency both sides locked to the same
+// version.
+func mergeLock(base, ours, theirs *Lock) *Lock {
+	if base == nil {
+		base = &Lock{}
+	}
+	pick := func(o, a, b string) string {
+		if a == o {
+			return b
+		}
+		return a
+	}
+
+	n := ours.Clone()
+	n.Repository = pick(base.Repository, ours.Repository, theirs.Repository)
+	n.VcsType = pick(base.VcsType, ours.VcsType, theirs.VcsType)
+	n.Path = pick(base.Path, ours.Path, theirs.Path)
+	n.Digest = pick(base.Digest, ours.Digest, theirs.Digest)
+	n.Rule = pick(base.Rule, ours.Rule, theirs.Rule)
+	n.Subpackages = mergeSets(base.Subpackages, ours.Subpackages, theirs.Subpackages)
+	n.Arch = mergeSets(base.Arch, ours.Arch, theirs.Arch)
+	n.Os = mergeSets(base.Os, ours.Os, theirs.Os)
+
+	var o, a, b []string
+	for _, r := range base.RequiredBy {
+		o = append(o, r.Package)
+	}
+	for _, r := range ours.RequiredBy {
+		a = append(a, r.Package)
+	}
+	for _, r := range theirs.RequiredBy {
+		b = append(b, r.Package)
+	}
+	n.RequiredBy = nil
+	for _, p := range mergeSets(o, a, b) {
+		r := reqFor(ours.RequiredBy, p)
+		if r == nil {
+			r = reqFor(theirs.RequiredBy, p)
+		}
+		n.RequiredBy = n.RequiredBy.Add(r.Package, r.Version)
+	}
+
+	return n
+}
+
+// mergeSets merges two changed copies of a set of strings. Strings added on
+// either side are kept and strings removed on either side are removed.
+func mergeSets(base, ours, theirs []string) []string {
+	var n []string
+	for _, s := range ours {
+		if containsString(theirs, s) || !containsString(base, s) {
+			n = append(n, s)
+		}
+	}
+	for _, s := range theirs {
+		if !containsString(ours, s) && !containsString(base, s) {
+			n = append(n, s)
+		}
+	}
+	sort.Strings(n)
+	return n
+}
+
+func reqFor(r Requirements, pkg string) *Requirement {
+	for _, v := range r {
+		if v.Package == pkg {
+			return v
+		}
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package cfg
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMergeLockfiles(t *testing.T) {
+	base := &Lockfile{
+		Hash: "base",
+		Imports: Locks{
+			{Name: "github.com/foo/a", Version: "a1"},
+			{Name: "github.com/foo/b", Version: "b1"},
+			{Name: "github.com/foo/c", Version: "c1", Subpackages: []string{"x"}},
+			{Name: "github.com/foo/d", Version: "d1"},
+		},
+	}
+	ours := &Lockfile{
+		Hash:    "ours",
+		Updated: time.Unix(10, 0),
+		Imports: Locks{
+			{Name: "github.com/foo/a", Version: "a2"},
+			{Name: "github.com/foo/b", Version: "b2"},
+			{Name: "github.com/foo/c", Version: "c2", Subpackages: []string{"x", "y"}},
+			{Name: "github.com/foo/e", Version: "e1"},
+		},
+	}
+	theirs := &Lockfile{
+		Hash:    "theirs",
+		Updated: time.Unix(20, 0),
+		Imports: Locks{
+			{Name: "github.com/foo/a", Version: "a1"},
+			{Name: "github.com/foo/b", Version: "b3"},
+			{Name: "github.com/foo/c", Version: "c2", Subpackages: []string{"x", "z"}},
+			{Name: "github.com/foo/d", Version: "d1"},
+		},
+		Groups: map[string]Locks{"linters": {{Name: "github.com/foo/lint", Version: "l1"}}},
+	}
+
+	m, conflicts := MergeLockfiles(base, ours, theirs)
+
+	if !m.Updated.Equal(time.Unix(20, 0)) {
+		t.Errorf("Expected the later updated time, got %s", m.Updated)
+	}
+	expected := map[string]string{
+		"github.com/foo/a": "a2",
+		"github.com/foo/b": "b2",
+		"github.com/foo/c": "c2",
+		"github.com/foo/e": "e1",
+	}
+	if len(m.Imports) != len(expected) {
+		t.Fatalf("Expected %d imports, got %d", len(expected), len(m.Imports))
+	}
+	for _, l := range m.Imports {
+		if expected[l.Name] != l.Version {
+			t.Errorf("Expected %s at %s, got %s", l.Name, expected[l.Name], l.Version)
+		}
+	}
+	if c := m.Imports[2]; len(c.Subpackages) != 3 {
+		t.Errorf("Expected the subpackages to be merged, got %v", c.Subpackages)
+	}
+	if len(m.Groups["linters"]) != 1 {
+		t.Error("Expected the group added by theirs to be kept")
+	}
+
+	if len(conflicts) != 1 {
+		t.Fatalf("Expected 1 conflict, got %d", len(conflicts))
+	}
+	c := conflicts[0]
+	if c.Section != "imports" || c.Name != "github.com/foo/b" || c.Base != "b1" || c.Ours != "b2" || c.Theirs != "b3" {
+		t.Errorf("Unexpected conflict %+v", c)
+	}
+}
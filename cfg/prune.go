@@ -0,0 +1,129 @@
+package cfg
+
+import (
+	"io/ioutil"
+	"sort"
+
+	"gopkg.in/yaml.v2"
+)
+
+// PruneRules are the rules for removing files from a dependency in the vendor
+// directory when installing with pruning.
+type PruneRules struct {
+
+	// UnusedPackages removes the packages no package of the project reaches
+	// through its imports.
+	UnusedPackages bool `yaml:"unusedPackages,omitempty"`
+
+	// GoTests removes the _test.go files.
+	GoTests bool `yaml:"goTests,omitempty"`
+
+	// Testdata removes the testdata directories.
+	Testdata bool `yaml:"testdata,omitempty"`
+
+	// NonGoFiles removes the files that are not needed to build the Go
+	// packages, except for license files.
+	NonGoFiles bool `yaml:"nonGoFiles,omitempty"`
+}
+
+// AllPruneRules are the rules used when a config does not set any.
+var AllPruneRules = PruneRules{
+	UnusedPackages: true,
+	GoTests:        true,
+	Testdata:       true,
+	NonGoFiles:     true,
+}
+
+// Any returns true if any of the rules removes files.
+func (r PruneRules) Any() bool {
+	return r.UnusedPackages || r.GoTests || r.Testdata || r.NonGoFiles
+}
+
+// Prune holds the rules for removing files from the vendor directory. The
+// rules listed for a dependency replace the top level rules for it.
+type Prune struct {
+	PruneRules `yaml:",inline"`
+
+	Dependencies []*PruneDependency `yaml:"dependencies,omitempty"`
+}
+
+// PruneDependency holds the rules for one dependency.
+type PruneDependency struct {
+	Name       string `yaml:"package"`
+	PruneRules `yaml:",inline"`
+}
+
+// Clone creates a clone of a Prune.
+func (p *Prune) Clone() *Prune {
+	if p == nil {
+		return nil
+	}
+	n := &Prune{PruneRules: p.PruneRules}
+	for _, d := range p.Dependencies {
+		n.Dependencies = append(n.Dependencies, &PruneDependency{Name: d.Name, PruneRules: d.PruneRules})
+	}
+	return n
+}
+
+// PruneRules returns the rules for removing files from a dependency. Every
+// rule is used when the config has no prune section.
+func (c *Config) PruneRules(name string) PruneRules {
+	if c.Prune == nil {
+		return AllPruneRules
+	}
+	for _, d := range c.Prune.Dependencies {
+		if d.Name == name {
+			return d.PruneRules
+		}
+	}
+	return c.Prune.PruneRules
+}
+
+// PruneManifest records the files removed from each dependency in a pruned
+// vendor directory. The digest of each dependency before pruning is the one in
+// the lock file and the pruned digest is of the files left in the vendor
+// directory.
+type PruneManifest struct {
+	Pruned []*PrunedDependency `yaml:"pruned"`
+}
+
+// PrunedDependency records the pruning of one dependency.
+type PrunedDependency struct {
+	Name         string   `yaml:"name"`
+	Digest       string   `yaml:"digest,omitempty"`
+	PrunedDigest string   `yaml:"prunedDigest"`
+	Removed      []string `yaml:"removed,omitempty"`
+}
+
+// Get returns the record of a dependency, or nil if it was not pruned.
+func (m *PruneManifest) Get(name string) *PrunedDependency {
+	for _, p := range m.Pruned {
+		if p.Name == name {
+			return p
+		}
+	}
+	return nil
+}
+
+// WriteFile writes the manifest with the dependencies in name order.
+func (m *PruneManifest) WriteFile(path string) error {
+	sort.Slice(m.Pruned, func(i, j int) bool {
+		return m.Pruned[i].Name < m.Pruned[j].Name
+	})
+	yml, err := yaml.Marshal(m)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, yml, 0666)
+}
+
+// ReadPruneManifest loads the contents of a prune manifest file.
+func ReadPruneManifest(path string) (*PruneManifest, error) {
+	yml, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	m := &PruneManifest{}
+	err = yaml.Unmarshal(yml, m)
+	return m, err
+}
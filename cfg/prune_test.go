@@ -0,0 +1,92 @@
+package cfg
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestPruneRules(t *testing.T) {
+	yml := `package: example.com/app
+import:
+- package: github.com/foo/bar
+prune:
+  goTests: true
+  nonGoFiles: true
+  dependencies:
+  - package: github.com/foo/bar
+    testdata: true
+  - package: github.com/foo/keep
+`
+	c, err := ConfigFromYaml([]byte(yml))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]PruneRules{
+		"github.com/foo/other": {GoTests: true, NonGoFiles: true},
+		"github.com/foo/bar":   {Testdata: true},
+		"github.com/foo/keep":  {},
+	}
+	for name, expected := range tests {
+		if r := c.PruneRules(name); r != expected {
+			t.Errorf("Expected the rules for %s to be %+v but got %+v", name, expected, r)
+		}
+	}
+	if c.PruneRules("github.com/foo/keep").Any() {
+		t.Error("Expected no rules to be used for github.com/foo/keep")
+	}
+
+	n := c.Clone()
+	if !reflect.DeepEqual(n.Prune, c.Prune) || n.Prune == c.Prune {
+		t.Error("Expected the prune rules to be cloned")
+	}
+
+	out, err := c.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	c2, err := ConfigFromYaml(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(c2.Prune, c.Prune) {
+		t.Errorf("Expected the prune rules to survive marshaling but got %s", out)
+	}
+
+	if r := (&Config{}).PruneRules("github.com/foo/bar"); r != AllPruneRules {
+		t.Errorf("Expected every rule without a prune section but got %+v", r)
+	}
+}
+
+func TestPruneManifest(t *testing.T) {
+	dir, err := ioutil.TempDir("", "glide-prune")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	m := &PruneManifest{Pruned: []*PrunedDependency{
+		{Name: "github.com/foo/z", PrunedDigest: "sha256:2"},
+		{Name: "github.com/foo/a", Digest: "sha256:0", PrunedDigest: "sha256:1", Removed: []string{"README.md"}},
+	}}
+	p := filepath.Join(dir, "glide.pruned.yaml")
+	if err := m.WriteFile(p); err != nil {
+		t.Fatal(err)
+	}
+	m2, err := ReadPruneManifest(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(m2.Pruned) != 2 || m2.Pruned[0].Name != "github.com/foo/a" {
+		t.Fatalf("Expected the dependencies in name order but got %+v", m2.Pruned)
+	}
+	if d := m2.Get("github.com/foo/a"); d == nil || !reflect.DeepEqual(d, m.Get("github.com/foo/a")) {
+		t.Errorf("Expected github.com/foo/a to be read back but got %+v", d)
+	}
+	if m2.Get("github.com/foo/none") != nil {
+		t.Error("Expected no record for a dependency that was not pruned")
+	}
+}
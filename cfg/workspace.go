@@ -0,0 +1,54 @@
+package cfg
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Workspace lists the projects in a repository holding more than one
+// glide.yaml file so they can be updated together.
+type Workspace struct {
+
+	// Projects are the directories, relative to the workspace file, holding
+	// the glide.yaml files of the projects.
+	Projects []string `yaml:"projects"`
+
+	// SingleVersion requires every project to resolve a package to the same
+	// version.
+	SingleVersion bool `yaml:"singleVersion,omitempty"`
+}
+
+// WorkspaceFromYaml returns an instance of Workspace from YAML.
+func WorkspaceFromYaml(yml []byte) (*Workspace, error) {
+	w := &Workspace{}
+	if err := yaml.Unmarshal(yml, w); err != nil {
+		return nil, err
+	}
+
+	if len(w.Projects) == 0 {
+		return nil, fmt.Errorf("No projects listed in the workspace")
+	}
+	seen := make(map[string]bool, len(w.Projects))
+	for i, p := range w.Projects {
+		p = path.Clean(p)
+		if seen[p] {
+			return nil, fmt.Errorf("Project %s is listed more than once", p)
+		}
+		seen[p] = true
+		w.Projects[i] = p
+	}
+
+	return w, nil
+}
+
+// ReadWorkspaceFile loads the contents of a workspace file.
+func ReadWorkspaceFile(wpath string) (*Workspace, error) {
+	yml, err := ioutil.ReadFile(wpath)
+	if err != nil {
+		return nil, err
+	}
+	return WorkspaceFromYaml(yml)
+}
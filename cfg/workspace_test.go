@@ -0,0 +1,23 @@
+package cfg
+
+import "testing"
+
+func TestWorkspaceFromYaml(t *testing.T) {
+	w, err := WorkspaceFromYaml([]byte("projects:\n- services/api/\n- ./services/worker\nsingleVersion: true\n"))
+	if err != nil {
+		t.Fatalf("Unexpected error parsing workspace: %s", err)
+	}
+	if len(w.Projects) != 2 || w.Projects[0] != "services/api" || w.Projects[1] != "services/worker" {
+		t.Errorf("Unexpected workspace projects %v", w.Projects)
+	}
+	if !w.SingleVersion {
+		t.Error("Expected singleVersion to be set")
+	}
+
+	if _, err := WorkspaceFromYaml([]byte("singleVersion: true\n")); err == nil {
+		t.Error("Expected a workspace without projects to fail")
+	}
+	if _, err := WorkspaceFromYaml([]byte("projects:\n- api\n- ./api\n")); err == nil {
+		t.Error("Expected a workspace with duplicate projects to fail")
+	}
+}
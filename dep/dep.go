@@ -0,0 +1,164 @@
+// Package dep provides basic importing of dep's Gopkg.toml and Gopkg.lock files.
+//
+// This is not a complete implementation of dep.
+package dep
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/Masterminds/glide/cfg"
+	"github.com/Masterminds/glide/msg"
+	gpath "github.com/Masterminds/glide/path"
+	"github.com/Masterminds/semver"
+)
+
+// Manifest is the subset of dep's Gopkg.toml used by Glide.
+type Manifest struct {
+	Constraints []Project `toml:"constraint"`
+	Overrides   []Project `toml:"override"`
+	Ignored     []string  `toml:"ignored"`
+	Required    []string  `toml:"required"`
+}
+
+// Lock is the subset of dep's Gopkg.lock used by Glide.
+type Lock struct {
+	Projects []LockedProject `toml:"projects"`
+}
+
+// Project is a constraint or override in a Gopkg.toml file.
+type Project struct {
+	Name     string `toml:"name"`
+	Branch   string `toml:"branch"`
+	Revision string `toml:"revision"`
+	Version  string `toml:"version"`
+	Source   string `toml:"source"`
+}
+
+// LockedProject is a project in a Gopkg.lock file.
+type LockedProject struct {
+	Name     string   `toml:"name"`
+	Branch   string   `toml:"branch"`
+	Revision string   `toml:"revision"`
+	Version  string   `toml:"version"`
+	Source   string   `toml:"source"`
+	Packages []string `toml:"packages"`
+}
+
+// Has returns true if this dir has a Gopkg.toml file.
+func Has(dir string) bool {
+	path := filepath.Join(dir, "Gopkg.toml")
+	fi, err := os.Stat(path)
+	return err == nil && !fi.IsDir()
+}
+
+// Parse parses dep's Gopkg.toml and, when present, Gopkg.lock files.
+//
+// Pinned revisions from Gopkg.lock are preferred. Projects only found in
+// Gopkg.toml use their constraint, with overrides taking precedence.
+func Parse(dir string) ([]*cfg.Dependency, error) {
+	path := filepath.Join(dir, "Gopkg.toml")
+	if fi, err := os.Stat(path); err != nil || fi.IsDir() {
+		return []*cfg.Dependency{}, nil
+	}
+
+	msg.Info("Found Gopkg.toml file in %s", gpath.StripBasepath(dir))
+	msg.Info("--> Parsing dep metadata...")
+
+	m := &Manifest{}
+	if _, err := toml.DecodeFile(path, m); err != nil {
+		return []*cfg.Dependency{}, err
+	}
+
+	buf := []*cfg.Dependency{}
+	seen := map[string]*cfg.Dependency{}
+
+	lpath := filepath.Join(dir, "Gopkg.lock")
+	if _, err := os.Stat(lpath); err == nil {
+		l := &Lock{}
+		if _, err := toml.DecodeFile(lpath, l); err != nil {
+			return []*cfg.Dependency{}, err
+		}
+		for _, p := range l.Projects {
+			if seen[p.Name] != nil {
+				continue
+			}
+			dep := &cfg.Dependency{
+				Name:       p.Name,
+				Reference:  p.Revision,
+				Repository: p.Source,
+			}
+			for _, pkg := range p.Packages {
+				if pkg != "." {
+					dep.Subpackages = append(dep.Subpackages, pkg)
+				}
+			}
+			seen[p.Name] = dep
+			buf = append(buf, dep)
+		}
+	}
+
+	// Overrides come first so they win over constraints for the same project.
+	for _, p := range append(m.Overrides, m.Constraints...) {
+		if d, ok := seen[p.Name]; ok {
+			if d.Repository == "" {
+				d.Repository = p.Source
+			}
+			continue
+		}
+		dep := &cfg.Dependency{
+			Name:       p.Name,
+			Reference:  reference(p),
+			Repository: p.Source,
+		}
+		seen[p.Name] = dep
+		buf = append(buf, dep)
+	}
+
+	return buf, nil
+}
+
+// Ignored returns the packages listed in the ignored section of a Gopkg.toml
+// file. dep's trailing wildcards are dropped as Glide ignores sub-packages of
+// an ignored package.
+func Ignored(dir string) ([]string, error) {
+	path := filepath.Join(dir, "Gopkg.toml")
+	if fi, err := os.Stat(path); err != nil || fi.IsDir() {
+		return []string{}, nil
+	}
+
+	m := &Manifest{}
+	if _, err := toml.DecodeFile(path, m); err != nil {
+		return []string{}, err
+	}
+
+	buf := make([]string, 0, len(m.Ignored))
+	for _, i := range m.Ignored {
+		buf = append(buf, strings.TrimSuffix(i, "/*"))
+	}
+	return buf, nil
+}
+
+// reference converts a dep constraint to a Glide version reference.
+//
+// dep treats a bare version such as 1.2.0 as ^1.2.0 while Glide treats it as
+// an exact version so a caret is added. dep's = operator pins a version.
+func reference(p Project) string {
+	switch {
+	case p.Revision != "":
+		return p.Revision
+	case p.Branch != "":
+		return p.Branch
+	case p.Version == "":
+		return ""
+	case strings.HasPrefix(p.Version, "="):
+		return strings.TrimSpace(strings.TrimPrefix(p.Version, "="))
+	}
+
+	if _, err := semver.NewVersion(p.Version); err == nil {
+		return "^" + p.Version
+	}
+	return p.Version
+}
@@ -0,0 +1,92 @@
+package dep
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const testManifest = `required = ["github.com/foo/tool"]
+ignored = ["github.com/ignored/one", "github.com/ignored/many/*"]
+
+[[constraint]]
+  name = "github.com/foo/bar"
+  version = "1.2.0"
+
+[[constraint]]
+  name = "github.com/foo/baz"
+  branch = "develop"
+  source = "https://github.com/fork/baz.git"
+
+[[constraint]]
+  name = "github.com/foo/exact"
+  version = "=2.0.1"
+
+[[constraint]]
+  name = "github.com/foo/locked"
+  version = "^0.4.0"
+
+[[override]]
+  name = "github.com/foo/bar"
+  revision = "3864e76763d94a6df2f9960b16a20a33da9f9a66"
+`
+
+const testLock = `[[projects]]
+  name = "github.com/foo/locked"
+  packages = [".", "sub"]
+  revision = "58046073cbffe2f25d425fe1331102f55cf719de"
+  version = "v0.4.1"
+`
+
+func TestParse(t *testing.T) {
+	dir, err := ioutil.TempDir("", "glide-dep")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	if Has(dir) {
+		t.Error("Expected no Gopkg.toml to be found")
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "Gopkg.toml"), []byte(testManifest), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "Gopkg.lock"), []byte(testLock), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if !Has(dir) {
+		t.Fatal("Expected Gopkg.toml to be found")
+	}
+
+	deps, err := Parse(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []struct{ name, ref, repo string }{
+		{"github.com/foo/locked", "58046073cbffe2f25d425fe1331102f55cf719de", ""},
+		{"github.com/foo/bar", "3864e76763d94a6df2f9960b16a20a33da9f9a66", ""},
+		{"github.com/foo/baz", "develop", "https://github.com/fork/baz.git"},
+		{"github.com/foo/exact", "2.0.1", ""},
+	}
+	if len(deps) != len(expected) {
+		t.Fatalf("Expected %d dependencies but got %d", len(expected), len(deps))
+	}
+	for i, e := range expected {
+		d := deps[i]
+		if d.Name != e.name || d.Reference != e.ref || d.Repository != e.repo {
+			t.Errorf("Expected %s %q %q but got %s %q %q", e.name, e.ref, e.repo, d.Name, d.Reference, d.Repository)
+		}
+	}
+	if len(deps[0].Subpackages) != 1 || deps[0].Subpackages[0] != "sub" {
+		t.Errorf("Unexpected subpackages %v", deps[0].Subpackages)
+	}
+
+	ignored, err := Ignored(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ignored) != 2 || ignored[0] != "github.com/ignored/one" || ignored[1] != "github.com/ignored/many" {
+		t.Errorf("Unexpected ignored packages %v", ignored)
+	}
+}
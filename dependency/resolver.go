@@ -8,10 +8,12 @@ import (
 
 	//"go/build"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
 
 	"github.com/Masterminds/glide/cfg"
+	"github.com/Masterminds/glide/gomod"
 	"github.com/Masterminds/glide/msg"
 	gpath "github.com/Masterminds/glide/path"
 	"github.com/Masterminds/glide/util"
@@ -92,10 +94,11 @@ func (d *DefaultMissingPackageHandler) InVendor(pkg string, addTest bool) error
 	return nil
 }
 
-// PkgPath returns the path to the package
+// PkgPath returns the path to the package. A major version suffix of a Go
+// module that is not a directory is left out.
 func (d *DefaultMissingPackageHandler) PkgPath(pkg string) string {
 	if d.Prefix != "" {
-		return filepath.Join(d.Prefix, pkg)
+		return filepath.Join(d.Prefix, filepath.FromSlash(gomod.ImportDir(d.Prefix, pkg)))
 	}
 	return pkg
 }
@@ -134,6 +137,22 @@ func (d *DefaultVersionHandler) SetVersion(pkg string, testDep bool) error {
 	return nil
 }
 
+// ImportGraph records the packages imported by each package a Resolver scans.
+// Packages are keyed by import path and the imports are as written in the
+// source, including those from the standard library.
+type ImportGraph struct {
+	Imports     map[string][]string
+	TestImports map[string][]string
+}
+
+// NewImportGraph returns an empty ImportGraph. This is the constructor.
+func NewImportGraph() *ImportGraph {
+	return &ImportGraph{
+		Imports:     map[string][]string{},
+		TestImports: map[string][]string{},
+	}
+}
+
 // Resolver resolves a dependency tree.
 //
 // It operates in two modes:
@@ -158,6 +177,9 @@ type Resolver struct {
 	// ResolveTest sets if test dependencies should be resolved.
 	ResolveTest bool
 
+	// Graph, when set, records the imports of every package scanned.
+	Graph *ImportGraph
+
 	// Items already in the queue.
 	alreadyQ map[string]bool
 
@@ -308,6 +330,7 @@ func (r *Resolver) ResolveLocal(deep bool) ([]string, []string, error) {
 			imps = p.Imports
 			testImps = dedupeStrings(p.TestImports, p.XTestImports)
 		}
+		r.record(r.localImportPath(path), imps, testImps)
 
 		// We are only looking for dependencies in vendor. No root, cgo, etc.
 		for _, imp := range imps {
@@ -426,6 +449,24 @@ func (r *Resolver) ResolveAll(deps []*cfg.Dependency, addTest bool) ([]string, e
 	return r.resolveImports(queue, false, addTest)
 }
 
+// localImportPath returns the import path of a directory in the project.
+func (r *Resolver) localImportPath(dir string) string {
+	rel, err := filepath.Rel(r.basedir, dir)
+	if err != nil || rel == "." {
+		return r.Config.Name
+	}
+	return path.Join(r.Config.Name, filepath.ToSlash(rel))
+}
+
+// record adds the imports of a package to the graph, when there is one.
+func (r *Resolver) record(pkg string, imps, testImps []string) {
+	if r.Graph == nil {
+		return
+	}
+	r.Graph.Imports[pkg] = imps
+	r.Graph.TestImports[pkg] = testImps
+}
+
 // Stripv strips the vendor/ prefix from vendored packages.
 func (r *Resolver) Stripv(str string) string {
 	return strings.TrimPrefix(str, r.VendorDir+string(os.PathSeparator))
@@ -500,16 +541,18 @@ func (r *Resolver) resolveImports(queue *list.List, testDeps, addTest bool) ([]s
 			// or main but +build ignore as a build tag. In that case we
 			// try to brute force the packages with a slower scan.
 			msg.Debug("Using Iterative Scanning for %s", dep)
-			if testDeps {
-				_, imps, err = IterativeScan(r.Handler.PkgPath(dep))
-			} else {
-				imps, _, err = IterativeScan(r.Handler.PkgPath(dep))
-			}
-
+			var all, tall []string
+			all, tall, err = IterativeScan(r.Handler.PkgPath(dep))
 			if err != nil {
 				msg.Err("Iterative scanning error %s: %s", dep, err)
 				continue
 			}
+			r.record(dep, all, tall)
+			if testDeps {
+				imps = tall
+			} else {
+				imps = all
+			}
 		} else if err != nil {
 			errStr := err.Error()
 			msg.Debug("ImportDir error on %s: %s", r.Handler.PkgPath(dep), err)
@@ -557,8 +600,10 @@ func (r *Resolver) resolveImports(queue *list.List, testDeps, addTest bool) ([]s
 			}
 			continue
 		} else {
+			timps := dedupeStrings(pkg.TestImports, pkg.XTestImports)
+			r.record(dep, pkg.Imports, timps)
 			if testDeps {
-				imps = dedupeStrings(pkg.TestImports, pkg.XTestImports)
+				imps = timps
 			} else {
 				imps = pkg.Imports
 			}
@@ -825,20 +870,24 @@ func (r *Resolver) imports(pkg string, testDeps, addTest bool) ([]string, error)
 		// declared. This is often because of an example with a package
 		// or main but +build ignore as a build tag. In that case we
 		// try to brute force the packages with a slower scan.
-		if testDeps {
-			_, imps, err = IterativeScan(r.Handler.PkgPath(pkg))
-		} else {
-			imps, _, err = IterativeScan(r.Handler.PkgPath(pkg))
-		}
-
+		var all, tall []string
+		all, tall, err = IterativeScan(r.Handler.PkgPath(pkg))
 		if err != nil {
 			return []string{}, err
 		}
+		r.record(r.Stripv(pkg), all, tall)
+		if testDeps {
+			imps = tall
+		} else {
+			imps = all
+		}
 	} else if err != nil {
 		return []string{}, err
 	} else {
+		timps := dedupeStrings(p.TestImports, p.XTestImports)
+		r.record(r.Stripv(pkg), p.Imports, timps)
 		if testDeps {
-			imps = dedupeStrings(p.TestImports, p.XTestImports)
+			imps = timps
 		} else {
 			imps = p.Imports
 		}
@@ -956,6 +1005,27 @@ const (
 	LocRelative
 )
 
+// String returns the name of the location, such as vendor or gopath.
+func (l PkgLoc) String() string {
+	switch l {
+	case LocLocal:
+		return "local"
+	case LocVendor:
+		return "vendor"
+	case LocGopath:
+		return "gopath"
+	case LocGoroot:
+		return "goroot"
+	case LocCgo:
+		return "cgo"
+	case LocAppengine:
+		return "appengine"
+	case LocRelative:
+		return "relative"
+	}
+	return "unknown"
+}
+
 // PkgInfo represents metadata about a package found by the resolver.
 type PkgInfo struct {
 	Name, Path string
@@ -1015,8 +1085,10 @@ func (r *Resolver) FindPkg(name string) *PkgInfo {
 		return info
 	}
 
-	// Check _only_ if this dep is in the current vendor directory.
-	p = filepath.Join(r.VendorDir, filepath.FromSlash(name))
+	// Check _only_ if this dep is in the current vendor directory. A package
+	// in a Go module with a major version suffix may be in the directory
+	// without it.
+	p = filepath.Join(r.VendorDir, filepath.FromSlash(gomod.ImportDir(r.VendorDir, name)))
 	if pkgExists(p) {
 		info.Path = p
 		info.Loc = LocVendor
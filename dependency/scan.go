@@ -58,13 +58,13 @@ func IterativeScan(path string) ([]string, []string, error) {
 				dirty = true
 				ttt = strings.TrimPrefix(ttt, "!")
 			}
-			if isSupportedOs(ttt) {
+			if IsSupportedOs(ttt) {
 				if dirty {
 					ops = getOsValue(ttt)
 				} else {
 					ops = ttt
 				}
-			} else if isSupportedArch(ttt) {
+			} else if IsSupportedArch(ttt) {
 				if dirty {
 					arch = getArchValue(ttt)
 				} else {
@@ -280,7 +280,9 @@ func getOsValue(n string) string {
 	return n
 }
 
-func isSupportedOs(n string) bool {
+// IsSupportedOs returns true if n is an operating system name used in build
+// constraints.
+func IsSupportedOs(n string) bool {
 	for _, o := range osList {
 		if o == n {
 			return true
@@ -301,7 +303,9 @@ func getArchValue(n string) string {
 	return n
 }
 
-func isSupportedArch(n string) bool {
+// IsSupportedArch returns true if n is an architecture name used in build
+// constraints.
+func IsSupportedArch(n string) bool {
 	for _, o := range archList {
 		if o == n {
 			return true
@@ -32,6 +32,7 @@ import (
 
 	"fmt"
 	"os"
+	"strings"
 )
 
 var version = "0.13.4-dev"
@@ -200,6 +201,10 @@ func commands() []cli.Command {
 					Name:  "all-dependencies",
 					Usage: "This will resolve all dependencies for all packages, not just those directly used.",
 				},
+				cli.BoolFlag{
+					Name:  "backtrack",
+					Usage: "Choose versions with the backtracking solver, trying older versions to satisfy every package. Experimental.",
+				},
 				cli.BoolFlag{
 					Name:   "update-vendored, u",
 					Usage:  "Update vendored packages (without local VCS repo). Warning, changes will be lost.",
@@ -278,6 +283,7 @@ func commands() []cli.Command {
 				inst := repo.NewInstaller()
 				inst.Force = c.Bool("force")
 				inst.ResolveAllFiles = c.Bool("all-dependencies")
+				inst.Backtrack = c.Bool("backtrack")
 				inst.ResolveTest = !c.Bool("skip-test")
 				packages := []string(c.Args())
 				insecure := c.Bool("insecure")
@@ -374,6 +380,160 @@ func commands() []cli.Command {
 						return nil
 					},
 				},
+				{
+					Name:  "gomod",
+					Usage: "Import Go modules go.mod file and display the would-be yaml file",
+					Flags: []cli.Flag{
+						cli.StringFlag{
+							Name:  "file, f",
+							Usage: "Save all of the discovered dependencies to a Glide YAML file.",
+						},
+					},
+					Action: func(c *cli.Context) error {
+						action.ImportGomod(c.String("file"))
+						return nil
+					},
+				},
+				{
+					Name:  "dep",
+					Usage: "Import dep's Gopkg.toml and Gopkg.lock files and display the would-be yaml file",
+					Flags: []cli.Flag{
+						cli.StringFlag{
+							Name:  "file, f",
+							Usage: "Save all of the discovered dependencies to a Glide YAML file.",
+						},
+					},
+					Action: func(c *cli.Context) error {
+						action.ImportDep(c.String("file"))
+						return nil
+					},
+				},
+				{
+					Name:  "govendor",
+					Usage: "Import govendor's vendor/vendor.json file and display the would-be yaml file",
+					Flags: []cli.Flag{
+						cli.StringFlag{
+							Name:  "file, f",
+							Usage: "Save all of the discovered dependencies to a Glide YAML file.",
+						},
+					},
+					Action: func(c *cli.Context) error {
+						action.ImportGovendor(c.String("file"))
+						return nil
+					},
+				},
+				{
+					Name:    "vndr",
+					Aliases: []string{"trash"},
+					Usage:   "Import vndr's and trash's vendor.conf file and display the would-be yaml file",
+					Flags: []cli.Flag{
+						cli.StringFlag{
+							Name:  "file, f",
+							Usage: "Save all of the discovered dependencies to a Glide YAML file.",
+						},
+					},
+					Action: func(c *cli.Context) error {
+						action.ImportVndr(c.String("file"))
+						return nil
+					},
+				},
+			},
+		},
+		{
+			Name:  "export",
+			Usage: "Export files for other dependency management systems.",
+			Subcommands: []cli.Command{
+				{
+					Name:  "gomod",
+					Usage: "Export the glide.yaml and glide.lock files to Go modules go.mod and go.sum files",
+					Description: `Locked commits are converted to module versions. Semantic version tags
+   are used when present on the locked commit. Otherwise a pseudo-version is
+   generated from the commit date. The go.sum hashes are computed from the
+   repositories in the Glide cache so nothing is fetched. Run 'glide install'
+   first to make sure the cache is populated.`,
+					Flags: []cli.Flag{
+						cli.StringFlag{
+							Name:  "go",
+							Usage: "The Go version to use for the go directive.",
+							Value: "1.12",
+						},
+						cli.BoolFlag{
+							Name:  "skip-sum",
+							Usage: "Do not generate a go.sum file.",
+						},
+						cli.BoolFlag{
+							Name:  "force",
+							Usage: "Overwrite an existing go.mod file.",
+						},
+					},
+					Action: func(c *cli.Context) error {
+						action.ExportGomod(c.String("go"), c.Bool("skip-sum"), c.Bool("force"))
+						return nil
+					},
+				},
+			},
+		},
+		{
+			Name:  "tools",
+			Usage: "Manage the tools listed in the glide.yaml file.",
+			Subcommands: []cli.Command{
+				{
+					Name:  "install",
+					Usage: "Build the tools into the bin directory",
+					Description: `The main packages listed under 'tools' in the glide.yaml file are built
+   from the vendor directory into the bin directory of the project. Run
+   'glide install' first so the versions in the glide.lock file are vendored.
+
+   A tool is only rebuilt when its locked commit changes or its binary is
+   missing. Use '--force' to rebuild all of them.`,
+					Flags: []cli.Flag{
+						cli.BoolFlag{
+							Name:  "force",
+							Usage: "Rebuild every tool.",
+						},
+					},
+					Action: func(c *cli.Context) error {
+						action.ToolsInstall(c.Bool("force"))
+						return nil
+					},
+				},
+			},
+		},
+		{
+			Name:  "lock",
+			Usage: "Work with the glide.lock file.",
+			Subcommands: []cli.Command{
+				{
+					Name:  "merge",
+					Usage: "Merge two versions of a glide.lock file",
+					Description: `Use 'merge' in the form:
+
+       glide lock merge [base] [ours] [theirs] [path]
+
+   This is a git merge driver. To use it add the driver to the git config
+
+       git config merge.glide.name "glide.lock merge driver"
+       git config merge.glide.driver "glide lock merge %O %A %B %P"
+
+   and the lock file to the .gitattributes file
+
+       glide.lock merge=glide
+
+   The dependencies in the lock files are merged one by one and the result is
+   written to the ours file. Only dependencies both sides locked to different
+   versions conflict. They are reported and marked in the merged file. The
+   hash is that of the merged glide.yaml file. When the glide.yaml file does
+   not merge cleanly the hash is marked as a conflict as well. Run glide up
+   once the glide.yaml file is merged.`,
+					Action: func(c *cli.Context) error {
+						if len(c.Args()) < 3 {
+							fmt.Println("Oops! The base, ours, and theirs lock files are required.")
+							os.Exit(1)
+						}
+						action.LockMerge(c.Args().Get(0), c.Args().Get(1), c.Args().Get(2), c.Args().Get(3))
+						return nil
+					},
+				},
 			},
 		},
 		{
@@ -429,7 +589,21 @@ Example:
    no lock file (glide.lock) the dependencies are installed using the "update"
    command and a glide.lock file is generated pinning all dependencies. If a
    glide.lock file is already present the dependencies are installed or updated
-   from the lock file.`,
+   from the lock file.
+
+   The '--group' flag installs only the named dependency groups. The imports,
+   test imports, and tools can be selected with the names 'import',
+   'testImport', and 'tools'. For example, '--group import,tools' installs the
+   imports and the tools.
+
+   The '--prune' flag removes files the project does not need from the
+   dependencies in the vendor/ directory: the packages it does not reach
+   through its imports, _test.go files, testdata directories, and files that
+   are not needed to build the Go packages, except for license files. The
+   rules can be changed, for every dependency or one at a time, in the prune
+   section of the glide.yaml file. The files removed are recorded in
+   vendor/glide.pruned.yaml so 'glide verify' can check the pruned
+   dependencies.`,
 			Flags: []cli.Flag{
 				cli.BoolFlag{
 					Name:   "delete",
@@ -478,6 +652,14 @@ Example:
 					Name:  "skip-test",
 					Usage: "Resolve dependencies in test files.",
 				},
+				cli.StringFlag{
+					Name:  "group",
+					Usage: "A comma separated list of the dependency groups to install.",
+				},
+				cli.BoolFlag{
+					Name:  "prune",
+					Usage: "Remove the packages and files the project does not need from vendor/.",
+				},
 			},
 			Action: func(c *cli.Context) error {
 				if c.Bool("delete") {
@@ -506,8 +688,14 @@ Example:
 				installer.Force = c.Bool("force")
 				installer.Home = c.GlobalString("home")
 				installer.ResolveTest = !c.Bool("skip-test")
+				installer.Prune = c.Bool("prune")
+
+				var groups []string
+				if g := c.String("group"); g != "" {
+					groups = strings.Split(g, ",")
+				}
 
-				action.Install(installer, c.Bool("strip-vendor"))
+				action.Install(installer, c.Bool("strip-vendor"), groups)
 				return nil
 			},
 		},
@@ -535,7 +723,30 @@ Example:
    'Godeps/_workspace' folders after an update (along with undoing any Godep
    import rewriting). Note, the Godeps specific functionality is deprecated and
    will be removed when most Godeps users have migrated to using the vendor
-   folder.`,
+   folder.
+
+   The '--backtrack' flag chooses versions with the backtracking solver. When
+   packages ask for versions of a dependency that conflict it tries older
+   versions of the packages until every package is satisfied. When there is no
+   solution the requirements that conflict are listed. The solver is being
+   rolled out and will become the default.
+
+   The '--platforms' flag resolves the dependencies once for each of a comma
+   separated list of os/arch pairs, such as
+   'linux/amd64,darwin/arm64,windows/amd64', looking only at the files built
+   on each, and merges the results. Dependencies needed on only some of them
+   are limited to those with 'os' and 'arch' in the glide.lock file so they
+   are skipped when installing on the others.
+
+   The '--save-new' flag adds the packages the code of the project imports
+   directly but the glide.yaml file does not list to it. Each is given a
+   range tracking the minor releases of its latest semantic version tag.
+   Packages only imported by tests are added as test imports.
+
+   The '--workspace' flag updates every project listed in the nearest
+   glide.workspace.yaml file in one run. Packages resolved to different
+   versions by the projects are reported. With '--single-version', or
+   'singleVersion: true' in the workspace file, this is an error.`,
 			Flags: []cli.Flag{
 				cli.BoolFlag{
 					Name:   "delete",
@@ -554,6 +765,10 @@ Example:
 					Name:  "all-dependencies",
 					Usage: "This will resolve all dependencies for all packages, not just those directly used.",
 				},
+				cli.BoolFlag{
+					Name:  "backtrack",
+					Usage: "Choose versions with the backtracking solver, trying older versions to satisfy every package. Experimental.",
+				},
 				cli.BoolFlag{
 					Name:   "update-vendored, u",
 					Usage:  "Update vendored packages (without local VCS repo). Warning, changes will be lost.",
@@ -583,6 +798,10 @@ Example:
 					Name:  "resolve-current",
 					Usage: "Resolve dependencies for only the current system rather than all build modes.",
 				},
+				cli.StringFlag{
+					Name:  "platforms",
+					Usage: "Resolve dependencies for each of a comma separated list of os/arch pairs and merge them.",
+				},
 				cli.BoolFlag{
 					Name:   "strip-vcs, s",
 					Usage:  "Removes version control metadata (e.g, .git directory) from the vendor folder.",
@@ -596,6 +815,18 @@ Example:
 					Name:  "skip-test",
 					Usage: "Resolve dependencies in test files.",
 				},
+				cli.BoolFlag{
+					Name:  "save-new",
+					Usage: "Add the packages the project imports but the glide.yaml file does not list to it.",
+				},
+				cli.BoolFlag{
+					Name:  "workspace",
+					Usage: "Update every project listed in the glide.workspace.yaml file.",
+				},
+				cli.BoolFlag{
+					Name:  "single-version",
+					Usage: "With --workspace, fail when projects resolve a package to different versions.",
+				},
 			},
 			Action: func(c *cli.Context) error {
 				if c.Bool("delete") {
@@ -625,13 +856,32 @@ Example:
 					msg.Warn("Only resolving dependencies for the current OS/Arch")
 				}
 
+				if c.Bool("save-new") && c.Bool("no-recursive") {
+					msg.Warn("The --save-new flag has no effect with --no-recursive.")
+				}
+
 				installer := repo.NewInstaller()
 				installer.Force = c.Bool("force")
 				installer.ResolveAllFiles = c.Bool("all-dependencies")
+				installer.Backtrack = c.Bool("backtrack")
 				installer.Home = c.GlobalString("home")
 				installer.ResolveTest = !c.Bool("skip-test")
+				if p := c.String("platforms"); p != "" {
+					if c.Bool("resolve-current") {
+						msg.Warn("The --resolve-current flag has no effect with --platforms.")
+					}
+					installer.Platforms = strings.Split(p, ",")
+				}
+
+				if c.Bool("workspace") {
+					action.UpdateWorkspace(installer, c.Bool("no-recursive"), c.Bool("strip-vendor"), c.Bool("single-version"), c.Bool("save-new"))
+					return nil
+				}
+				if c.Bool("single-version") {
+					msg.Warn("The --single-version flag only applies with --workspace.")
+				}
 
-				action.Update(installer, c.Bool("no-recursive"), c.Bool("strip-vendor"))
+				action.Update(installer, c.Bool("no-recursive"), c.Bool("strip-vendor"), c.Bool("save-new"))
 
 				return nil
 			},
@@ -647,8 +897,26 @@ Example:
    one of its dependencies.
 
    Note, for large projects this can display a large list tens of thousands of
-   lines long.`,
+   lines long.
+
+   The '--format' flag prints the whole import graph instead, for use with
+   Graphviz (dot), tools that read GraphML (graphml), or scripts (json). The
+   graph holds the packages and the repositories they are in, annotated with
+   where each package was found, the versions in the glide.lock file, and
+   whether they are only imported by tests. Imports that lead to a cycle are
+   marked.`,
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "format",
+					Usage: "Output format. One of: dot|graphml|json|json-pretty|text",
+					Value: "text",
+				},
+			},
 			Action: func(c *cli.Context) error {
+				if f := c.String("format"); f != "text" {
+					action.TreeGraph(".", false, f)
+					return nil
+				}
 				action.Tree(".", false)
 				return nil
 			},
@@ -675,6 +943,138 @@ Example:
 				},
 			},
 		},
+		{
+			Name:  "diff",
+			Usage: "List the dependencies changed between two lock files.",
+			Description: `Diff compares two glide.lock files and lists the dependencies added,
+   removed, upgraded, and downgraded.
+
+   Each lock file can be a path or a git revision the project's glide.lock
+   file is read from. The old one defaults to HEAD and the new one to the
+   glide.lock file in the project. For example,
+
+       glide diff master
+
+   lists the dependencies changed since master.
+
+   The tags and the subjects of the commits between the old and new versions
+   are read from the repositories in the Glide cache. Nothing is fetched.
+   Use '--output markdown' for a list to paste into a pull request.`,
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "output, o",
+					Usage: "Output format. One of: json|json-pretty|markdown|text",
+					Value: "text",
+				},
+			},
+			Action: func(c *cli.Context) error {
+				action.Diff(c.Args().Get(0), c.Args().Get(1), c.String("output"))
+				return nil
+			},
+		},
+		{
+			Name:      "why",
+			Usage:     "Explain why a package is a dependency of the project.",
+			ArgsUsage: "<package>",
+			Description: `Why prints every import chain from the packages of the project to
+   a package in the vendor/ directory.
+
+       glide why github.com/Masterminds/semver
+
+   The package can be a single package or the root package of a repository.
+   Chains that only exist through the tests of the project are marked. For
+   each step into another project the version the configuration of the
+   importing project asks for is shown in brackets.`,
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "output, o",
+					Usage: "Output format. One of: json|json-pretty|text",
+					Value: "text",
+				},
+			},
+			Action: func(c *cli.Context) error {
+				if len(c.Args()) < 1 {
+					fmt.Println("Oops! Package name is required.")
+					os.Exit(1)
+				}
+				action.Why(c.Args().First(), c.String("output"))
+				return nil
+			},
+		},
+		{
+			Name:  "prune-config",
+			Usage: "List the dependencies in the glide.yaml file nothing imports.",
+			Description: `Prune-config scans the project and the packages it uses in the vendor/
+   directory and lists the import and testImport entries of the glide.yaml
+   file that no package of the project reaches, directly or through other
+   dependencies. Run 'glide install' first so every package can be scanned.
+
+   Use '--remove' to remove them from the glide.yaml file. Run 'glide up'
+   afterwards to update the glide.lock file and the vendor/ directory.`,
+			Flags: []cli.Flag{
+				cli.BoolFlag{
+					Name:  "remove",
+					Usage: "Remove the unused dependencies from the glide.yaml file.",
+				},
+			},
+			Action: func(c *cli.Context) error {
+				action.PruneConfig(c.Bool("remove"))
+				return nil
+			},
+		},
+		{
+			Name:  "verify",
+			Usage: "Verify the vendor/ directory against the glide.lock file.",
+			Description: `Verify compares the contents of the vendor/ directory to the
+   glide.lock file without accessing the network.
+
+   It reports locked dependencies missing from vendor/, directories in vendor/
+   that are not in the lock file, vendored VCS checkouts at a version other
+   than the locked one, and dependencies whose contents no longer match the
+   digest recorded in the lock file.
+
+   When any difference is found verify exits with a non-zero exit code. This
+   makes it suitable as a check in continuous integration.`,
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "output, o",
+					Usage: "Output format. One of: json|json-pretty|text",
+					Value: "text",
+				},
+				cli.BoolFlag{
+					Name:  "skip-test",
+					Usage: "Do not require test dependencies to be present.",
+				},
+			},
+			Action: func(c *cli.Context) error {
+				action.Verify(c.String("output"), c.Bool("skip-test"))
+				return nil
+			},
+		},
+		{
+			Name:  "lint",
+			Usage: "Check the glide.yaml file for problems.",
+			Description: `Lint validates the glide.yaml file and reports each problem with
+   the line and column it appears at.
+
+   It reports unknown fields, invalid version constraints, unknown VCS types,
+   malformed repo locations, license values that are neither a valid SPDX
+   expression nor a license file, unsupported os and arch names, and
+   subpackages that do not exist in the cached copy of a repository.
+
+   When an error is found lint exits with a non-zero exit code.`,
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "output, o",
+					Usage: "Output format. One of: json|json-pretty|text",
+					Value: "text",
+				},
+			},
+			Action: func(c *cli.Context) error {
+				action.Lint(c.String("output"))
+				return nil
+			},
+		},
 		{
 			Name:  "info",
 			Usage: "Info prints information about this project",
@@ -19,19 +19,46 @@ func Has(dir string) bool {
 }
 
 // Parse parses a Gomfile.
+//
+// Only dependencies in the development and production groups, or in no group,
+// are returned. Others are skipped.
 func Parse(dir string) ([]*cfg.Dependency, error) {
+	buf, groups, err := ParseGroups(dir)
+	if err != nil {
+		return buf, err
+	}
+	skipped := map[string]bool{}
+	for _, g := range groups {
+		for _, dep := range g {
+			if !skipped[dep.Name] {
+				// right now we only support development and production
+				msg.Info("Skipping dependency '%s' because it isn't in the development or production group", dep.Name)
+				skipped[dep.Name] = true
+			}
+		}
+	}
+	return buf, nil
+}
+
+// ParseGroups parses a Gomfile keeping its groups.
+//
+// Dependencies in the development and production groups, or in no group, are
+// returned first. Dependencies in other groups are returned by group name. A
+// dependency in more than one of those groups is listed in each.
+func ParseGroups(dir string) ([]*cfg.Dependency, map[string][]*cfg.Dependency, error) {
 	path := filepath.Join(dir, "Gomfile")
 	if fi, err := os.Stat(path); err != nil || fi.IsDir() {
-		return []*cfg.Dependency{}, nil
+		return []*cfg.Dependency{}, nil, nil
 	}
 
 	msg.Info("Found Gomfile in %s", gpath.StripBasepath(dir))
 	msg.Info("--> Parsing Gomfile metadata...")
 	buf := []*cfg.Dependency{}
+	groups := map[string][]*cfg.Dependency{}
 
 	goms, err := parseGomfile(path)
 	if err != nil {
-		return []*cfg.Dependency{}, err
+		return []*cfg.Dependency{}, nil, err
 	}
 
 	for _, gom := range goms {
@@ -42,16 +69,15 @@ func Parse(dir string) ([]*cfg.Dependency, error) {
 
 		// Check for custom cloning command
 		if _, ok := gom.options["command"]; ok {
-			return []*cfg.Dependency{}, errors.New("Glide does not support custom Gomfile commands")
+			return []*cfg.Dependency{}, nil, errors.New("Glide does not support custom Gomfile commands")
 		}
 
 		// Check for groups/environments
+		var envs []string
 		if val, ok := gom.options["group"]; ok {
-			groups := toStringSlice(val)
-			if !stringsContain(groups, "development") && !stringsContain(groups, "production") {
-				// right now we only support development and production
-				msg.Info("Skipping dependency '%s' because it isn't in the development or production group", gom.name)
-				continue
+			envs = toStringSlice(val)
+			if stringsContain(envs, "development") || stringsContain(envs, "production") {
+				envs = nil
 			}
 		}
 
@@ -84,10 +110,21 @@ func Parse(dir string) ([]*cfg.Dependency, error) {
 			dep.Arch = toStringSlice(val)
 		}
 
-		buf = append(buf, dep)
+		if len(envs) == 0 {
+			buf = append(buf, dep)
+			continue
+		}
+		for i, env := range envs {
+			// Each group gets its own copy of the dependency.
+			d := dep
+			if i > 0 {
+				d = dep.Clone()
+			}
+			groups[env] = append(groups[env], d)
+		}
 	}
 
-	return buf, nil
+	return buf, groups, nil
 }
 
 func stringsContain(v []string, key string) bool {
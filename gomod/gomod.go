@@ -0,0 +1,222 @@
+// Package gomod provides compatibility with Go modules go.mod and go.sum files.
+package gomod
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/semver"
+)
+
+// File represents the contents of a go.mod file.
+type File struct {
+	Module  string
+	Go      string
+	Require []*Require
+	Replace []*Replace
+	Exclude []*Exclude
+}
+
+// Require is a require directive in a go.mod file.
+type Require struct {
+	Path     string
+	Version  string
+	Indirect bool
+}
+
+// Exclude is an exclude directive in a go.mod file.
+type Exclude struct {
+	Path    string
+	Version string
+}
+
+// Replace is a replace directive in a go.mod file.
+type Replace struct {
+	Old        string
+	OldVersion string
+	New        string
+	NewVersion string
+}
+
+// Marshal converts a File to the go.mod format. Requirements and replacements
+// are sorted by path for reproducibility.
+func (f *File) Marshal() []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "module %s\n", quote(f.Module))
+	if f.Go != "" {
+		fmt.Fprintf(&b, "\ngo %s\n", f.Go)
+	}
+
+	sort.Slice(f.Require, func(i, j int) bool { return f.Require[i].Path < f.Require[j].Path })
+	if len(f.Require) > 0 {
+		b.WriteString("\nrequire (\n")
+		for _, r := range f.Require {
+			fmt.Fprintf(&b, "\t%s %s", quote(r.Path), r.Version)
+			if r.Indirect {
+				b.WriteString(" // indirect")
+			}
+			b.WriteString("\n")
+		}
+		b.WriteString(")\n")
+	}
+
+	sort.Slice(f.Exclude, func(i, j int) bool { return f.Exclude[i].Path < f.Exclude[j].Path })
+	if len(f.Exclude) > 0 {
+		b.WriteString("\nexclude (\n")
+		for _, e := range f.Exclude {
+			fmt.Fprintf(&b, "\t%s %s\n", quote(e.Path), e.Version)
+		}
+		b.WriteString(")\n")
+	}
+
+	sort.Slice(f.Replace, func(i, j int) bool { return f.Replace[i].Old < f.Replace[j].Old })
+	if len(f.Replace) > 0 {
+		b.WriteString("\nreplace (\n")
+		for _, r := range f.Replace {
+			old := quote(r.Old)
+			if r.OldVersion != "" {
+				old += " " + r.OldVersion
+			}
+			nw := quote(r.New)
+			if r.NewVersion != "" {
+				nw += " " + r.NewVersion
+			}
+			fmt.Fprintf(&b, "\t%s => %s\n", old, nw)
+		}
+		b.WriteString(")\n")
+	}
+
+	return b.Bytes()
+}
+
+// WriteFile writes a go.mod file.
+//
+// If the file exists, it will be clobbered.
+func (f *File) WriteFile(path string) error {
+	return ioutil.WriteFile(path, f.Marshal(), 0666)
+}
+
+// quote quotes a path when it contains characters the go.mod format does not
+// allow in bare identifiers.
+func quote(s string) string {
+	if s == "" || strings.ContainsAny(s, " \t\r\n\"'`,;()[]{}") || strings.Contains(s, "//") || strings.Contains(s, "/*") {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+var majorSuffix = regexp.MustCompile(`(?:/v|\.v)([0-9]+)$`)
+
+// PathMajor returns the major version implied by a module path. For example,
+// github.com/foo/bar/v2 and gopkg.in/yaml.v2 both return 2. Paths without a
+// major version suffix return 0.
+func PathMajor(path string) uint64 {
+	m := majorSuffix.FindStringSubmatch(path)
+	if m == nil {
+		return 0
+	}
+	// gopkg.in uses .vN while everything else uses /vN.
+	if strings.HasPrefix(path, "gopkg.in/") != strings.Contains(m[0], ".v") {
+		return 0
+	}
+	v, err := strconv.ParseUint(m[1], 10, 64)
+	if err != nil {
+		return 0
+	}
+	return v
+}
+
+var canonicalTag = regexp.MustCompile(`^v[0-9]+\.[0-9]+\.[0-9]+(?:-[0-9A-Za-z.-]+)?(?:\+[0-9A-Za-z.-]+)?$`)
+
+// TagVersion converts a VCS tag into a module version for the module at path.
+//
+// Only canonical semantic version tags (e.g., v1.2.3) are usable by Go modules.
+// When the tag is a major version of 2 or greater and the module path does not
+// carry the matching major version suffix the version is marked +incompatible.
+// The second return value is false when the tag cannot be used for path.
+func TagVersion(path, tag string) (string, bool) {
+	if !canonicalTag.MatchString(tag) {
+		return "", false
+	}
+	v, err := semver.NewVersion(tag)
+	if err != nil {
+		return "", false
+	}
+	// Build metadata is not part of a module version.
+	ver := strings.SplitN(tag, "+", 2)[0]
+
+	pm := PathMajor(path)
+	switch {
+	case pm > 0 && v.Major() != int64(pm):
+		return "", false
+	case pm == 0 && strings.HasPrefix(path, "gopkg.in/"):
+		return "", false
+	case pm == 0 && v.Major() > 1:
+		return ver + "+incompatible", true
+	}
+
+	return ver, true
+}
+
+// PseudoVersion generates a module pseudo-version for a commit that has no
+// usable tag. The major version is taken from the module path.
+func PseudoVersion(path string, t time.Time, rev string) string {
+	if len(rev) > 12 {
+		rev = rev[:12]
+	}
+	return fmt.Sprintf("v%d.0.0-%s-%s", PathMajor(path), t.UTC().Format("20060102150405"), rev)
+}
+
+// LocalVersion is the placeholder version required for modules that are
+// replaced by a directory on the local filesystem.
+const LocalVersion = "v0.0.0-00010101000000-000000000000"
+
+// LocalPath converts a filesystem path into the form used by the target of a
+// replace directive. Relative paths must begin with ./ or ../ for the go tool
+// to treat them as directories rather than module paths.
+func LocalPath(p string) string {
+	p = filepath.ToSlash(p)
+	if path.IsAbs(p) || filepath.IsAbs(filepath.FromSlash(p)) || strings.HasPrefix(p, "./") || strings.HasPrefix(p, "../") {
+		return p
+	}
+	return "./" + p
+}
+
+var scpLike = regexp.MustCompile(`^(?:[A-Za-z0-9_.-]+@)?([A-Za-z0-9_.-]+):(.+)$`)
+
+// PathFromRepo derives a module path from a repository location such as
+// https://github.com/foo/bar.git or git@github.com:foo/bar.git. An empty
+// string is returned when no path can be derived, such as for local paths.
+func PathFromRepo(remote string) string {
+	r := remote
+	if i := strings.Index(r, "://"); i >= 0 {
+		r = r[i+3:]
+		if j := strings.Index(r, "@"); j >= 0 && j < strings.Index(r+"/", "/") {
+			r = r[j+1:]
+		}
+	} else if m := scpLike.FindStringSubmatch(r); m != nil {
+		r = m[1] + "/" + m[2]
+	} else {
+		return ""
+	}
+
+	r = strings.TrimSuffix(strings.TrimSuffix(r, "/"), ".git")
+	// Ports are not part of a module path.
+	if i := strings.Index(r, "/"); i >= 0 {
+		if j := strings.Index(r[:i], ":"); j >= 0 {
+			r = r[:j] + r[i:]
+		}
+	}
+	if !strings.Contains(r, ".") || !strings.Contains(r, "/") {
+		return ""
+	}
+	return r
+}
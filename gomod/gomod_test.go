@@ -0,0 +1,320 @@
+package gomod
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Masterminds/glide/msg"
+)
+
+func TestTagVersion(t *testing.T) {
+	tests := []struct {
+		path, tag, ver string
+		ok             bool
+	}{
+		{"github.com/foo/bar", "v1.2.3", "v1.2.3", true},
+		{"github.com/foo/bar", "1.2.3", "", false},
+		{"github.com/foo/bar", "v1.2", "", false},
+		{"github.com/foo/bar", "v1.2.3+meta", "v1.2.3", true},
+		{"github.com/foo/bar", "v2.0.0", "v2.0.0+incompatible", true},
+		{"github.com/foo/bar/v2", "v2.1.0", "v2.1.0", true},
+		{"github.com/foo/bar/v2", "v1.1.0", "", false},
+		{"gopkg.in/yaml.v2", "v2.2.1", "v2.2.1", true},
+		{"gopkg.in/yaml", "v2.2.1", "", false},
+	}
+
+	for _, tt := range tests {
+		ver, ok := TagVersion(tt.path, tt.tag)
+		if ver != tt.ver || ok != tt.ok {
+			t.Errorf("TagVersion(%q, %q) = %q, %t; expected %q, %t", tt.path, tt.tag, ver, ok, tt.ver, tt.ok)
+		}
+	}
+}
+
+func TestPseudoVersion(t *testing.T) {
+	d := time.Date(2018, 5, 23, 9, 45, 22, 0, time.FixedZone("", 3600))
+	rev := "3864e76763d94a6df2f9960b16a20a33da9f9a66"
+	if v := PseudoVersion("github.com/foo/bar", d, rev); v != "v0.0.0-20180523084522-3864e76763d9" {
+		t.Errorf("Unexpected pseudo-version %s", v)
+	}
+	if v := PseudoVersion("github.com/foo/bar/v3", d, rev); v != "v3.0.0-20180523084522-3864e76763d9" {
+		t.Errorf("Unexpected pseudo-version %s", v)
+	}
+}
+
+func TestPathFromRepo(t *testing.T) {
+	tests := map[string]string{
+		"https://github.com/foo/bar.git":     "github.com/foo/bar",
+		"git@github.com:foo/bar.git":         "github.com/foo/bar",
+		"ssh://git@example.com:2222/foo/bar": "example.com/foo/bar",
+		"/home/foo/src/bar":                  "",
+	}
+	for in, expected := range tests {
+		if p := PathFromRepo(in); p != expected {
+			t.Errorf("PathFromRepo(%q) = %q; expected %q", in, p, expected)
+		}
+	}
+}
+
+func TestLocalPath(t *testing.T) {
+	tests := map[string]string{
+		"../bar":        "../bar",
+		"./bar":         "./bar",
+		"bar":           "./bar",
+		"/home/foo/bar": "/home/foo/bar",
+	}
+	for in, expected := range tests {
+		if p := LocalPath(in); p != expected {
+			t.Errorf("LocalPath(%q) = %q; expected %q", in, p, expected)
+		}
+	}
+}
+
+func TestMarshal(t *testing.T) {
+	f := &File{
+		Module: "github.com/example/app",
+		Go:     "1.12",
+		Require: []*Require{
+			{Path: "github.com/foo/bar", Version: "v1.2.3"},
+			{Path: "github.com/baz/qux", Version: "v0.0.0-20180523084522-3864e76763d9", Indirect: true},
+		},
+		Replace: []*Replace{
+			{Old: "github.com/foo/bar", New: "github.com/fork/bar", NewVersion: "v1.2.3"},
+		},
+	}
+
+	expected := `module github.com/example/app
+
+go 1.12
+
+require (
+	github.com/baz/qux v0.0.0-20180523084522-3864e76763d9 // indirect
+	github.com/foo/bar v1.2.3
+)
+
+replace (
+	github.com/foo/bar => github.com/fork/bar v1.2.3
+)
+`
+	if out := string(f.Marshal()); out != expected {
+		t.Errorf("Unexpected go.mod file:\n%s", out)
+	}
+}
+
+func TestHash(t *testing.T) {
+	// Expected values are from go.sum entries generated by the go tool.
+	mod := SynthesizeGoMod("github.com/mitchellh/go-homedir")
+	if h := HashGoMod(mod); h != "h1:SfyaCUpYCn1Vlf4IUYiD9fPX4A5wJrkLzIz1N1q0pr0=" {
+		t.Errorf("Unexpected go.mod hash %s", h)
+	}
+
+	dir, err := ioutil.TempDir("", "glide-gomod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"foo.go":                        "package foo\n",
+		"vendor/modules.txt":            "",
+		"vendor/example.com/bar/bar.go": "package bar\n",
+		"nested/go.mod":                 "module example.com/foo/nested\n",
+		"nested/nested.go":              "package nested\n",
+		".git/HEAD":                     "ref: refs/heads/master\n",
+		"internal/baz/baz.go":           "package baz\n",
+	}
+	for n, c := range files {
+		p := filepath.Join(dir, filepath.FromSlash(n))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(p, []byte(c), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	h1, err := HashDir(dir, "example.com/foo", "v1.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Excluded files do not contribute to the hash.
+	for _, n := range []string{"vendor/example.com", "nested", ".git"} {
+		if err := os.RemoveAll(filepath.Join(dir, filepath.FromSlash(n))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	h2, err := HashDir(dir, "example.com/foo", "v1.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h1 != h2 {
+		t.Errorf("Expected excluded files to be skipped but hash changed from %s to %s", h1, h2)
+	}
+
+	h3, err := HashDir(dir, "example.com/foo", "v1.0.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h1 == h3 {
+		t.Error("Expected the version to be part of the hash")
+	}
+}
+
+func TestHashNestedVendor(t *testing.T) {
+	dir, err := ioutil.TempDir("", "glide-gomod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// The go tool leaves out sub/vendor/y.go, directly inside a nested vendor
+	// directory, as well as vendored packages.
+	files := map[string]string{
+		"go.mod":          "module example.com/m.git\n\ngo 1.16\n",
+		"m.go":            "package m\n",
+		"sub/sub.go":      "package sub\n",
+		"sub/vendor/y.go": "package y\n",
+	}
+	for n, c := range files {
+		p := filepath.Join(dir, filepath.FromSlash(n))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(p, []byte(c), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The expected value is from go mod download of the module.
+	h, err := HashDir(dir, "example.com/m.git", "v1.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h != "h1:GRQLd+CpkBf3ku3Mb4cKyZxHXzxB/wrSt/HfFUUC6HE=" {
+		t.Errorf("Unexpected hash %s", h)
+	}
+	if h := HashGoMod([]byte(files["go.mod"])); h != "h1:e/jJUDPKGx3xtcLNFL2zodUj48K2DJ+skIKvY23F0WQ=" {
+		t.Errorf("Unexpected go.mod hash %s", h)
+	}
+
+	tests := map[string]bool{
+		"vendor/modules.txt":        false,
+		"vendor/example.com/x/x.go": true,
+		"sub/vendor/y.go":           true,
+		"sub/vendor/x/y.go":         true,
+		"vendors/x.go":              false,
+		"sub/x.go":                  false,
+	}
+	for name, expected := range tests {
+		if isVendoredPackage(name) != expected {
+			t.Errorf("Expected isVendoredPackage(%q) to be %t", name, expected)
+		}
+	}
+}
+
+func TestParseFile(t *testing.T) {
+	data := []byte(`module github.com/example/app // the app
+
+go 1.12
+
+require github.com/foo/bar v1.2.3
+require (
+	"github.com/baz/qux/v2" v2.0.1 // indirect
+	github.com/old/thing v0.0.0-20180523094522-3864e76763d9
+	github.com/incompat/lib v3.1.0+incompatible
+)
+
+exclude github.com/foo/bar v1.2.5
+
+replace (
+	github.com/foo/bar => github.com/fork/bar v1.2.4
+	github.com/old/thing v0.0.0-20180523094522-3864e76763d9 => ../thing
+)
+`)
+
+	f, err := ParseFile(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Module != "github.com/example/app" || f.Go != "1.12" {
+		t.Errorf("Unexpected module %q or go version %q", f.Module, f.Go)
+	}
+	if len(f.Require) != 4 || len(f.Replace) != 2 || len(f.Exclude) != 1 {
+		t.Fatalf("Expected 4 requires, 2 replaces, and 1 exclude but got %d, %d, and %d", len(f.Require), len(f.Replace), len(f.Exclude))
+	}
+	if r := f.Require[1]; r.Path != "github.com/baz/qux/v2" || r.Version != "v2.0.1" || !r.Indirect {
+		t.Errorf("Unexpected require %+v", r)
+	}
+	if r := f.Replace[1]; r.Old != "github.com/old/thing" || r.OldVersion == "" || r.New != "../thing" || r.NewVersion != "" {
+		t.Errorf("Unexpected replace %+v", r)
+	}
+
+	if _, err := ParseFile([]byte("require (\n\tgithub.com/foo/bar v1.0.0\n")); err == nil {
+		t.Error("Expected an error for an unterminated block")
+	}
+}
+
+func TestParse(t *testing.T) {
+	dir, err := ioutil.TempDir("", "glide-gomod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	data := `module github.com/example/app
+
+require (
+	github.com/foo/bar v1.2.3
+	github.com/baz/qux/v2 v2.0.1
+	github.com/baz/qux v1.4.0
+	github.com/old/thing v1.2.4-0.20180523094522-3864e76763d9
+	github.com/incompat/lib v3.1.0+incompatible
+	github.com/local/lib v1.0.0
+)
+
+exclude github.com/foo/bar v1.2.5
+
+replace github.com/foo/bar => github.com/fork/bar v1.2.4
+replace github.com/local/lib => ./lib
+`
+	if err := ioutil.WriteFile(filepath.Join(dir, "go.mod"), []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if !Has(dir) {
+		t.Fatal("Expected go.mod to be found")
+	}
+
+	var buf bytes.Buffer
+	stderr := msg.Default.Stderr
+	msg.Default.Stderr = &buf
+	deps, err := Parse(dir)
+	msg.Default.Stderr = stderr
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "Both github.com/baz/qux/v2 and github.com/baz/qux are required") {
+		t.Errorf("Expected a warning about the dropped major version but got %q", buf.String())
+	}
+	expected := []struct{ name, ref, repo string }{
+		{"github.com/foo/bar", "^1.2.4, !=1.2.5", "https://github.com/fork/bar"},
+		{"github.com/baz/qux", "^2.0.1", ""},
+		{"github.com/old/thing", "3864e76763d9", ""},
+		{"github.com/incompat/lib", "^3.1.0", ""},
+		{"github.com/local/lib", "^1.0.0", ""},
+	}
+	if len(deps) != len(expected) {
+		t.Fatalf("Expected %d dependencies but got %d", len(expected), len(deps))
+	}
+	for i, e := range expected {
+		d := deps[i]
+		if d.Name != e.name || d.Reference != e.ref || d.Repository != e.repo {
+			t.Errorf("Expected %s %q %q but got %s %q %q", e.name, e.ref, e.repo, d.Name, d.Reference, d.Repository)
+		}
+	}
+}
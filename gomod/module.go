@@ -0,0 +1,123 @@
+package gomod
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// majorElem matches the path element of a major version suffix, such as v2.
+// Modules at v0 and v1 have no suffix.
+var majorElem = regexp.MustCompile(`^v([2-9]|[1-9][0-9]+)$`)
+
+// ModulePath returns the module path declared in the go.mod file in a
+// directory. An empty string is returned when there is no go.mod file or it
+// cannot be parsed.
+func ModulePath(dir string) string {
+	data, err := ioutil.ReadFile(filepath.Join(dir, "go.mod"))
+	if err != nil {
+		return ""
+	}
+	f, err := ParseFile(data)
+	if err != nil {
+		return ""
+	}
+	return f.Module
+}
+
+// MajorModule reports whether a package is in a module with a major version
+// suffix in the repository of a root package checked out in dir. For example,
+// github.com/foo/bar/v2/baz is in the module github.com/foo/bar/v2 when the
+// go.mod file in the v2 directory declares it, a major subdirectory, or when
+// there is no v2 directory and the go.mod file at the root of the repository
+// declares one of its modules, a major branch.
+//
+// It returns the major version and the directory of the module relative to
+// dir, which is empty for a major branch. The major version is 0 when the
+// package is not in such a module.
+func MajorModule(dir, root, pkg string) (uint64, string) {
+	if !strings.HasPrefix(pkg, root+"/") || strings.HasPrefix(root, "gopkg.in/") {
+		return 0, ""
+	}
+	elem := strings.SplitN(strings.TrimPrefix(pkg, root+"/"), "/", 2)[0]
+	m := majorElem.FindStringSubmatch(elem)
+	if m == nil {
+		return 0, ""
+	}
+	major, err := strconv.ParseUint(m[1], 10, 64)
+	if err != nil {
+		return 0, ""
+	}
+
+	if ModulePath(filepath.Join(dir, elem)) == root+"/"+elem {
+		return major, elem
+	}
+	// The version checked out of a major branch may be another major version
+	// so any module of the repository will do when the suffix is not a
+	// directory.
+	if m := ModulePath(dir); m != "" && RepoName(m) == root && !isDir(filepath.Join(dir, elem)) {
+		return major, ""
+	}
+	return 0, ""
+}
+
+// SubpackageDir returns the directory, relative to the checkout of a root
+// package in dir, holding one of its subpackages. The major version suffix is
+// not a directory in a major branch so it is left out. This mirrors the
+// minimal module compatibility of the go tool outside of module mode.
+func SubpackageDir(dir, root, sub string) string {
+	if major, mdir := MajorModule(dir, root, root+"/"+sub); major > 0 && mdir == "" {
+		parts := strings.SplitN(sub, "/", 2)
+		if len(parts) == 1 {
+			return ""
+		}
+		return parts[1]
+	}
+	return sub
+}
+
+// ImportDir returns the path, relative to a directory holding packages by
+// their import paths such as a vendor directory, of the directory a package is
+// in. When the package is not found and a go.mod file above it declares the
+// module path with a major version suffix in the import path, the suffix is
+// left out as the go tool does outside of module mode.
+func ImportDir(base, pkg string) string {
+	if isDir(filepath.Join(base, filepath.FromSlash(pkg))) {
+		return pkg
+	}
+	elems := strings.Split(pkg, "/")
+	for i := 1; i < len(elems); i++ {
+		if !majorElem.MatchString(elems[i]) {
+			continue
+		}
+		prefix := path.Join(elems[:i]...)
+		if ModulePath(filepath.Join(base, filepath.FromSlash(prefix))) == path.Join(elems[:i+1]...) {
+			return path.Join(append([]string{prefix}, elems[i+1:]...)...)
+		}
+	}
+	return pkg
+}
+
+// MajorElem returns the major version suffix, such as v2, every subpackage of
+// a dependency is imported with. An empty string is returned when there is
+// none or the root package itself is imported.
+func MajorElem(subpackages []string) string {
+	elem := ""
+	for _, sp := range subpackages {
+		e := strings.SplitN(sp, "/", 2)[0]
+		if !majorElem.MatchString(e) || (elem != "" && e != elem) {
+			return ""
+		}
+		elem = e
+	}
+	return elem
+}
+
+func isDir(p string) bool {
+	fi, err := os.Stat(p)
+	return err == nil && fi.IsDir()
+}
@@ -0,0 +1,104 @@
+package gomod
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeModule(t *testing.T, dir, module string) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "go.mod"), []byte("module "+module+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMajorModule(t *testing.T) {
+	dir, err := ioutil.TempDir("", "glide-gomod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// A major subdirectory, a major branch, and a repository without modules.
+	sub := filepath.Join(dir, "sub")
+	writeModule(t, sub, "example.com/sub")
+	writeModule(t, filepath.Join(sub, "v2"), "example.com/sub/v2")
+	branch := filepath.Join(dir, "branch")
+	writeModule(t, branch, "example.com/branch/v3")
+	plain := filepath.Join(dir, "plain")
+	if err := os.MkdirAll(filepath.Join(plain, "v2"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		dir, root, pkg string
+		major          uint64
+		mdir, subdir   string
+	}{
+		{sub, "example.com/sub", "example.com/sub/v2/x", 2, "v2", "v2/x"},
+		{sub, "example.com/sub", "example.com/sub/x", 0, "", "x"},
+		{branch, "example.com/branch", "example.com/branch/v2/x", 2, "", "x"},
+		{branch, "example.com/branch", "example.com/branch/v3", 3, "", ""},
+		{plain, "example.com/plain", "example.com/plain/v2", 0, "", "v2"},
+		{branch, "gopkg.in/branch", "gopkg.in/branch/v2", 0, "", "v2"},
+	}
+	for _, tt := range tests {
+		major, mdir := MajorModule(tt.dir, tt.root, tt.pkg)
+		if major != tt.major || mdir != tt.mdir {
+			t.Errorf("MajorModule(%q) = %d, %q; expected %d, %q", tt.pkg, major, mdir, tt.major, tt.mdir)
+		}
+		sp := tt.pkg[len(tt.root)+1:]
+		if d := SubpackageDir(tt.dir, tt.root, sp); d != tt.subdir {
+			t.Errorf("SubpackageDir(%q) = %q; expected %q", sp, d, tt.subdir)
+		}
+	}
+}
+
+func TestImportDir(t *testing.T) {
+	dir, err := ioutil.TempDir("", "glide-gomod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	writeModule(t, filepath.Join(dir, "example.com", "branch"), "example.com/branch/v2")
+	writeModule(t, filepath.Join(dir, "example.com", "sub", "v2"), "example.com/sub/v2")
+	if err := os.MkdirAll(filepath.Join(dir, "example.com", "branch", "x"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]string{
+		"example.com/branch/v2/x": "example.com/branch/x",
+		"example.com/branch/v2":   "example.com/branch",
+		"example.com/branch/v3/x": "example.com/branch/v3/x",
+		"example.com/sub/v2":      "example.com/sub/v2",
+		"example.com/missing/v2":  "example.com/missing/v2",
+	}
+	for pkg, expected := range tests {
+		if d := ImportDir(dir, pkg); d != expected {
+			t.Errorf("ImportDir(%q) = %q; expected %q", pkg, d, expected)
+		}
+	}
+}
+
+func TestMajorElem(t *testing.T) {
+	tests := []struct {
+		subs []string
+		elem string
+	}{
+		{[]string{"v2", "v2/x"}, "v2"},
+		{[]string{"v2/x", "x"}, ""},
+		{[]string{"v2", "v3"}, ""},
+		{[]string{"v1/x"}, ""},
+		{nil, ""},
+	}
+	for _, tt := range tests {
+		if e := MajorElem(tt.subs); e != tt.elem {
+			t.Errorf("MajorElem(%v) = %q; expected %q", tt.subs, e, tt.elem)
+		}
+	}
+}
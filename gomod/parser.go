@@ -0,0 +1,273 @@
+package gomod
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/Masterminds/glide/cfg"
+	"github.com/Masterminds/glide/msg"
+	gpath "github.com/Masterminds/glide/path"
+)
+
+// Has returns true if this dir has a go.mod file.
+func Has(dir string) bool {
+	path := filepath.Join(dir, "go.mod")
+	fi, err := os.Stat(path)
+	return err == nil && !fi.IsDir()
+}
+
+// Parse parses a go.mod file.
+//
+// Required modules become dependencies. Tagged versions become a constraint
+// on the same major version, as the go tool treats them as minimums, while
+// pseudo-versions pin the commit. Replacements with another module set the
+// repository and version. Excluded versions are removed from constraints.
+// Dependencies are named after the root of their repository so only the first
+// of several major versions required from one repository is kept, with a
+// warning.
+func Parse(dir string) ([]*cfg.Dependency, error) {
+	path := filepath.Join(dir, "go.mod")
+	if fi, err := os.Stat(path); err != nil || fi.IsDir() {
+		return []*cfg.Dependency{}, nil
+	}
+
+	msg.Info("Found go.mod file in %s", gpath.StripBasepath(dir))
+	msg.Info("--> Parsing go.mod metadata...")
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return []*cfg.Dependency{}, err
+	}
+	f, err := ParseFile(data)
+	if err != nil {
+		return []*cfg.Dependency{}, fmt.Errorf("Unable to parse %s: %s", path, err)
+	}
+
+	buf := []*cfg.Dependency{}
+	seen := map[string]string{}
+	for _, r := range f.Require {
+		name := RepoName(r.Path)
+		if p, ok := seen[name]; ok {
+			msg.Warn("Both %s and %s are required from the repository %s. Only %s is used", p, r.Path, name, p)
+			continue
+		}
+		dep := &cfg.Dependency{
+			Name:      name,
+			Reference: VersionReference(r.Version),
+		}
+
+		for _, rep := range f.Replace {
+			if rep.Old != r.Path || (rep.OldVersion != "" && rep.OldVersion != r.Version) {
+				continue
+			}
+			if rep.NewVersion == "" {
+				msg.Warn("Skipping the replacement of %s with the local path %s", r.Path, rep.New)
+				break
+			}
+			if n := RepoName(rep.New); n != name {
+				dep.Repository = "https://" + n
+			}
+			dep.Reference = VersionReference(rep.NewVersion)
+			break
+		}
+
+		for _, e := range f.Exclude {
+			if e.Path == r.Path && isConstraint(dep.Reference) {
+				dep.Reference += ", !=" + strings.TrimPrefix(strings.TrimSuffix(e.Version, "+incompatible"), "v")
+			}
+		}
+
+		seen[name] = r.Path
+		buf = append(buf, dep)
+	}
+
+	return buf, nil
+}
+
+// RepoName converts a module path to the name of the package at the root of
+// its repository by dropping a /vN major version suffix. The gopkg.in .vN
+// suffix is part of the name and kept.
+func RepoName(path string) string {
+	if PathMajor(path) > 0 && !strings.HasPrefix(path, "gopkg.in/") {
+		return path[:strings.LastIndex(path, "/")]
+	}
+	return path
+}
+
+// VersionReference converts a module version to a Glide version reference.
+// Pseudo-versions are pinned to their commit while other versions become a
+// caret constraint.
+func VersionReference(v string) string {
+	v = strings.TrimSuffix(v, "+incompatible")
+	if rev := pseudoRevision(v); rev != "" {
+		return rev
+	}
+	return "^" + strings.TrimPrefix(v, "v")
+}
+
+func isConstraint(ref string) bool {
+	return strings.HasPrefix(ref, "^")
+}
+
+// pseudoRevision returns the commit of a pseudo-version, such as
+// v0.0.0-20180523094522-3864e76763d9, or an empty string for other versions.
+func pseudoRevision(v string) string {
+	i := strings.LastIndex(v, "-")
+	if i < 0 || strings.Count(v, "-") < 2 {
+		return ""
+	}
+	rev := v[i+1:]
+	ts := v[:i]
+	ts = ts[strings.LastIndexAny(ts, "-.")+1:]
+	if len(rev) != 12 || len(ts) != 14 {
+		return ""
+	}
+	for _, c := range ts {
+		if c < '0' || c > '9' {
+			return ""
+		}
+	}
+	return rev
+}
+
+// ParseFile parses the contents of a go.mod file. Directives other than
+// module, go, require, replace, and exclude are ignored.
+func ParseFile(data []byte) (*File, error) {
+	f := &File{}
+	block := ""
+	for n, line := range strings.Split(string(data), "\n") {
+		toks, comment, err := tokenize(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s", n+1, err)
+		}
+		if len(toks) == 0 {
+			continue
+		}
+
+		verb := block
+		if block == "" {
+			verb = toks[0]
+			toks = toks[1:]
+			if len(toks) == 1 && toks[0] == "(" {
+				block = verb
+				continue
+			}
+		} else if len(toks) == 1 && toks[0] == ")" {
+			block = ""
+			continue
+		}
+
+		switch verb {
+		case "module":
+			if len(toks) != 1 {
+				return nil, fmt.Errorf("line %d: usage: module module/path", n+1)
+			}
+			f.Module = toks[0]
+		case "go":
+			if len(toks) != 1 {
+				return nil, fmt.Errorf("line %d: usage: go 1.23", n+1)
+			}
+			f.Go = toks[0]
+		case "require":
+			if len(toks) != 2 {
+				return nil, fmt.Errorf("line %d: usage: require module/path v1.2.3", n+1)
+			}
+			f.Require = append(f.Require, &Require{
+				Path:     toks[0],
+				Version:  toks[1],
+				Indirect: strings.TrimSpace(comment) == "indirect",
+			})
+		case "exclude":
+			if len(toks) != 2 {
+				return nil, fmt.Errorf("line %d: usage: exclude module/path v1.2.3", n+1)
+			}
+			f.Exclude = append(f.Exclude, &Exclude{Path: toks[0], Version: toks[1]})
+		case "replace":
+			r, err := parseReplace(toks)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %s", n+1, err)
+			}
+			f.Replace = append(f.Replace, r)
+		}
+	}
+	if block != "" {
+		return nil, fmt.Errorf("unterminated %s block", block)
+	}
+
+	return f, nil
+}
+
+func parseReplace(toks []string) (*Replace, error) {
+	r := &Replace{}
+	i := 0
+	for ; i < len(toks) && toks[i] != "=>"; i++ {
+	}
+	switch i {
+	case 1:
+		r.Old = toks[0]
+	case 2:
+		r.Old, r.OldVersion = toks[0], toks[1]
+	default:
+		return nil, fmt.Errorf("usage: replace module/path [v1.2.3] => other/module v1.4.5")
+	}
+	switch len(toks) - i - 1 {
+	case 1:
+		r.New = toks[i+1]
+	case 2:
+		r.New, r.NewVersion = toks[i+1], toks[i+2]
+	default:
+		return nil, fmt.Errorf("usage: replace module/path [v1.2.3] => other/module v1.4.5")
+	}
+	return r, nil
+}
+
+// tokenize splits a go.mod line into its tokens and trailing comment.
+func tokenize(line string) ([]string, string, error) {
+	var toks []string
+	for {
+		line = strings.TrimLeftFunc(line, unicode.IsSpace)
+		switch {
+		case line == "":
+			return toks, "", nil
+		case strings.HasPrefix(line, "//"):
+			return toks, line[2:], nil
+		case line[0] == '(' || line[0] == ')':
+			toks = append(toks, line[:1])
+			line = line[1:]
+		case line[0] == '"' || line[0] == '`':
+			end := 1
+			for end < len(line) && line[end] != line[0] {
+				if line[0] == '"' && line[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(line) {
+				return nil, "", fmt.Errorf("unterminated quoted string")
+			}
+			s, err := strconv.Unquote(line[:end+1])
+			if err != nil {
+				return nil, "", err
+			}
+			toks = append(toks, s)
+			line = line[end+1:]
+		default:
+			end := strings.IndexFunc(line, func(r rune) bool {
+				return unicode.IsSpace(r) || r == '(' || r == ')' || r == '"'
+			})
+			if end < 0 {
+				end = len(line)
+			}
+			if i := strings.Index(line[:end], "//"); i > 0 {
+				end = i
+			}
+			toks = append(toks, line[:end])
+			line = line[end:]
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package gomod
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// HashDir generates the go.sum "h1:" hash of the module source in dir as it
+// would be published as module@version.
+//
+// The files included mirror the contents of a module zip file. VCS metadata,
+// nested modules (directories with their own go.mod), vendored packages, and
+// anything other than regular files are left out.
+func HashDir(dir, module, version string) (string, error) {
+	prefix := module + "@" + version
+	files := map[string]string{}
+	err := filepath.Walk(dir, func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+
+		if fi.IsDir() {
+			if path == dir {
+				return nil
+			}
+			switch fi.Name() {
+			case ".bzr", ".git", ".hg", ".svn":
+				return filepath.SkipDir
+			}
+			if _, err := os.Stat(filepath.Join(path, "go.mod")); err == nil {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if !fi.Mode().IsRegular() || isVendoredPackage(rel) {
+			return nil
+		}
+		files[prefix+"/"+rel] = path
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	names := make([]string, 0, len(files))
+	for n := range files {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+
+	h := sha256.New()
+	for _, n := range names {
+		f, err := os.Open(files[n])
+		if err != nil {
+			return "", err
+		}
+		fh := sha256.New()
+		_, err = io.Copy(fh, f)
+		f.Close()
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(h, "%x  %s\n", fh.Sum(nil), n)
+	}
+
+	return "h1:" + base64.StdEncoding.EncodeToString(h.Sum(nil)), nil
+}
+
+// HashGoMod generates the go.sum "h1:" hash of a go.mod file. When a module has
+// no go.mod file the go tool synthesizes one containing only the module
+// directive so pass in SynthesizeGoMod(module).
+func HashGoMod(data []byte) string {
+	fh := sha256.Sum256(data)
+	h := sha256.New()
+	fmt.Fprintf(h, "%x  go.mod\n", fh)
+	return "h1:" + base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
+
+// SynthesizeGoMod returns the go.mod file the go tool uses for a module that
+// does not have one.
+func SynthesizeGoMod(module string) []byte {
+	return []byte(fmt.Sprintf("module %s\n", quote(module)))
+}
+
+// isVendoredPackage reports whether a file belongs to a package in a vendor
+// directory. Files directly inside a vendor directory, such as
+// vendor/modules.txt, are not part of a package.
+//
+// This is a copy of the function in golang.org/x/mod/zip, including its
+// offset for nested vendor directories. It excludes files directly inside a
+// nested vendor directory too, but the go tool cannot fix it without changing
+// the hashes of existing modules so neither can we.
+func isVendoredPackage(name string) bool {
+	var i int
+	if strings.HasPrefix(name, "vendor/") {
+		i += len("vendor/")
+	} else if j := strings.Index(name, "/vendor/"); j >= 0 {
+		// The offset should be j + len("/vendor/"). See
+		// https://golang.org/issue/31562 and https://golang.org/issue/37397.
+		i += len("/vendor/")
+	} else {
+		return false
+	}
+	return strings.Contains(name[i:], "/")
+}
+
+// Sum represents the contents of a go.sum file. Each key is a module path
+// and version, with a /go.mod suffix for go.mod hashes, mapped to its hash.
+type Sum map[string]string
+
+// Add records the directory and go.mod hashes for module@version.
+func (s Sum) Add(module, version, dirHash, modHash string) {
+	if dirHash != "" {
+		s[module+" "+version] = dirHash
+	}
+	if modHash != "" {
+		s[module+" "+version+"/go.mod"] = modHash
+	}
+}
+
+// Marshal converts a Sum to the go.sum format with lines sorted the way the
+// go tool sorts them.
+func (s Sum) Marshal() []byte {
+	keys := make([]string, 0, len(s))
+	for k := range s {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b bytes.Buffer
+	for _, k := range keys {
+		fmt.Fprintf(&b, "%s %s\n", k, s[k])
+	}
+	return b.Bytes()
+}
+
+// WriteFile writes a go.sum file.
+//
+// If the file exists, it will be clobbered.
+func (s Sum) WriteFile(path string) error {
+	return ioutil.WriteFile(path, s.Marshal(), 0666)
+}
@@ -0,0 +1,128 @@
+// Package govendor provides basic importing of govendor's vendor.json files.
+//
+// This is not a complete implementation of govendor.
+package govendor
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Masterminds/glide/cfg"
+	"github.com/Masterminds/glide/msg"
+	gpath "github.com/Masterminds/glide/path"
+	"github.com/Masterminds/glide/util"
+)
+
+// File is the subset of govendor's vendor.json file used by Glide.
+type File struct {
+	RootPath string     `json:"rootPath"`
+	Ignore   string     `json:"ignore"`
+	Package  []*Package `json:"package"`
+}
+
+// Package is a vendored package in a vendor.json file.
+type Package struct {
+	// Path is the import path of the package.
+	Path string `json:"path"`
+
+	// Origin is where the package was fetched from when it differs from Path.
+	Origin string `json:"origin,omitempty"`
+
+	// Tree is true when the package and all of its sub-packages are vendored.
+	Tree bool `json:"tree,omitempty"`
+
+	Revision     string `json:"revision"`
+	Version      string `json:"version,omitempty"`
+	VersionExact string `json:"versionExact,omitempty"`
+}
+
+// Has returns true if this dir has a vendor/vendor.json file.
+func Has(dir string) bool {
+	path := filepath.Join(dir, "vendor/vendor.json")
+	fi, err := os.Stat(path)
+	return err == nil && !fi.IsDir()
+}
+
+// Parse parses a govendor vendor.json file.
+//
+// Packages are grouped by their repository root with the rest of their path
+// becoming a sub-package. Packages pinned to a revision use it. An origin in
+// another repository becomes the repository to fetch from. Packages vendored
+// as a tree need no special handling as Glide always fetches the complete
+// repository.
+func Parse(dir string) ([]*cfg.Dependency, error) {
+	path := filepath.Join(dir, "vendor/vendor.json")
+	if fi, err := os.Stat(path); err != nil || fi.IsDir() {
+		return []*cfg.Dependency{}, nil
+	}
+
+	msg.Info("Found govendor vendor.json file in %s", gpath.StripBasepath(dir))
+	msg.Info("--> Parsing govendor metadata...")
+	buf := []*cfg.Dependency{}
+	file, err := os.Open(path)
+	if err != nil {
+		return buf, err
+	}
+	defer file.Close()
+
+	f := File{}
+	if err := json.NewDecoder(file).Decode(&f); err != nil {
+		return buf, err
+	}
+
+	seen := map[string]*cfg.Dependency{}
+	for _, p := range f.Package {
+		pkg, sub := util.NormalizeName(p.Path)
+		dep, ok := seen[pkg]
+		if !ok {
+			dep = &cfg.Dependency{
+				Name:       pkg,
+				Reference:  reference(p),
+				Repository: repository(p, pkg),
+			}
+			seen[pkg] = dep
+			buf = append(buf, dep)
+		}
+		if len(sub) > 0 && !dep.HasSubpackage(sub) {
+			dep.Subpackages = append(dep.Subpackages, sub)
+		}
+	}
+
+	return buf, nil
+}
+
+func reference(p *Package) string {
+	if p.Revision != "" {
+		return p.Revision
+	}
+	if p.VersionExact != "" {
+		return p.VersionExact
+	}
+	return p.Version
+}
+
+// repository determines the location to fetch a package from when it has an
+// origin. Origins within another project's vendor directory cannot be
+// fetched on their own and are skipped.
+func repository(p *Package, pkg string) string {
+	if p.Origin == "" || p.Origin == p.Path {
+		return ""
+	}
+	if strings.Contains(p.Origin, "/vendor/") {
+		msg.Warn("Skipping the origin %s for %s as it is vendored in another project", p.Origin, p.Path)
+		return ""
+	}
+
+	// The origin is the package path at another location. Trim the package's
+	// sub-path to get the root of the origin repository.
+	root := p.Origin
+	if sub := strings.TrimPrefix(p.Path, pkg); sub != p.Path {
+		root = strings.TrimSuffix(root, sub)
+	}
+	if root == pkg {
+		return ""
+	}
+	return "https://" + root
+}
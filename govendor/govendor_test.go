@@ -0,0 +1,54 @@
+package govendor
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	dir, err := ioutil.TempDir("", "glide-govendor")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	data := `{
+	"comment": "",
+	"ignore": "test",
+	"package": [
+		{"path": "github.com/foo/bar", "revision": "3864e76763d94a6df2f9960b16a20a33da9f9a66", "tree": true},
+		{"path": "github.com/foo/baz/sub", "revision": "58046073cbffe2f25d425fe1331102f55cf719de", "origin": "github.com/fork/baz/sub"},
+		{"path": "github.com/foo/baz/other", "revision": "58046073cbffe2f25d425fe1331102f55cf719de", "origin": "github.com/fork/baz/other"},
+		{"path": "github.com/foo/qux", "revision": "b8bc1bf767474819792c23f32d8286a45736f1c6", "origin": "github.com/some/project/vendor/github.com/foo/qux"}
+	],
+	"rootPath": "github.com/example/app"
+}`
+	if err := os.MkdirAll(filepath.Join(dir, "vendor"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "vendor", "vendor.json"), []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if !Has(dir) {
+		t.Fatal("Expected vendor.json to be found")
+	}
+
+	deps, err := Parse(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deps) != 3 {
+		t.Fatalf("Expected 3 dependencies but got %d", len(deps))
+	}
+	if d := deps[0]; d.Name != "github.com/foo/bar" || d.Reference != "3864e76763d94a6df2f9960b16a20a33da9f9a66" || d.Repository != "" {
+		t.Errorf("Unexpected dependency %+v", d)
+	}
+	if d := deps[1]; d.Name != "github.com/foo/baz" || d.Repository != "https://github.com/fork/baz" || len(d.Subpackages) != 2 {
+		t.Errorf("Unexpected dependency %+v", d)
+	}
+	if d := deps[2]; d.Name != "github.com/foo/qux" || d.Repository != "" {
+		t.Errorf("Unexpected dependency %+v", d)
+	}
+}
@@ -1,21 +1,21 @@
-// Package importer imports dependency configuration from Glide, Godep, GPM, GB and gom
+// Package importer imports dependency configuration from Glide and other dependency managers.
+//
+// Importers register themselves by name. The configuration of a project can
+// choose which importers to use, and in what order, with the importers
+// setting in its glide.yaml file.
 package importer
 
 import (
-	"io/ioutil"
-	"os"
+	"fmt"
 	"path/filepath"
 
 	"github.com/Masterminds/glide/cfg"
-	"github.com/Masterminds/glide/gb"
-	"github.com/Masterminds/glide/godep"
-	"github.com/Masterminds/glide/gom"
-	"github.com/Masterminds/glide/gpm"
 )
 
 var i = &DefaultImporter{}
 
-// Import uses the DefaultImporter to import from Glide, Godep, GPM, GB and gom.
+// Import uses the DefaultImporter to import from the first of the registered
+// importers, in their default order, to find configuration.
 func Import(path string) (bool, []*cfg.Dependency, error) {
 	return i.Import(path)
 }
@@ -30,63 +30,100 @@ type Importer interface {
 	Import(path string) (bool, []*cfg.Dependency, error)
 }
 
-// DefaultImporter imports from Glide, Godep, GPM, GB and gom.
-type DefaultImporter struct{}
+// ManifestImporter imports from a single manifest file using functions like
+// those in the godep and gom packages.
+type ManifestImporter struct {
 
-// Import tries to import configuration from Glide, Godep, GPM, GB and gom.
-func (d *DefaultImporter) Import(path string) (bool, []*cfg.Dependency, error) {
+	// Manifest is the path to the manifest file relative to the package.
+	Manifest string
 
-	// Try importing from Glide first.
-	p := filepath.Join(path, "glide.yaml")
-	if _, err := os.Stat(p); err == nil {
-		// We found glide configuration.
-		yml, err := ioutil.ReadFile(p)
-		if err != nil {
-			return false, []*cfg.Dependency{}, err
-		}
-		conf, err := cfg.ConfigFromYaml(yml)
-		if err != nil {
-			return false, []*cfg.Dependency{}, err
-		}
-		return true, conf.Imports, nil
-	}
+	// Has returns true if a package has the manifest file.
+	Has func(dir string) bool
 
-	// Try importing from Godep
-	if godep.Has(path) {
-		deps, err := godep.Parse(path)
-		if err != nil {
-			return false, []*cfg.Dependency{}, err
-		}
-		return true, deps, nil
+	// Parse reads the dependencies from the manifest file.
+	Parse func(dir string) ([]*cfg.Dependency, error)
+}
+
+// Import imports the dependencies listed in the manifest file and records the
+// manifest as where each was imported from.
+func (m *ManifestImporter) Import(path string) (bool, []*cfg.Dependency, error) {
+	if !m.Has(path) {
+		return false, []*cfg.Dependency{}, nil
+	}
+	deps, err := m.Parse(path)
+	if err != nil {
+		return false, []*cfg.Dependency{}, err
+	}
+	for _, d := range deps {
+		d.ImportedFrom = filepath.Join(path, filepath.FromSlash(m.Manifest))
 	}
+	return true, deps, nil
+}
 
-	// Try importing from GPM
-	if gpm.Has(path) {
-		deps, err := gpm.Parse(path)
-		if err != nil {
-			return false, []*cfg.Dependency{}, err
+// DefaultImporter imports from the registered importers.
+type DefaultImporter struct {
+
+	// Names are the importers to use in the order to use them. When empty the
+	// registered importers are tried in the order they were registered and
+	// the first to find configuration is used.
+	Names []string
+}
+
+// New creates a DefaultImporter using the named importers in the given order.
+// An error is returned if an importer has not been registered.
+func New(names []string) (*DefaultImporter, error) {
+	for _, n := range names {
+		if Get(n) == nil {
+			return nil, fmt.Errorf("Unknown importer %s. Available importers are %v", n, Names())
 		}
-		return true, deps, nil
 	}
+	return &DefaultImporter{Names: names}, nil
+}
 
-	// Try importin from GB
-	if gb.Has(path) {
-		deps, err := gb.Parse(path)
-		if err != nil {
-			return false, []*cfg.Dependency{}, err
-		}
-		return true, deps, nil
+// Import tries each importer in order.
+//
+// When importers are named the configuration they find is merged. When more
+// than one finds a dependency the first one wins, so the order says which
+// manifest is authoritative. When none are named only the configuration from
+// the first importer to find any is used. The ImportedFrom property of each
+// dependency reports the manifest it came from.
+func (d *DefaultImporter) Import(path string) (bool, []*cfg.Dependency, error) {
+	names := d.Names
+	merge := len(names) > 0
+	if !merge {
+		names = Names()
 	}
 
-	// Try importing from gom
-	if gom.Has(path) {
-		deps, err := gom.Parse(path)
+	found := false
+	buf := []*cfg.Dependency{}
+	seen := map[string]bool{}
+	for _, n := range names {
+		imp := Get(n)
+		if imp == nil {
+			return false, []*cfg.Dependency{}, fmt.Errorf("Unknown importer %s", n)
+		}
+		f, deps, err := imp.Import(path)
 		if err != nil {
 			return false, []*cfg.Dependency{}, err
 		}
-		return true, deps, nil
+		if !f {
+			continue
+		}
+		found = true
+		for _, dep := range deps {
+			if seen[dep.Name] {
+				continue
+			}
+			seen[dep.Name] = true
+			if dep.ImportedFrom == "" {
+				dep.ImportedFrom = n
+			}
+			buf = append(buf, dep)
+		}
+		if !merge {
+			break
+		}
 	}
 
-	// When none are found.
-	return false, []*cfg.Dependency{}, nil
+	return found, buf, nil
 }
@@ -0,0 +1,130 @@
+package importer
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Masterminds/glide/cfg"
+)
+
+type testImporter struct {
+	deps []*cfg.Dependency
+}
+
+func (t *testImporter) Import(path string) (bool, []*cfg.Dependency, error) {
+	return len(t.deps) > 0, t.deps, nil
+}
+
+func TestDefaultImporter(t *testing.T) {
+	dir, err := ioutil.TempDir("", "glide-importer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	yml := `package: github.com/example/app
+import:
+- package: github.com/foo/bar
+  version: ^1.2.0
+`
+	if err := ioutil.WriteFile(filepath.Join(dir, "glide.yaml"), []byte(yml), 0644); err != nil {
+		t.Fatal(err)
+	}
+	Register("test", &testImporter{deps: []*cfg.Dependency{
+		{Name: "github.com/foo/bar", Reference: "master"},
+		{Name: "github.com/foo/baz", Reference: "v1.0.0"},
+	}})
+
+	if _, err := New([]string{"glide", "missing"}); err == nil {
+		t.Error("Expected an error for an unregistered importer")
+	}
+
+	// The first importer listed wins for a dependency both list.
+	imp, err := New([]string{"glide", "test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	found, deps, err := imp.Import(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !found || len(deps) != 2 {
+		t.Fatalf("Expected 2 dependencies to be found but got %d", len(deps))
+	}
+	if deps[0].Reference != "^1.2.0" || deps[0].ImportedFrom != filepath.Join(dir, "glide.yaml") {
+		t.Errorf("Expected github.com/foo/bar from glide.yaml but got %s from %s", deps[0].Reference, deps[0].ImportedFrom)
+	}
+	if deps[1].Name != "github.com/foo/baz" || deps[1].ImportedFrom != "test" {
+		t.Errorf("Expected github.com/foo/baz from test but got %s from %s", deps[1].Name, deps[1].ImportedFrom)
+	}
+
+	imp, err = New([]string{"test", "glide"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, deps, err = imp.Import(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deps[0].Reference != "master" {
+		t.Errorf("Expected the test importer to win but got version %s", deps[0].Reference)
+	}
+}
+
+func TestDefaultImporterFirstFound(t *testing.T) {
+	dir, err := ioutil.TempDir("", "glide-importer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	yml := `package: github.com/example/app
+import:
+- package: github.com/foo/bar
+  version: ^1.2.0
+`
+	if err := ioutil.WriteFile(filepath.Join(dir, "glide.yaml"), []byte(yml), 0644); err != nil {
+		t.Fatal(err)
+	}
+	godeps := `{"ImportPath": "github.com/example/app", "Deps": [{"ImportPath": "github.com/foo/baz", "Rev": "abc123"}]}`
+	if err := os.MkdirAll(filepath.Join(dir, "Godeps"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "Godeps", "Godeps.json"), []byte(godeps), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Without importers set only the first manifest found is used.
+	found, deps, err := Import(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !found || len(deps) != 1 || deps[0].Name != "github.com/foo/bar" {
+		t.Fatalf("Expected only github.com/foo/bar from glide.yaml but got %v", deps)
+	}
+
+	imp, err := New([]string{"glide", "godep"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, deps, err = imp.Import(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deps) != 2 || deps[1].Name != "github.com/foo/baz" {
+		t.Errorf("Expected the godep manifest to be merged but got %v", deps)
+	}
+}
+
+func TestDefaultOrder(t *testing.T) {
+	// The importers Glide has always had are tried first, in the same order.
+	expected := []string{"glide", "godep", "gpm", "gb", "gom"}
+	names := Names()
+	for i, n := range expected {
+		if i >= len(names) || names[i] != n {
+			t.Fatalf("Expected the default order to start with %v, got %v", expected, names)
+		}
+	}
+}
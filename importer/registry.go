@@ -0,0 +1,91 @@
+package importer
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/Masterminds/glide/cfg"
+	"github.com/Masterminds/glide/dep"
+	"github.com/Masterminds/glide/gb"
+	"github.com/Masterminds/glide/godep"
+	"github.com/Masterminds/glide/gom"
+	"github.com/Masterminds/glide/gomod"
+	"github.com/Masterminds/glide/govendor"
+	"github.com/Masterminds/glide/gpm"
+	"github.com/Masterminds/glide/vndr"
+)
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]Importer{}
+	order      []string
+)
+
+// The importers Glide has always had come first, in the order it always tried
+// them, so adding importers does not change which manifest existing projects
+// are read from.
+func init() {
+	Register("glide", &ManifestImporter{Manifest: "glide.yaml", Has: hasGlide, Parse: parseGlide})
+	Register("godep", &ManifestImporter{Manifest: "Godeps/Godeps.json", Has: godep.Has, Parse: godep.Parse})
+	Register("gpm", &ManifestImporter{Manifest: "Godeps", Has: gpm.Has, Parse: gpm.Parse})
+	Register("gb", &ManifestImporter{Manifest: "vendor/manifest", Has: gb.Has, Parse: gb.Parse})
+	Register("gom", &ManifestImporter{Manifest: "Gomfile", Has: gom.Has, Parse: gom.Parse})
+	Register("gomod", &ManifestImporter{Manifest: "go.mod", Has: gomod.Has, Parse: gomod.Parse})
+	Register("dep", &ManifestImporter{Manifest: "Gopkg.toml", Has: dep.Has, Parse: dep.Parse})
+	Register("govendor", &ManifestImporter{Manifest: "vendor/vendor.json", Has: govendor.Has, Parse: govendor.Parse})
+	Register("vndr", &ManifestImporter{Manifest: "vendor.conf", Has: vndr.Has, Parse: vndr.Parse})
+}
+
+// Register makes an importer available by name. Importers are used in the
+// order they are registered unless a project configures another order.
+//
+// Registering the same name twice panics.
+func Register(name string, imp Importer) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if imp == nil {
+		panic("importer: Register importer is nil")
+	}
+	if _, dup := registry[name]; dup {
+		panic(fmt.Sprintf("importer: Register called twice for importer %s", name))
+	}
+	registry[name] = imp
+	order = append(order, name)
+}
+
+// Get returns the importer registered with a name or nil if there is none.
+func Get(name string) Importer {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	return registry[name]
+}
+
+// Names returns the names of the registered importers in their default order.
+func Names() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	n := make([]string, len(order))
+	copy(n, order)
+	return n
+}
+
+func hasGlide(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, "glide.yaml"))
+	return err == nil
+}
+
+func parseGlide(dir string) ([]*cfg.Dependency, error) {
+	yml, err := ioutil.ReadFile(filepath.Join(dir, "glide.yaml"))
+	if err != nil {
+		return []*cfg.Dependency{}, err
+	}
+	conf, err := cfg.ConfigFromYaml(yml)
+	if err != nil {
+		return []*cfg.Dependency{}, err
+	}
+	return conf.Imports, nil
+}
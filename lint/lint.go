@@ -0,0 +1,492 @@
+// Package lint validates glide.yaml files.
+//
+// Problems are reported with the line and column they appear at. Loading a
+// glide.yaml file with the cfg package skips unknown fields and invalid VCS
+// types without notice while invalid versions only fail once a dependency is
+// being fetched. Linting catches these up front.
+package lint
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Masterminds/glide/cache"
+	"github.com/Masterminds/glide/cfg"
+	"github.com/Masterminds/glide/dependency"
+	"github.com/Masterminds/glide/importer"
+	"github.com/Masterminds/glide/msg"
+	gpath "github.com/Masterminds/glide/path"
+	"github.com/Masterminds/glide/util"
+	"github.com/Masterminds/semver"
+	"gopkg.in/yaml.v2"
+)
+
+// Severity describes how serious a problem is.
+type Severity string
+
+const (
+	// Error is a problem that will cause Glide to misbehave.
+	Error Severity = "error"
+
+	// Warning is a problem that may be intended.
+	Warning Severity = "warning"
+)
+
+// Diagnostic is a problem found in a glide.yaml file.
+type Diagnostic struct {
+	Position
+	Severity Severity `json:"severity"`
+	Message  string   `json:"message"`
+}
+
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("%d:%d: %s: %s", d.Line, d.Column, d.Severity, d.Message)
+}
+
+// The fields known for each part of a glide.yaml file.
+var (
+	configFields = map[string]bool{
+		"package":     true,
+		"extends":     true,
+		"description": true,
+		"homepage":    true,
+		"license":     true,
+		"owners":      true,
+		"ignore":      true,
+		"excludeDirs": true,
+		"importers":   true,
+		"import":      true,
+		"testImport":  true,
+		"groups":      true,
+		"tools":       true,
+		"prune":       true,
+	}
+	ownerFields = map[string]bool{
+		"name":     true,
+		"email":    true,
+		"homepage": true,
+	}
+	dependencyFields = map[string]bool{
+		"package":     true,
+		"version":     true,
+		"ref":         true,
+		"repo":        true,
+		"vcs":         true,
+		"path":        true,
+		"subpackages": true,
+		"arch":        true,
+		"os":          true,
+	}
+	pruneRuleFields = map[string]bool{
+		"unusedPackages": true,
+		"goTests":        true,
+		"testdata":       true,
+		"nonGoFiles":     true,
+	}
+	pruneFields           = withFields(pruneRuleFields, "dependencies")
+	pruneDependencyFields = withFields(pruneRuleFields, "package")
+)
+
+// withFields returns a copy of a set of known fields with more added.
+func withFields(known map[string]bool, more ...string) map[string]bool {
+	m := make(map[string]bool, len(known)+len(more))
+	for k := range known {
+		m[k] = true
+	}
+	for _, k := range more {
+		m[k] = true
+	}
+	return m
+}
+
+// Lint validates the contents of a glide.yaml file.
+//
+// The dir is the directory holding the file. It is used to find a license
+// file. Subpackages are checked against the repositories in the Glide cache
+// when they are present.
+func Lint(yml []byte, dir string) []Diagnostic {
+	l := &linter{pos: locate(yml), dir: dir}
+
+	var doc yaml.MapSlice
+	if err := yaml.Unmarshal(yml, &doc); err != nil {
+		l.add(yamlErrorPosition(err), Error, "%s", strings.TrimPrefix(err.Error(), "yaml: "))
+		return l.diags
+	}
+
+	for _, item := range doc {
+		key := fmt.Sprint(item.Key)
+		if !configFields[key] {
+			l.add(l.pos.Key(key), Error, "unknown field %q", key)
+			continue
+		}
+		switch key {
+		case "license":
+			l.license(key, item.Value)
+		case "owners":
+			for i, o := range l.list(key, item.Value) {
+				l.fields(join(key, strconv.Itoa(i)), o, ownerFields, "owner")
+			}
+		case "ignore", "excludeDirs":
+			l.strings(key, item.Value)
+		case "extends":
+			for i, e := range l.strings(key, item.Value) {
+				if _, err := cfg.ExtendsPath(e, l.dir, gpath.Home()); err != nil {
+					l.add(l.pos.Value(join(key, strconv.Itoa(i))), Error, "%s", err)
+				}
+			}
+		case "importers":
+			for i, n := range l.strings(key, item.Value) {
+				if importer.Get(n) == nil {
+					l.add(l.pos.Value(join(key, strconv.Itoa(i))), Error, "unknown importer %q, must be one of: %s", n, strings.Join(importer.Names(), ", "))
+				}
+			}
+		case "import", "testImport", "tools":
+			for i, d := range l.list(key, item.Value) {
+				l.dependency(join(key, strconv.Itoa(i)), d)
+			}
+		case "groups":
+			l.groups(key, item.Value)
+		case "prune":
+			l.prune(key, item.Value)
+		}
+	}
+
+	sort.SliceStable(l.diags, func(i, j int) bool {
+		if l.diags[i].Line != l.diags[j].Line {
+			return l.diags[i].Line < l.diags[j].Line
+		}
+		return l.diags[i].Column < l.diags[j].Column
+	})
+	return l.diags
+}
+
+type linter struct {
+	pos   *positions
+	dir   string
+	diags []Diagnostic
+}
+
+func (l *linter) add(p Position, s Severity, format string, args ...interface{}) {
+	l.diags = append(l.diags, Diagnostic{Position: p, Severity: s, Message: fmt.Sprintf(format, args...)})
+}
+
+// list returns the items of a sequence, reporting anything else.
+func (l *linter) list(path string, v interface{}) []interface{} {
+	if v == nil {
+		return nil
+	}
+	items, ok := v.([]interface{})
+	if !ok {
+		l.add(l.pos.Value(path), Error, "%s must be a list", path)
+	}
+	return items
+}
+
+// strings returns the items of a sequence of strings, reporting anything else.
+func (l *linter) strings(path string, v interface{}) []string {
+	var buf []string
+	for i, item := range l.list(path, v) {
+		s, ok := scalar(item)
+		if !ok {
+			l.add(l.pos.Value(join(path, strconv.Itoa(i))), Error, "%s must be a list of strings", path)
+			continue
+		}
+		buf = append(buf, s)
+	}
+	return buf
+}
+
+// fields returns a mapping as a map, reporting unknown fields.
+func (l *linter) fields(path string, v interface{}, known map[string]bool, what string) map[string]interface{} {
+	m := map[string]interface{}{}
+	items, ok := v.(yaml.MapSlice)
+	if !ok {
+		l.add(l.pos.Value(path), Error, "each %s must be a mapping", what)
+		return m
+	}
+	for _, item := range items {
+		key := fmt.Sprint(item.Key)
+		if !known[key] {
+			l.add(l.pos.Key(join(path, key)), Error, "unknown field %q in %s", key, what)
+			continue
+		}
+		m[key] = item.Value
+	}
+	return m
+}
+
+// groups checks each group has a usable name and lints its dependencies.
+func (l *linter) groups(path string, v interface{}) {
+	if v == nil {
+		return
+	}
+	groups, ok := v.(yaml.MapSlice)
+	if !ok {
+		l.add(l.pos.Value(path), Error, "%s must be a mapping of group names to dependencies", path)
+		return
+	}
+	for _, g := range groups {
+		name := fmt.Sprint(g.Key)
+		gpath := join(path, name)
+		if name == cfg.ImportGroup || name == cfg.TestImportGroup {
+			l.add(l.pos.Key(gpath), Error, "%q cannot be used as a group name", name)
+		}
+		for i, d := range l.list(gpath, g.Value) {
+			l.dependency(join(gpath, strconv.Itoa(i)), d)
+		}
+	}
+}
+
+// prune checks the prune rules and the rules listed for each dependency.
+func (l *linter) prune(path string, v interface{}) {
+	if v == nil {
+		return
+	}
+	m := l.fields(path, v, pruneFields, "prune section")
+	l.pruneRules(path, m)
+
+	seen := map[string]bool{}
+	for i, d := range l.list(join(path, "dependencies"), m["dependencies"]) {
+		dpath := join(path, "dependencies", strconv.Itoa(i))
+		dm := l.fields(dpath, d, pruneDependencyFields, "prune dependency")
+		l.pruneRules(dpath, dm)
+		name, ok := scalar(dm["package"])
+		if !ok || name == "" {
+			if _, isMap := d.(yaml.MapSlice); isMap {
+				l.add(l.pos.Key(dpath), Error, "prune dependency is missing a package name")
+			}
+			continue
+		}
+		if seen[name] {
+			l.add(l.pos.Value(join(dpath, "package")), Warning, "prune rules for %s are listed more than once, only the first are used", name)
+		}
+		seen[name] = true
+	}
+}
+
+// pruneRules checks the rules in a prune mapping are booleans.
+func (l *linter) pruneRules(path string, m map[string]interface{}) {
+	for k := range pruneRuleFields {
+		v, ok := m[k]
+		if !ok {
+			continue
+		}
+		if _, isBool := v.(bool); !isBool {
+			l.add(l.pos.Value(join(path, k)), Error, "%s must be true or false", k)
+		}
+	}
+}
+
+func (l *linter) license(path string, v interface{}) {
+	lic, ok := scalar(v)
+	if !ok {
+		l.add(l.pos.Value(path), Error, "license must be a string")
+		return
+	}
+	if lic == "" {
+		return
+	}
+
+	// The license may be a path to a file containing the license.
+	if fi, err := os.Stat(filepath.Join(l.dir, filepath.FromSlash(lic))); err == nil && !fi.IsDir() {
+		return
+	}
+
+	unknown, err := spdxExpression(lic)
+	if err != nil {
+		l.add(l.pos.Value(path), Error, "license %q is not a valid SPDX license expression or a license file: %s", lic, err)
+		return
+	}
+	for _, u := range unknown {
+		l.add(l.pos.Value(path), Warning, "license %q is not a known SPDX license identifier", u)
+	}
+}
+
+var constraintChars = regexp.MustCompile(`[\^~<>=|,* ]|^v?[0-9]+(\.[0-9xX*]+)*$`)
+
+func (l *linter) dependency(path string, v interface{}) {
+	if _, ok := v.(yaml.MapSlice); !ok {
+		l.add(l.pos.Value(path), Error, "each dependency must be a mapping")
+		return
+	}
+	m := l.fields(path, v, dependencyFields, "dependency")
+
+	name, ok := scalar(m["package"])
+	if !ok || name == "" {
+		l.add(l.pos.Key(path), Error, "dependency is missing a package name")
+		return
+	}
+	d := &cfg.Dependency{}
+	d.Name, _ = util.NormalizeName(name)
+
+	for _, key := range []string{"version", "ref"} {
+		if m[key] == nil {
+			continue
+		}
+		ver, ok := scalar(m[key])
+		if !ok {
+			l.add(l.pos.Value(join(path, key)), Error, "%s must be a string", key)
+			continue
+		}
+		// Branches, tags, and commits cannot contain the characters used by
+		// constraints so anything that looks like one must be one.
+		if constraintChars.MatchString(ver) {
+			if _, err := semver.NewConstraint(ver); err != nil {
+				l.add(l.pos.Value(join(path, key)), Error, "invalid version constraint %q for %s: %s", ver, d.Name, err)
+			}
+		}
+	}
+
+	if m["vcs"] != nil {
+		t, _ := scalar(m["vcs"])
+		switch t {
+		case "git", "hg", "bzr", "svn", "mercurial", "bazaar", "subversion":
+			d.VcsType = t
+		default:
+			l.add(l.pos.Value(join(path, "vcs")), Error, "unknown VCS type %q for %s, must be one of: git, hg, bzr, svn", t, d.Name)
+		}
+	}
+
+	if m["repo"] != nil {
+		r, _ := scalar(m["repo"])
+		if err := validRepo(r); err != nil {
+			l.add(l.pos.Value(join(path, "repo")), Error, "malformed repo %q for %s: %s", r, d.Name, err)
+		} else {
+			d.Repository = r
+		}
+	}
+
+	if m["path"] != nil {
+		p, _ := scalar(m["path"])
+		d.Path = p
+		lp := filepath.FromSlash(p)
+		if !filepath.IsAbs(lp) {
+			lp = filepath.Join(l.dir, lp)
+		}
+		if fi, err := os.Stat(lp); err != nil || !fi.IsDir() {
+			l.add(l.pos.Value(join(path, "path")), Error, "local path %q for %s is not a directory", p, d.Name)
+			d.Path = ""
+		}
+	}
+
+	for i, o := range l.strings(join(path, "os"), m["os"]) {
+		if !dependency.IsSupportedOs(o) {
+			l.add(l.pos.Value(join(path, "os", strconv.Itoa(i))), Error, "unsupported operating system %q for %s", o, d.Name)
+		}
+	}
+	for i, a := range l.strings(join(path, "arch"), m["arch"]) {
+		if !dependency.IsSupportedArch(a) {
+			l.add(l.pos.Value(join(path, "arch", strconv.Itoa(i))), Error, "unsupported architecture %q for %s", a, d.Name)
+		}
+	}
+
+	subs := map[string]string{}
+	if _, sub := util.NormalizeName(name); sub != "" {
+		subs[join(path, "package")] = sub
+	}
+	for i, s := range l.strings(join(path, "subpackages"), m["subpackages"]) {
+		subs[join(path, "subpackages", strconv.Itoa(i))] = strings.TrimPrefix(s, "/")
+	}
+	if len(subs) > 0 {
+		l.subpackages(d, subs)
+	}
+}
+
+// subpackages checks that subpackages exist in the local path override or the
+// cached copy of a repository. Nothing is fetched so repositories not in the
+// cache are skipped.
+func (l *linter) subpackages(d *cfg.Dependency, subs map[string]string) {
+	where := "cached repository"
+	var cdir string
+	if d.Path != "" {
+		where = "local path"
+		cdir = filepath.FromSlash(d.Path)
+		if !filepath.IsAbs(cdir) {
+			cdir = filepath.Join(l.dir, cdir)
+		}
+	} else {
+		key, err := cache.Key(d.Remote())
+		if err != nil {
+			return
+		}
+		cdir = filepath.Join(cache.Location(), "src", key)
+		if _, err := os.Stat(cdir); err != nil {
+			msg.Debug("%s is not in the cache. Skipping its subpackage checks", d.Name)
+			return
+		}
+	}
+
+	paths := make([]string, 0, len(subs))
+	for p := range subs {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	for _, p := range paths {
+		sub := subs[p]
+		if fi, err := os.Stat(filepath.Join(cdir, filepath.FromSlash(sub))); err != nil || !fi.IsDir() {
+			l.add(l.pos.Value(p), Warning, "subpackage %q does not exist in the %s for %s", sub, where, d.Name)
+		}
+	}
+}
+
+var scpLike = regexp.MustCompile(`^[A-Za-z0-9_.-]+@[A-Za-z0-9_.-]+:[^/]`)
+
+// validRepo checks a repository location. It may be a URL, an scp-like
+// address such as git@github.com:Masterminds/glide.git, or a local path.
+func validRepo(r string) error {
+	if r == "" {
+		return fmt.Errorf("empty location")
+	}
+	if scpLike.MatchString(r) {
+		return nil
+	}
+	u, err := url.Parse(r)
+	if err != nil {
+		return err
+	}
+	switch u.Scheme {
+	case "":
+		if filepath.IsAbs(r) {
+			return nil
+		}
+		return fmt.Errorf("missing a scheme such as https://")
+	case "file":
+		return nil
+	case "http", "https", "git", "ssh", "git+ssh", "svn", "svn+ssh", "bzr", "bzr+ssh":
+	default:
+		return fmt.Errorf("unsupported scheme %q", u.Scheme)
+	}
+	if u.Host == "" {
+		return fmt.Errorf("missing a host")
+	}
+	return nil
+}
+
+// scalar returns a scalar value as a string.
+func scalar(v interface{}) (string, bool) {
+	switch t := v.(type) {
+	case nil:
+		return "", true
+	case string:
+		return t, true
+	case int, int64, uint64, float64, bool:
+		return fmt.Sprint(t), true
+	}
+	return "", false
+}
+
+var yamlLine = regexp.MustCompile(`line ([0-9]+)`)
+
+func yamlErrorPosition(err error) Position {
+	if m := yamlLine.FindStringSubmatch(err.Error()); m != nil {
+		n, _ := strconv.Atoi(m[1])
+		return Position{Line: n, Column: 1}
+	}
+	return Position{Line: 1, Column: 1}
+}
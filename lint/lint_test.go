@@ -0,0 +1,151 @@
+package lint
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	gpath "github.com/Masterminds/glide/path"
+)
+
+const testYaml = `package: github.com/example/app
+license: MIT License
+owners:
+- name: Example
+  emial: example@example.com
+importers:
+- glide
+- glode
+import:
+- package: github.com/foo/bar
+  version: ^1.2.3.4
+  vcs: got
+  repo: github.com/fork/bar
+- package: github.com/foo/baz
+  version: master
+  repo: https://github.com/fork/baz.git
+  os:
+  - linux
+  - plan10
+  arch: [amd64, "z80"]
+testImport:
+- package: github.com/foo/qux
+  versoin: ~1.0.0
+  path: ../does-not-exist
+flavor: vanilla
+groups:
+  import:
+  - package: github.com/foo/tool
+    vcs: got
+extends:
+- does-not-exist.yaml
+`
+
+func TestLint(t *testing.T) {
+	diags := Lint([]byte(testYaml), ".")
+
+	expected := []string{
+		"2:10: error: license \"MIT License\"",
+		"5:3: error: unknown field \"emial\" in owner",
+		"8:3: error: unknown importer \"glode\"",
+		"11:12: error: invalid version constraint \"^1.2.3.4\"",
+		"12:8: error: unknown VCS type \"got\"",
+		"13:9: error: malformed repo \"github.com/fork/bar\"",
+		"19:5: error: unsupported operating system \"plan10\"",
+		"20:17: error: unsupported architecture \"z80\"",
+		"23:3: error: unknown field \"versoin\" in dependency",
+		"24:9: error: local path \"../does-not-exist\" for github.com/foo/qux is not a directory",
+		"25:1: error: unknown field \"flavor\"",
+		"27:3: error: \"import\" cannot be used as a group name",
+		"29:10: error: unknown VCS type \"got\" for github.com/foo/tool",
+		"31:3: error: Unable to find the extended file does-not-exist.yaml in . or " + gpath.Home(),
+	}
+	if len(diags) != len(expected) {
+		for _, d := range diags {
+			t.Log(d)
+		}
+		t.Fatalf("Expected %d problems but got %d", len(expected), len(diags))
+	}
+	for i, e := range expected {
+		if !strings.HasPrefix(diags[i].String(), e) {
+			t.Errorf("Expected a problem starting with %q but got %q", e, diags[i].String())
+		}
+	}
+}
+
+func TestLintLicense(t *testing.T) {
+	dir, err := ioutil.TempDir("", "glide-lint")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err := ioutil.WriteFile(filepath.Join(dir, "LICENSE.txt"), []byte("license"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]int{
+		"MIT":                                   0,
+		"(MIT OR Apache-2.0) AND BSD-3-Clause":  0,
+		"GPL-2.0+ WITH Classpath-exception-2.0": 0,
+		"LicenseRef-Proprietary":                0,
+		"LICENSE.txt":                           0,
+		"MIT OR":                                1,
+		"(MIT":                                  1,
+		"Not-A-License":                         1,
+	}
+	for lic, n := range tests {
+		diags := Lint([]byte("package: github.com/example/app\nlicense: "+lic+"\nimport: []\n"), dir)
+		if len(diags) != n {
+			t.Errorf("Expected %d problems for license %q but got %v", n, lic, diags)
+		}
+	}
+}
+
+func TestLintSyntax(t *testing.T) {
+	diags := Lint([]byte("package: github.com/example/app\nimport:\n- package: a\n  version: [\n"), ".")
+	if len(diags) != 1 || diags[0].Severity != Error || diags[0].Line < 4 {
+		t.Errorf("Expected a syntax error on line 4 or later but got %v", diags)
+	}
+}
+
+func TestLintPrune(t *testing.T) {
+	yml := `package: github.com/example/app
+import:
+- package: github.com/foo/bar
+prune:
+  goTests: true
+  nonGoFiles: yes please
+  unused: true
+  dependencies:
+  - package: github.com/foo/bar
+    testdata: true
+  - goTests: true
+  - package: github.com/foo/bar
+`
+	diags := Lint([]byte(yml), ".")
+
+	expected := []string{
+		"6:15: error: nonGoFiles must be true or false",
+		"7:3: error: unknown field \"unused\" in prune section",
+		"11:3: error: prune dependency is missing a package name",
+		"12:14: warning: prune rules for github.com/foo/bar are listed more than once",
+	}
+	if len(diags) != len(expected) {
+		for _, d := range diags {
+			t.Log(d)
+		}
+		t.Fatalf("Expected %d problems but got %d", len(expected), len(diags))
+	}
+	for i, e := range expected {
+		if !strings.HasPrefix(diags[i].String(), e) {
+			t.Errorf("Expected a problem starting with %q but got %q", e, diags[i].String())
+		}
+	}
+
+	valid := "package: github.com/example/app\nimport: []\nprune:\n  goTests: true\n  dependencies:\n  - package: github.com/foo/bar\n"
+	if diags := Lint([]byte(valid), "."); len(diags) != 0 {
+		t.Errorf("Expected a valid prune section to pass but got %v", diags)
+	}
+}
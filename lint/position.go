@@ -0,0 +1,241 @@
+package lint
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Position is a line and column in a file. Both start at 1.
+type Position struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// positions maps paths within a YAML document, such as "import.2.version", to
+// where their keys and values appear.
+//
+// It understands the block style used by glide.yaml files. The items of a flow
+// sequence on a single line, such as [linux, darwin], are located too. Other
+// values in flow style are located by the position of their key.
+type positions struct {
+	keys   map[string]Position
+	values map[string]Position
+}
+
+type container struct {
+	indent  int
+	seq     bool
+	path    string
+	items   int
+	pending string
+
+	// fresh is set for a mapping in a sequence item that starts on the next
+	// line. Its indent is set by the first key.
+	fresh bool
+}
+
+// join joins the parts of a path such as "import", "2", and "version".
+func join(parts ...string) string {
+	buf := parts[:0:0]
+	for _, p := range parts {
+		if p != "" {
+			buf = append(buf, p)
+		}
+	}
+	return strings.Join(buf, ".")
+}
+
+func locate(yml []byte) *positions {
+	p := &positions{
+		keys:   map[string]Position{},
+		values: map[string]Position{},
+	}
+
+	stack := []*container{{indent: 0}}
+	top := func() *container { return stack[len(stack)-1] }
+	block := -1
+
+	for n, line := range strings.Split(string(yml), "\n") {
+		trimmed := strings.TrimLeft(line, " ")
+		col := len(line) - len(trimmed)
+		content := strings.TrimRight(trimmed, " \t\r")
+
+		// Skip the contents of multi-line scalars.
+		if block >= 0 {
+			if content == "" || col > block {
+				continue
+			}
+			block = -1
+		}
+		if content == "" || strings.HasPrefix(content, "#") || content == "---" {
+			continue
+		}
+
+		// Sequence items, which may be nested on a single line.
+		for content == "-" || strings.HasPrefix(content, "- ") {
+			t := top()
+			if !t.seq && t.pending != "" && col >= t.indent {
+				stack = append(stack, &container{indent: col, seq: true, path: join(t.path, t.pending)})
+				t.pending = ""
+			} else {
+				for len(stack) > 1 && (top().indent > col || (top().indent == col && !top().seq)) {
+					stack = stack[:len(stack)-1]
+				}
+			}
+			t = top()
+			if !t.seq {
+				// A sequence where one is not expected. Nothing to record.
+				content = ""
+				break
+			}
+			item := join(t.path, strconv.Itoa(t.items))
+			t.items++
+			p.keys[item] = Position{Line: n + 1, Column: col + 1}
+
+			rest := strings.TrimLeft(content[1:], " ")
+			rcol := col + len(content) - len(rest)
+			switch {
+			case rest == "" || strings.HasPrefix(rest, "#"):
+				stack = append(stack, &container{indent: col + 1, path: item, fresh: true})
+				content = ""
+			case strings.HasPrefix(rest, "- "):
+				stack = append(stack, &container{indent: rcol, seq: true, path: item})
+				col, content = rcol, rest
+			default:
+				if _, _, ok := splitKey(rest); ok {
+					stack = append(stack, &container{indent: rcol, path: item})
+				} else {
+					p.values[item] = Position{Line: n + 1, Column: rcol + 1}
+					p.flow(item, n+1, rcol, rest)
+					rest = ""
+				}
+				col, content = rcol, rest
+			}
+		}
+		if content == "" {
+			continue
+		}
+
+		key, vcol, ok := splitKey(content)
+		if !ok {
+			continue
+		}
+		t := top()
+		switch {
+		case t.fresh && col >= t.indent:
+			t.indent = col
+			t.fresh = false
+		case !t.seq && t.pending != "" && col > t.indent:
+			stack = append(stack, &container{indent: col, path: join(t.path, t.pending)})
+			t.pending = ""
+		default:
+			for len(stack) > 1 && (top().indent > col || (top().indent == col && top().seq)) {
+				stack = stack[:len(stack)-1]
+			}
+		}
+		t = top()
+		if t.seq {
+			continue
+		}
+		t.pending = ""
+		path := join(t.path, key)
+		p.keys[path] = Position{Line: n + 1, Column: col + 1}
+
+		value := strings.TrimLeft(content[vcol:], " \t")
+		vpos := Position{Line: n + 1, Column: col + len(content) - len(value) + 1}
+		switch {
+		case value == "" || strings.HasPrefix(value, "#"):
+			t.pending = key
+		case value[0] == '|' || value[0] == '>':
+			p.values[path] = vpos
+			block = col
+		default:
+			p.values[path] = vpos
+			p.flow(path, n+1, vpos.Column-1, value)
+		}
+	}
+
+	return p
+}
+
+// flow records the positions of the items of a flow sequence, such as
+// [linux, darwin], starting at col on a line. Only plain and quoted scalars
+// are located. It stops at anything nested or at the end of the line.
+func (p *positions) flow(path string, line, col int, s string) {
+	if s[0] != '[' {
+		return
+	}
+	for i, n := 1, 0; i < len(s); n++ {
+		for i < len(s) && (s[i] == ' ' || s[i] == '\t') {
+			i++
+		}
+		if i == len(s) || s[i] == ']' || s[i] == '[' || s[i] == '{' || s[i] == '#' {
+			return
+		}
+		start := i
+		if s[i] == '"' || s[i] == '\'' {
+			end := strings.IndexByte(s[i+1:], s[i])
+			if end < 0 {
+				return
+			}
+			i += end + 2
+		}
+		for i < len(s) && s[i] != ',' && s[i] != ']' {
+			i++
+		}
+		if i > start && s[start] != ',' {
+			p.values[join(path, strconv.Itoa(n))] = Position{Line: line, Column: col + start + 1}
+		}
+		if i == len(s) || s[i] == ']' {
+			return
+		}
+		i++
+	}
+}
+
+// splitKey splits "key: value" returning the key and the offset of the value.
+func splitKey(s string) (string, int, bool) {
+	if s[0] == '"' || s[0] == '\'' {
+		end := strings.IndexByte(s[1:], s[0])
+		if end < 0 || !strings.HasPrefix(s[end+2:], ":") {
+			return "", 0, false
+		}
+		return s[1 : end+1], end + 3, true
+	}
+	if s[0] == '[' || s[0] == '{' {
+		return "", 0, false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] == ':' && (i+1 == len(s) || s[i+1] == ' ' || s[i+1] == '\t') {
+			return s[:i], i + 1, true
+		}
+		if s[i] == '#' && i > 0 && s[i-1] == ' ' {
+			return "", 0, false
+		}
+	}
+	return "", 0, false
+}
+
+// Key returns the position of the key for a path. When the path is not found
+// its closest ancestor is used.
+func (p *positions) Key(path string) Position {
+	for {
+		if pos, ok := p.keys[path]; ok {
+			return pos
+		}
+		i := strings.LastIndex(path, ".")
+		if i < 0 {
+			return Position{Line: 1, Column: 1}
+		}
+		path = path[:i]
+	}
+}
+
+// Value returns the position of the value for a path, falling back to the
+// position of its key.
+func (p *positions) Value(path string) Position {
+	if pos, ok := p.values[path]; ok {
+		return pos
+	}
+	return p.Key(path)
+}
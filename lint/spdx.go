@@ -0,0 +1,123 @@
+package lint
+
+import (
+	"fmt"
+	"strings"
+)
+
+// spdxLicenses contains commonly used identifiers from the SPDX license list
+// at https://spdx.org/licenses/. Identifiers not found here are reported as
+// warnings rather than errors as the list is not exhaustive.
+var spdxLicenses = map[string]bool{}
+
+func init() {
+	ids := `0BSD AAL AFL-1.1 AFL-1.2 AFL-2.0 AFL-2.1 AFL-3.0 AGPL-1.0 AGPL-1.0-only
+	AGPL-1.0-or-later AGPL-3.0 AGPL-3.0-only AGPL-3.0-or-later APL-1.0 APSL-1.0
+	APSL-1.1 APSL-1.2 APSL-2.0 Apache-1.0 Apache-1.1 Apache-2.0 Artistic-1.0
+	Artistic-1.0-Perl Artistic-2.0 BSD-1-Clause BSD-2-Clause BSD-2-Clause-FreeBSD
+	BSD-2-Clause-NetBSD BSD-2-Clause-Patent BSD-3-Clause BSD-3-Clause-Attribution
+	BSD-3-Clause-Clear BSD-3-Clause-LBNL BSD-3-Clause-No-Nuclear-License
+	BSD-4-Clause BSD-Protection BSD-Source-Code BSL-1.0 BlueOak-1.0.0 CC-BY-1.0
+	CC-BY-2.0 CC-BY-2.5 CC-BY-3.0 CC-BY-4.0 CC-BY-NC-4.0 CC-BY-NC-SA-4.0
+	CC-BY-ND-4.0 CC-BY-SA-3.0 CC-BY-SA-4.0 CC0-1.0 CDDL-1.0 CDDL-1.1 CECILL-2.0
+	CECILL-2.1 CECILL-B CECILL-C CPAL-1.0 CPL-1.0 ECL-1.0 ECL-2.0 EFL-1.0 EFL-2.0
+	EPL-1.0 EPL-2.0 EUPL-1.0 EUPL-1.1 EUPL-1.2 GFDL-1.1 GFDL-1.2 GFDL-1.3 GPL-1.0
+	GPL-1.0+ GPL-1.0-only GPL-1.0-or-later GPL-2.0 GPL-2.0+ GPL-2.0-only
+	GPL-2.0-or-later GPL-2.0-with-classpath-exception GPL-3.0 GPL-3.0+
+	GPL-3.0-only GPL-3.0-or-later ISC LGPL-2.0 LGPL-2.0+ LGPL-2.0-only
+	LGPL-2.0-or-later LGPL-2.1 LGPL-2.1+ LGPL-2.1-only LGPL-2.1-or-later LGPL-3.0
+	LGPL-3.0+ LGPL-3.0-only LGPL-3.0-or-later LPL-1.0 LPL-1.02 LPPL-1.3c MIT
+	MIT-0 MIT-CMU MIT-advertising MIT-enna MIT-feh MPL-1.0 MPL-1.1 MPL-2.0
+	MPL-2.0-no-copyleft-exception MS-PL MS-RL MirOS MulanPSL-2.0 NCSA NPL-1.0
+	NPL-1.1 ODbL-1.0 OFL-1.0 OFL-1.1 OSL-1.0 OSL-1.1 OSL-2.0 OSL-2.1 OSL-3.0
+	OpenSSL PHP-3.0 PHP-3.01 PostgreSQL Python-2.0 QPL-1.0 RPL-1.1 RPL-1.5
+	RPSL-1.0 Ruby SISSL SPL-1.0 SSPL-1.0 Sleepycat UPL-1.0 Unicode-DFS-2016
+	Unlicense W3C WTFPL X11 Zend-2.0 Zlib ZPL-1.1 ZPL-2.0 ZPL-2.1 bzip2-1.0.6
+	curl libpng zlib-acknowledgement`
+	for _, id := range strings.Fields(ids) {
+		spdxLicenses[strings.ToLower(id)] = true
+	}
+}
+
+// spdxExpression checks the syntax of an SPDX license expression such as
+// "MIT OR Apache-2.0" and returns any identifiers not in the license list.
+func spdxExpression(expr string) ([]string, error) {
+	toks := strings.Fields(strings.NewReplacer("(", " ( ", ")", " ) ").Replace(expr))
+	if len(toks) == 0 {
+		return nil, fmt.Errorf("empty license expression")
+	}
+
+	var unknown []string
+	pos := 0
+	var compound func() error
+	simple := func() error {
+		if pos >= len(toks) {
+			return fmt.Errorf("expected a license identifier at the end of the expression")
+		}
+		t := toks[pos]
+		pos++
+		if t == "(" {
+			if err := compound(); err != nil {
+				return err
+			}
+			if pos >= len(toks) || toks[pos] != ")" {
+				return fmt.Errorf("missing closing parenthesis")
+			}
+			pos++
+			return nil
+		}
+		if isOperator(t) || t == ")" {
+			return fmt.Errorf("unexpected %q", t)
+		}
+		if !validIdentifier(t) {
+			return fmt.Errorf("%q is not a valid license identifier", t)
+		}
+		if !strings.HasPrefix(t, "LicenseRef-") && !strings.HasPrefix(t, "DocumentRef-") && !spdxLicenses[strings.ToLower(t)] && !spdxLicenses[strings.ToLower(strings.TrimSuffix(t, "+"))] {
+			unknown = append(unknown, t)
+		}
+		if pos < len(toks) && strings.EqualFold(toks[pos], "WITH") {
+			pos++
+			if pos >= len(toks) || !validIdentifier(toks[pos]) || isOperator(toks[pos]) {
+				return fmt.Errorf("expected a license exception after WITH")
+			}
+			pos++
+		}
+		return nil
+	}
+	compound = func() error {
+		if err := simple(); err != nil {
+			return err
+		}
+		for pos < len(toks) && (strings.EqualFold(toks[pos], "AND") || strings.EqualFold(toks[pos], "OR")) {
+			pos++
+			if err := simple(); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if err := compound(); err != nil {
+		return unknown, err
+	}
+	if pos < len(toks) {
+		return unknown, fmt.Errorf("unexpected %q", toks[pos])
+	}
+	return unknown, nil
+}
+
+func isOperator(t string) bool {
+	return strings.EqualFold(t, "AND") || strings.EqualFold(t, "OR") || strings.EqualFold(t, "WITH")
+}
+
+func validIdentifier(t string) bool {
+	for i, c := range t {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', c == '-', c == '.':
+		case c == '+' && i == len(t)-1:
+		default:
+			return false
+		}
+	}
+	return t != ""
+}
@@ -23,6 +23,9 @@ const DefaultGlideFile = "glide.yaml"
 // As of Go 1.5, this is always vendor.
 var VendorDir = "vendor"
 
+// BinDir is the name of the directory, in a project, tools are built into.
+var BinDir = "bin"
+
 // Tmp is the temporary directory Glide should use. Defaults to "" which
 // signals using the system default.
 var Tmp = ""
@@ -39,6 +42,13 @@ var GlideFile = DefaultGlideFile
 // LockFile is the default name for the lock file.
 const LockFile = "glide.lock"
 
+// WorkspaceFile is the name of the file listing the projects in a workspace.
+const WorkspaceFile = "glide.workspace.yaml"
+
+// PruneFile is the name of the file, in the vendor directory, recording the
+// files removed from each dependency when installing with pruning.
+const PruneFile = "glide.pruned.yaml"
+
 func init() {
 
 	// As of Go 1.8 the GOPATH is no longer required to be set. Instead there
@@ -164,6 +174,26 @@ func GlideWD(dir string) (string, error) {
 	return GlideWD(base)
 }
 
+// Workspace finds the workspace file by walking up from the working directory.
+func Workspace() (string, error) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+
+	for {
+		wf := filepath.Join(dir, WorkspaceFile)
+		if _, err := os.Stat(wf); err == nil {
+			return wf, nil
+		}
+		base := filepath.Dir(dir)
+		if base == dir {
+			return "", fmt.Errorf("Unable to find %s", WorkspaceFile)
+		}
+		dir = base
+	}
+}
+
 // Stores the gopaths so they do not get repeatedly looked up. This is especially
 // true when the default value needs to be retrieved from `go env GOPATH`.
 // TODO(mattfarina): Instead of a singleton an application context would be a
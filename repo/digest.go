@@ -0,0 +1,99 @@
+package repo
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// DigestPrefix identifies the algorithm used to generate a content digest.
+const DigestPrefix = "sha256:"
+
+// Directories holding VCS metadata. These are never part of an exported tree
+// so they are skipped when generating a digest.
+var digestSkipDirs = map[string]bool{
+	".git": true,
+	".hg":  true,
+	".bzr": true,
+	".svn": true,
+}
+
+// Digest generates a content digest for the package tree at dir.
+//
+// Every regular file and symlink below dir contributes its slash separated
+// relative path, whether it is a file or a link, and a sha256 of its contents
+// (or link target). Files are visited in lexical order so the digest is stable
+// across systems. Permissions are left out as Windows does not report the
+// executable bit. VCS metadata directories are skipped, as are the nested
+// vendor and Godeps/_workspace directories installing with --strip-vendor
+// removes.
+func Digest(dir string) (string, error) {
+	h := sha256.New()
+	err := filepath.Walk(dir, func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if fi.IsDir() {
+			if path != dir && (digestSkipDirs[fi.Name()] || isStripped(path)) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+
+		var sum []byte
+		mode := "f"
+		if fi.Mode()&os.ModeSymlink != 0 {
+			mode = "l"
+			ln, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			s := sha256.Sum256([]byte(filepath.ToSlash(ln)))
+			sum = s[:]
+		} else if fi.Mode().IsRegular() {
+			sum, err = fileSum(path)
+			if err != nil {
+				return err
+			}
+		} else {
+			// Devices, sockets, and the like are not part of a source tree.
+			return nil
+		}
+
+		fmt.Fprintf(h, "%s %s %x\n", mode, filepath.ToSlash(rel), sum)
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%s%x", DigestPrefix, h.Sum(nil)), nil
+}
+
+// isStripped returns true for the directories stripping the vendor directory
+// removes.
+func isStripped(dir string) bool {
+	name := filepath.Base(dir)
+	return name == "vendor" || (name == "_workspace" && filepath.Base(filepath.Dir(dir)) == "Godeps")
+}
+
+func fileSum(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
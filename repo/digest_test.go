@@ -0,0 +1,74 @@
+package repo
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDigest(t *testing.T) {
+	dir, err := ioutil.TempDir("", "glide-digest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	if err := os.MkdirAll(filepath.Join(dir, "sub"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "foo.go"), []byte("package foo\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "sub", "bar.go"), []byte("package sub\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	d1, err := Digest(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(d1, DigestPrefix) {
+		t.Errorf("Expected digest %s to begin with %s", d1, DigestPrefix)
+	}
+
+	// VCS metadata does not change the digest.
+	if err := os.MkdirAll(filepath.Join(dir, ".git"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, ".git", "HEAD"), []byte("ref: refs/heads/master\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	d2, err := Digest(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d1 != d2 {
+		t.Errorf("Expected VCS metadata to be skipped but digest changed from %s to %s", d1, d2)
+	}
+
+	// Nor does the executable bit, which Windows does not report.
+	if err := os.Chmod(filepath.Join(dir, "foo.go"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	d2, err = Digest(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d1 != d2 {
+		t.Errorf("Expected the executable bit to be left out but digest changed from %s to %s", d1, d2)
+	}
+
+	// Modifying a file does.
+	if err := ioutil.WriteFile(filepath.Join(dir, "sub", "bar.go"), []byte("package bar\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	d3, err := Digest(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d1 == d3 {
+		t.Error("Expected a modified file to change the digest")
+	}
+}
@@ -0,0 +1,65 @@
+package repo
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Masterminds/semver"
+	"github.com/Masterminds/vcs"
+)
+
+// CommitSubjects returns the subject, the first line of the message, of each
+// commit reachable from to but not from from. The newest commit is first.
+//
+// Only Git and Mercurial repositories are supported.
+func CommitSubjects(repo vcs.Repo, from, to string) ([]string, error) {
+	var out []byte
+	var err error
+	switch repo.Vcs() {
+	case vcs.Git:
+		out, err = repo.RunFromDir("git", "log", "--format=%s", from+".."+to)
+	case vcs.Hg:
+		out, err = repo.RunFromDir("hg", "log", "-r", fmt.Sprintf("reverse(only(%s, %s))", to, from), "--template", "{desc|firstline}\\n")
+	default:
+		return nil, fmt.Errorf("Listing commits is not supported for %s", repo.Vcs())
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Unable to list the commits between %s and %s: %s", from, to, strings.TrimSpace(string(out)))
+	}
+
+	var subjects []string
+	for _, l := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		if l != "" {
+			subjects = append(subjects, commitSubjectFirstLine(l))
+		}
+	}
+	return subjects, nil
+}
+
+// CommitTag returns a tag on a commit, preferring the highest semantic version.
+// An empty string is returned when the commit is not tagged.
+func CommitTag(repo vcs.Repo, commit string) string {
+	tags, err := repo.TagsFromCommit(commit)
+	if err != nil || len(tags) == 0 {
+		return ""
+	}
+
+	var best *semver.Version
+	tag := ""
+	for _, t := range tags {
+		sv, err := semver.NewVersion(t)
+		if err != nil {
+			continue
+		}
+		if best == nil || sv.GreaterThan(best) {
+			best = sv
+			tag = t
+		}
+	}
+	if tag != "" {
+		return tag
+	}
+	sort.Strings(tags)
+	return tags[0]
+}
@@ -6,6 +6,7 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"sort"
 	"strings"
 	"sync"
 	"syscall"
@@ -14,6 +15,7 @@ import (
 	"github.com/Masterminds/glide/cache"
 	"github.com/Masterminds/glide/cfg"
 	"github.com/Masterminds/glide/dependency"
+	"github.com/Masterminds/glide/gomod"
 	"github.com/Masterminds/glide/importer"
 	"github.com/Masterminds/glide/msg"
 	gpath "github.com/Masterminds/glide/path"
@@ -43,6 +45,21 @@ type Installer struct {
 	// ResolveTest sets if test dependencies should be resolved.
 	ResolveTest bool
 
+	// Backtrack chooses versions with the backtracking solver once every
+	// requirement is known rather than keeping the first version found.
+	Backtrack bool
+
+	// Platforms lists the os/arch pairs, such as linux/amd64, dependencies
+	// are resolved for one at a time. When empty they are resolved once.
+	Platforms []string
+
+	// Graph, when set, records the imports found while updating.
+	Graph *dependency.ImportGraph
+
+	// Prune removes the files selected by the prune rules of the config from
+	// the dependencies when exporting them. See cfg.Prune.
+	Prune bool
+
 	// Updated tracks the packages that have been remotely fetched.
 	Updated *UpdateTracker
 }
@@ -75,6 +92,7 @@ func (i *Installer) Install(lock *cfg.Lockfile, conf *cfg.Config) (*cfg.Config,
 	// existing commands.
 	newConf := &cfg.Config{}
 	newConf.Name = conf.Name
+	newConf.Prune = conf.Prune
 
 	newConf.Imports = make(cfg.Dependencies, len(lock.Imports))
 	for k, v := range lock.Imports {
@@ -86,9 +104,31 @@ func (i *Installer) Install(lock *cfg.Lockfile, conf *cfg.Config) (*cfg.Config,
 		newConf.DevImports[k] = cfg.DependencyFromLock(v)
 	}
 
+	if len(lock.Groups) > 0 {
+		newConf.Groups = make(map[string]cfg.Dependencies, len(lock.Groups))
+		for name, g := range lock.Groups {
+			newConf.Groups[name] = make(cfg.Dependencies, len(g))
+			for k, v := range g {
+				newConf.Groups[name][k] = cfg.DependencyFromLock(v)
+			}
+		}
+	}
+
+	newConf.Tools = make(cfg.Dependencies, len(lock.Tools))
+	for k, v := range lock.Tools {
+		newConf.Tools[k] = cfg.DependencyFromLock(v)
+	}
+
 	newConf.DeDupe()
+	groups := newConf.GroupDependencies()
 
-	if len(newConf.Imports) == 0 && len(newConf.DevImports) == 0 {
+	for _, d := range append(append(newConf.Imports, newConf.DevImports...), groups...) {
+		if d.Path != "" {
+			msg.Warn("%s is overridden by the local path %s", d.Name, d.Path)
+		}
+	}
+
+	if len(newConf.Imports) == 0 && len(newConf.DevImports) == 0 && len(groups) == 0 {
 		msg.Info("No dependencies found. Nothing installed.")
 		return newConf, nil
 	}
@@ -100,6 +140,10 @@ func (i *Installer) Install(lock *cfg.Lockfile, conf *cfg.Config) (*cfg.Config,
 		return newConf, err
 	}
 	err = LazyConcurrentUpdate(newConf.DevImports, i, newConf)
+	if err != nil {
+		return newConf, err
+	}
+	err = LazyConcurrentUpdate(groups, i, newConf)
 
 	return newConf, err
 }
@@ -117,10 +161,12 @@ func (i *Installer) Checkout(conf *cfg.Config) error {
 	}
 
 	if i.ResolveTest {
-		return ConcurrentUpdate(conf.DevImports, i, conf)
+		if err := ConcurrentUpdate(conf.DevImports, i, conf); err != nil {
+			return err
+		}
 	}
 
-	return nil
+	return ConcurrentUpdate(conf.GroupDependencies(), i, conf)
 }
 
 // Update updates all dependencies.
@@ -131,10 +177,86 @@ func (i *Installer) Checkout(conf *cfg.Config) error {
 //
 // In other words, all versions in the Lockfile will be empty.
 func (i *Installer) Update(conf *cfg.Config) error {
-	base := "."
-
 	ic := newImportCache()
 
+	// The import graph records the packages that import the dependencies
+	// only found by walking the code.
+	if i.Graph == nil {
+		i.Graph = dependency.NewImportGraph()
+		defer func() { i.Graph = nil }()
+	}
+
+	// The project asks for the dependencies listed in its glide.yaml file.
+	listed := append(append(cfg.Dependencies{}, conf.Imports...), conf.DevImports...)
+	for _, d := range append(listed, conf.GroupDependencies()...) {
+		ic.Require(d.Name, conf.Name, d.Reference)
+		if d.Reference != "" {
+			d.Rule = cfg.RuleConfig
+		}
+	}
+
+	// The solver may choose versions importing other packages than those the
+	// imports were first walked at, so the dependencies listed are kept to
+	// walk them again from.
+	var orig *cfg.Config
+	if i.Backtrack {
+		orig = conf.Clone()
+	}
+
+	i.resolveImports(conf, ic)
+
+	for round := 1; i.Backtrack; round++ {
+		changed, err := solveVersions(conf, ic, configImporter(conf))
+		if err != nil {
+			return err
+		}
+		if !changed {
+			break
+		}
+		if round == maxSolveRounds {
+			return fmt.Errorf("The versions solved did not settle after walking the imports %d times", maxSolveRounds)
+		}
+
+		msg.Info("Resolving imports again at the solved versions")
+		ic = resetDependencies(conf, orig, solverDependencies(conf))
+		if err := SetReference(conf, i.ResolveTest); err != nil {
+			return err
+		}
+		*i.Graph = *dependency.NewImportGraph()
+		i.resolveImports(conf, ic)
+	}
+
+	requireImporters(conf, i.Graph, ic)
+	recordProvenance(conf.Imports, ic)
+	recordProvenance(conf.DevImports, ic)
+	for _, g := range conf.Groups {
+		recordProvenance(g, ic)
+	}
+	recordProvenance(conf.Tools, ic)
+
+	msg.Info("Downloading dependencies. Please wait...")
+
+	err := ConcurrentUpdate(conf.Imports, i, conf)
+	if err != nil {
+		return err
+	}
+
+	if i.ResolveTest {
+		err = ConcurrentUpdate(conf.DevImports, i, conf)
+		if err != nil {
+			return err
+		}
+	}
+
+	return ConcurrentUpdate(conf.GroupDependencies(), i, conf)
+}
+
+// resolveImports walks the imports of the project and of its dependencies,
+// adding the dependencies found to the config and checking out the versions
+// they are walked at.
+func (i *Installer) resolveImports(conf *cfg.Config, ic *importCache) {
+	base := "."
+
 	m := &MissingPackageHandler{
 		home:    i.Home,
 		force:   i.Force,
@@ -148,6 +270,8 @@ func (i *Installer) Update(conf *cfg.Config) error {
 		Imported:  make(map[string]bool),
 		Conflicts: make(map[string]bool),
 		Config:    conf,
+		Importer:  configImporter(conf),
+		Backtrack: i.Backtrack,
 	}
 
 	// Update imports
@@ -160,6 +284,7 @@ func (i *Installer) Update(conf *cfg.Config) error {
 	res.Handler = m
 	res.VersionHandler = v
 	res.ResolveAllFiles = i.ResolveAllFiles
+	res.Graph = i.Graph
 	msg.Info("Resolving imports")
 
 	imps, timps, err := res.ResolveLocal(false)
@@ -227,24 +352,172 @@ func (i *Installer) Update(conf *cfg.Config) error {
 		}
 	}
 
-	msg.Info("Downloading dependencies. Please wait...")
-
-	err = ConcurrentUpdate(conf.Imports, i, conf)
-	if err != nil {
-		return err
+	for _, name := range conf.GroupNames() {
+		msg.Debug("Resolving dependencies in group %s", name)
+		added, err := resolveGroup(conf, conf.Groups[name], res, m, v)
+		if err != nil {
+			msg.Die("Failed to retrieve a list of dependencies in group %s: %s", name, err)
+		}
+		conf.Groups[name] = append(conf.Groups[name], added...)
 	}
 
-	if i.ResolveTest {
-		err = ConcurrentUpdate(conf.DevImports, i, conf)
+	if len(conf.Tools) > 0 {
+		msg.Debug("Resolving tool dependencies")
+		added, err := resolveGroup(conf, conf.Tools, res, m, v)
 		if err != nil {
-			return err
+			msg.Die("Failed to retrieve a list of tool dependencies: %s", err)
 		}
+		conf.Tools = append(conf.Tools, added...)
 	}
+}
 
-	return nil
+// resetDependencies sets the dependencies of a config back to those listed in
+// the glide.yaml file before walking the imports again at solved versions. The
+// dependencies listed get their solved versions and the import cache returned
+// holds the solved versions of the others for when they are found again. The
+// project still asks for the versions listed.
+func resetDependencies(conf, orig *cfg.Config, solved cfg.Dependencies) *importCache {
+	c := orig.Clone()
+	conf.Imports = c.Imports
+	conf.DevImports = c.DevImports
+	conf.Groups = c.Groups
+	conf.Tools = c.Tools
+
+	ic := newImportCache()
+	listed := append(append(cfg.Dependencies{}, orig.Imports...), orig.DevImports...)
+	for _, d := range append(listed, orig.GroupDependencies()...) {
+		ic.Require(d.Name, conf.Name, d.Reference)
+	}
+
+	for _, s := range solved {
+		found := false
+		for _, d := range solverDependencies(conf) {
+			if d.Name != s.Name {
+				continue
+			}
+			found = true
+			// The subpackages are added to as the imports are walked.
+			d.Subpackages = append([]string{}, d.Subpackages...)
+			if d.Path == "" && s.Reference != "" {
+				d.Reference = s.Reference
+				d.Pin = ""
+			}
+		}
+		if !found && s.Path == "" {
+			if d, _ := ic.Get(s.Name); d == nil {
+				d = s.Clone()
+				d.Subpackages = nil
+				d.Pin = ""
+				d.RequiredBy = nil
+				ic.Add(s.Name, d, conf.Name)
+			}
+		}
+	}
+	return ic
+}
+
+// requireImporters records the packages importing each dependency in the
+// import graph as asking for it. Those asking for a version in their
+// configuration have already been recorded. This adds those that only import
+// it in their code.
+func requireImporters(conf *cfg.Config, g *dependency.ImportGraph, ic *importCache) {
+	roots := []string{conf.Name}
+	for _, d := range solverDependencies(conf) {
+		roots = append(roots, d.Name)
+	}
+	// The longest root a package is in is the one it belongs to.
+	sort.Slice(roots, func(a, b int) bool { return len(roots[a]) > len(roots[b]) })
+	rootOf := func(pkg string) string {
+		for _, r := range roots {
+			if pkg == r || strings.HasPrefix(pkg, r+"/") {
+				return r
+			}
+		}
+		return ""
+	}
+
+	pkgs := make([]string, 0, len(g.Imports))
+	for p := range g.Imports {
+		pkgs = append(pkgs, p)
+	}
+	sort.Strings(pkgs)
+	for _, p := range pkgs {
+		by := rootOf(p)
+		if by == "" {
+			continue
+		}
+		imps := g.Imports[p]
+		// Only the tests of the project are walked.
+		if by == conf.Name {
+			imps = append(append([]string{}, imps...), g.TestImports[p]...)
+		}
+		for _, imp := range imps {
+			if r := rootOf(imp); r != "" && r != by && r != conf.Name {
+				ic.Require(r, by, "")
+			}
+		}
+	}
 }
 
-// Export from the cache to the vendor directory
+// recordProvenance records on each resolved dependency the packages that asked
+// for it and the rule its version was chosen by.
+func recordProvenance(deps cfg.Dependencies, ic *importCache) {
+	for _, d := range deps {
+		d.RequiredBy = ic.Requirements(d.Name)
+		switch {
+		case d.Path != "":
+			d.Rule = cfg.RulePath
+		case d.Reference == "":
+			d.Rule = cfg.RuleDefault
+		case d.Rule == "":
+			d.Rule = cfg.RuleFirst
+		}
+	}
+}
+
+// resolveGroup resolves the transitive dependencies of a group and returns
+// those not already listed in it. The group is resolved with its dependencies
+// standing in for the test imports so the dependencies it brings in are kept
+// with the group rather than added to the imports.
+func resolveGroup(conf *cfg.Config, group cfg.Dependencies, res *dependency.Resolver, m *MissingPackageHandler, v *VersionHandler) (cfg.Dependencies, error) {
+	if len(group) == 0 {
+		return nil, nil
+	}
+
+	gconf := &cfg.Config{
+		Name:      conf.Name,
+		Ignore:    conf.Ignore,
+		Exclude:   conf.Exclude,
+		Importers: conf.Importers,
+		Imports:   conf.Imports,
+	}
+	for _, d := range group {
+		if !conf.Imports.Has(d.Name) && !conf.DevImports.Has(d.Name) {
+			gconf.DevImports = append(gconf.DevImports, d)
+		}
+	}
+	known := len(gconf.DevImports)
+
+	res.Config, m.Config, v.Config = gconf, gconf, gconf
+	defer func() { res.Config, m.Config, v.Config = conf, conf, conf }()
+
+	// Every package in the group is resolved, including those in repositories
+	// that are imported, as the group may use packages the imports do not.
+	if _, err := allPackages(group, res, true); err != nil {
+		return nil, err
+	}
+
+	var added cfg.Dependencies
+	for _, d := range gconf.DevImports[known:] {
+		if !group.Has(d.Name) {
+			added = append(added, d)
+		}
+	}
+	return added, nil
+}
+
+// Export from the cache to the vendor directory. Dependencies not used on the
+// current platform are left out.
 func (i *Installer) Export(conf *cfg.Config) error {
 	tempDir, err := ioutil.TempDir(gpath.Tmp, "glide-vendor")
 	if err != nil {
@@ -260,6 +533,9 @@ func (i *Installer) Export(conf *cfg.Config) error {
 	vp := filepath.Join(tempDir, "vendor")
 	err = os.MkdirAll(vp, 0755)
 
+	// The dependencies exported, for pruning.
+	var exported cfg.Dependencies
+
 	msg.Info("Exporting resolved dependencies...")
 	done := make(chan struct{}, concurrentWorkers)
 	in := make(chan *cfg.Dependency, concurrentWorkers)
@@ -279,14 +555,34 @@ func (i *Installer) Export(conf *cfg.Config) error {
 					}
 					cache.Lock(key)
 
-					cdir := filepath.Join(cache.Location(), "src", key)
-					repo, err := dep.GetRepo(cdir)
-					if err != nil {
-						msg.Die(err.Error())
+					dest := filepath.Join(vp, filepath.ToSlash(dep.Name))
+					if dep.Path != "" {
+						// Local path overrides change as they are worked on
+						// so no digest is kept for them.
+						msg.Info("--> Copying %s from local path %s", dep.Name, dep.Path)
+						dep.Digest = ""
+						err = exportLocal(dep, dest)
+						if err != nil {
+							msg.Err("Copy failed for %s: %s\n", dep.Name, err)
+						}
+					} else {
+						cdir := filepath.Join(cache.Location(), "src", key)
+						repo, err := dep.GetRepo(cdir)
+						if err != nil {
+							msg.Die(err.Error())
+						}
+						msg.Info("--> Exporting %s", dep.Name)
+						err = repo.ExportDir(dest)
+						if err != nil {
+							msg.Err("Export failed for %s: %s\n", dep.Name, err)
+						} else {
+							err = verifyDigest(dep, dest)
+						}
 					}
-					msg.Info("--> Exporting %s", dep.Name)
-					if err := repo.ExportDir(filepath.Join(vp, filepath.ToSlash(dep.Name))); err != nil {
-						msg.Err("Export failed for %s: %s\n", dep.Name, err)
+					if err == nil {
+						err = vendorMajor(dep, dest)
+					}
+					if err != nil {
 						// Capture the error while making sure the concurrent
 						// operations don't step on each other.
 						lock.Lock()
@@ -307,7 +603,7 @@ func (i *Installer) Export(conf *cfg.Config) error {
 	}
 
 	for _, dep := range conf.Imports {
-		if !conf.HasIgnore(dep.Name) {
+		if !conf.HasIgnore(dep.Name) && !filterArchOs(dep) {
 			err = os.MkdirAll(filepath.Join(vp, filepath.ToSlash(dep.Name)), 0755)
 			if err != nil {
 				lock.Lock()
@@ -319,13 +615,14 @@ func (i *Installer) Export(conf *cfg.Config) error {
 				lock.Unlock()
 			}
 			wg.Add(1)
+			exported = append(exported, dep)
 			in <- dep
 		}
 	}
 
 	if i.ResolveTest {
 		for _, dep := range conf.DevImports {
-			if !conf.HasIgnore(dep.Name) {
+			if !conf.HasIgnore(dep.Name) && !filterArchOs(dep) {
 				err = os.MkdirAll(filepath.Join(vp, filepath.ToSlash(dep.Name)), 0755)
 				if err != nil {
 					lock.Lock()
@@ -337,11 +634,30 @@ func (i *Installer) Export(conf *cfg.Config) error {
 					lock.Unlock()
 				}
 				wg.Add(1)
+				exported = append(exported, dep)
 				in <- dep
 			}
 		}
 	}
 
+	for _, dep := range conf.GroupDependencies() {
+		if !conf.HasIgnore(dep.Name) && !filterArchOs(dep) {
+			err = os.MkdirAll(filepath.Join(vp, filepath.ToSlash(dep.Name)), 0755)
+			if err != nil {
+				lock.Lock()
+				if returnErr == nil {
+					returnErr = err
+				} else {
+					returnErr = cli.NewMultiError(returnErr, err)
+				}
+				lock.Unlock()
+			}
+			wg.Add(1)
+			exported = append(exported, dep)
+			in <- dep
+		}
+	}
+
 	wg.Wait()
 
 	// Close goroutines setting the version
@@ -353,6 +669,12 @@ func (i *Installer) Export(conf *cfg.Config) error {
 		return returnErr
 	}
 
+	if i.Prune {
+		if err := i.pruneVendor(conf, exported, vp); err != nil {
+			return err
+		}
+	}
+
 	msg.Info("Replacing existing vendor dependencies")
 
 	// Check if a .git directory exists under the old vendor dir. If it does,
@@ -394,6 +716,56 @@ func (i *Installer) Export(conf *cfg.Config) error {
 
 }
 
+// vendorMajor moves a Go module in a major branch, exported to dest, under its
+// major version suffix when all of its packages are imported with the suffix.
+// The go tool only leaves the suffix out of import paths in module aware code
+// outside of vendor directories.
+func vendorMajor(dep *cfg.Dependency, dest string) error {
+	elem := gomod.MajorElem(dep.Subpackages)
+	if elem == "" || gomod.ModulePath(dest) != dep.Name+"/"+elem {
+		return nil
+	}
+	tmp := dest + ".major"
+	if err := os.Rename(dest, tmp); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dest, 0755); err != nil {
+		return err
+	}
+	return os.Rename(tmp, filepath.Join(dest, elem))
+}
+
+// VendoredDir returns the directory in a vendor directory holding a
+// dependency. A Go module in a major branch is vendored under its major
+// version suffix. See vendorMajor.
+func VendoredDir(vpath, name string, subpackages []string) string {
+	dir := filepath.Join(vpath, filepath.FromSlash(name))
+	if e := gomod.MajorElem(subpackages); e != "" && gomod.ModulePath(filepath.Join(dir, e)) == name+"/"+e {
+		return filepath.Join(dir, e)
+	}
+	return dir
+}
+
+// verifyDigest generates the content digest of an exported dependency. When
+// the dependency already carries a digest, typically from the lock file, the
+// exported tree must match it. Otherwise the new digest is recorded on the
+// dependency so it can be written to the lock file.
+func verifyDigest(dep *cfg.Dependency, dir string) error {
+	d, err := Digest(dir)
+	if err != nil {
+		msg.Err("Unable to generate digest for %s: %s", dep.Name, err)
+		return err
+	}
+
+	if dep.Digest != "" && dep.Digest != d {
+		msg.Err("Digest mismatch for %s: %s expects %s but exported tree is %s", dep.Name, gpath.LockFile, dep.Digest, d)
+		return fmt.Errorf("Exported %s does not match the digest in %s", dep.Name, gpath.LockFile)
+	}
+
+	dep.Digest = d
+	return nil
+}
+
 // fixcle is a helper function that tries to recover from cross-device rename
 // errors by falling back to copying.
 func fixcle(from, to string, terr *os.LinkError) error {
@@ -432,6 +804,7 @@ func (i *Installer) List(conf *cfg.Config) []*cfg.Dependency {
 		Imported:  make(map[string]bool),
 		Conflicts: make(map[string]bool),
 		Config:    conf,
+		Importer:  configImporter(conf),
 	}
 
 	// Update imports
@@ -652,12 +1025,25 @@ func (m *MissingPackageHandler) PkgPath(pkg string) string {
 		}
 	}
 
-	key, err := cache.Key(d.Remote())
-	if err != nil {
-		msg.Die("Error generating cache key for %s", d.Name)
+	return subpackagePath(d, root, sub)
+}
+
+// subpackagePath returns the location of a subpackage of a dependency, in
+// its local path or in the cache. The major version suffix of a Go module in
+// a major branch is not a directory and is left out.
+func subpackagePath(d *cfg.Dependency, root, sub string) string {
+	var dir string
+	if d.Path != "" {
+		dir = LocalPath(d)
+	} else {
+		key, err := cache.Key(d.Remote())
+		if err != nil {
+			msg.Die("Error generating cache key for %s", d.Name)
+		}
+		dir = filepath.Join(cache.Location(), "src", key)
 	}
 
-	return filepath.Join(cache.Location(), "src", key, filepath.FromSlash(sub))
+	return filepath.Join(dir, filepath.FromSlash(gomod.SubpackageDir(dir, root, sub)))
 }
 
 func (m *MissingPackageHandler) fetchToCache(pkg string, addTest bool) error {
@@ -690,6 +1076,15 @@ func (m *MissingPackageHandler) fetchToCache(pkg string, addTest bool) error {
 	return VcsUpdate(d, m.force, m.updated)
 }
 
+// configImporter returns the importer for the importers setting in a config.
+func configImporter(conf *cfg.Config) importer.Importer {
+	imp, err := importer.New(conf.Importers)
+	if err != nil {
+		msg.Die("Invalid importers setting in %s: %s", gpath.GlideFile, err)
+	}
+	return imp
+}
+
 // VersionHandler handles setting the proper version in the VCS.
 type VersionHandler struct {
 
@@ -702,11 +1097,19 @@ type VersionHandler struct {
 
 	Config *cfg.Config
 
+	// Importer reads the configuration of dependencies. When nil all of the
+	// registered importers are used.
+	Importer importer.Importer
+
 	// There's a problem where many sub-packages have been asked to set a version
 	// and you can end up with numerous conflict messages that are exactly the
 	// same. We are keeping track to only display them once.
 	// the parent pac
 	Conflicts map[string]bool
+
+	// Backtrack leaves conflicting versions for the backtracking solver to
+	// settle once every requirement is known.
+	Backtrack bool
 }
 
 // Process imports dependencies for a package
@@ -718,18 +1121,34 @@ func (d *VersionHandler) Process(pkg string) (e error) {
 		return nil
 	}
 
+	d.requireMajor(pkg, root)
+
 	// We have not tried to import, yet.
 	// Should we look in places other than the root of the project?
 	if d.Imported[root] == false {
 		d.Imported[root] = true
 		p := d.pkgPath(root)
-		f, deps, err := importer.Import(p)
+
+		// A Go module in a major subdirectory has its own go.mod file.
+		if _, mdir := gomod.MajorModule(p, root, pkg); mdir != "" {
+			p = filepath.Join(p, mdir)
+		}
+		var f bool
+		var deps []*cfg.Dependency
+		var err error
+		if d.Importer != nil {
+			f, deps, err = d.Importer.Import(p)
+		} else {
+			f, deps, err = importer.Import(p)
+		}
 		if f && err == nil {
 			for _, dep := range deps {
+				d.Use.Require(dep.Name, root, dep.Reference)
 
 				// The fist one wins. Would something smater than this be better?
 				exists, _ := d.Use.Get(dep.Name)
 				if exists == nil && (dep.Reference != "" || dep.Repository != "") {
+					msg.Debug("--> Using %s from %s for %s", dep.Reference, dep.ImportedFrom, dep.Name)
 					d.Use.Add(dep.Name, dep, root)
 				}
 			}
@@ -742,6 +1161,47 @@ func (d *VersionHandler) Process(pkg string) (e error) {
 	return
 }
 
+// requireMajor asks for the major version of a Go module when a package is
+// imported with its suffix, such as github.com/foo/bar/v2, and nothing else
+// asked for a version. The package is recorded as a subpackage so the module
+// can be vendored under the suffix.
+func (d *VersionHandler) requireMajor(pkg, root string) {
+	major, _ := gomod.MajorModule(d.pkgPath(root), root, pkg)
+	if major == 0 {
+		return
+	}
+	mod := fmt.Sprintf("%s/v%d", root, major)
+	ref := fmt.Sprintf("^%d.0.0", major)
+
+	v := d.Config.Imports.Get(root)
+	if v == nil {
+		v = d.Config.DevImports.Get(root)
+	}
+	if v == nil {
+		// SetVersion uses it once the dependency is found.
+		if dep, _ := d.Use.Get(root); dep == nil {
+			d.Use.Require(root, mod, ref)
+			d.Use.Add(root, &cfg.Dependency{Name: root, Reference: ref}, mod)
+		}
+		return
+	}
+
+	if sub := strings.TrimPrefix(pkg, root+"/"); !v.HasSubpackage(sub) {
+		v.Subpackages = append(v.Subpackages, sub)
+	}
+	if v.Reference != "" {
+		return
+	}
+	msg.Info("--> Using %s %s for the module %s", root, ref, mod)
+	d.Use.Require(root, mod, ref)
+	v.Reference = ref
+	v.Pin = ""
+	v.Rule = cfg.RuleFirst
+	if err := VcsVersion(v); err != nil {
+		msg.Warn("Unable to set version on %s to %s. Err: %s", root, ref, err)
+	}
+}
+
 // SetVersion sets the version for a package. If that package version is already
 // set it handles the case by:
 // - keeping the already set version
@@ -777,6 +1237,10 @@ func (d *VersionHandler) SetVersion(pkg string, addTest bool) (e error) {
 			v.Reference = dep.Reference
 			// Clear the pin, if set, so the new version can be used.
 			v.Pin = ""
+			v.Rule = cfg.RuleFirst
+			dep = v
+		} else if v.Reference != "" && dep.Reference != "" && v.Reference != dep.Reference && d.Backtrack {
+			msg.Debug("--> %s wants %s %s, leaving it to the solver", req, v.Name, dep.Reference)
 			dep = v
 		} else if v.Reference != "" && dep.Reference != "" && v.Reference != dep.Reference {
 			dest := d.pkgPath(pkg)
@@ -790,6 +1254,7 @@ func (d *VersionHandler) SetVersion(pkg string, addTest bool) (e error) {
 	} else if dep != nil {
 		// We've got an imported dependency to use and don't already have a
 		// record of it. Append it to the Imports.
+		dep.Rule = cfg.RuleFirst
 		if addTest {
 			d.Config.DevImports = append(d.Config.DevImports, dep)
 		} else {
@@ -842,12 +1307,7 @@ func (d *VersionHandler) pkgPath(pkg string) string {
 		}
 	}
 
-	key, err := cache.Key(dep.Remote())
-	if err != nil {
-		msg.Die("Error generating cache key for %s", dep.Name)
-	}
-
-	return filepath.Join(cache.Location(), "src", key, filepath.FromSlash(sub))
+	return subpackagePath(dep, root, sub)
 }
 
 func determineDependency(v, dep *cfg.Dependency, dest, req string) *cfg.Dependency {
@@ -855,12 +1315,21 @@ func determineDependency(v, dep *cfg.Dependency, dest, req string) *cfg.Dependen
 	if err != nil {
 		singleWarn("Unable to access repo for %s\n", v.Name)
 		singleInfo("Keeping %s %s", v.Name, v.Reference)
-		return v
+		return keepDependency(v, cfg.RuleKept)
 	}
 
 	vIsRef := repo.IsReference(v.Reference)
 	depIsRef := repo.IsReference(dep.Reference)
 
+	// A pseudo-version of a Go module names a commit by the prefix of its id.
+	// Both may be the same commit.
+	if vIsRef && depIsRef && sameCommit(repo, v.Reference, dep.Reference) {
+		if len(dep.Reference) > len(v.Reference) {
+			v.Reference = dep.Reference
+		}
+		return v
+	}
+
 	// Both are references and they are different ones.
 	if vIsRef && depIsRef {
 		singleWarn("Conflict: %s rev is currently %s, but %s wants %s\n", v.Name, v.Reference, req, dep.Reference)
@@ -869,14 +1338,14 @@ func determineDependency(v, dep *cfg.Dependency, dest, req string) *cfg.Dependen
 		displayCommitInfo(repo, dep)
 
 		singleInfo("Keeping %s %s", v.Name, v.Reference)
-		return v
+		return keepDependency(v, cfg.RuleKept)
 	} else if vIsRef {
 		// The current one is a reference and the suggestion is a SemVer constraint.
 		con, err := semver.NewConstraint(dep.Reference)
 		if err != nil {
 			singleWarn("Version issue for %s: '%s' is neither a reference or semantic version constraint\n", dep.Name, dep.Reference)
 			singleInfo("Keeping %s %s", v.Name, v.Reference)
-			return v
+			return keepDependency(v, cfg.RuleKept)
 		}
 
 		ver, err := semver.NewVersion(v.Reference)
@@ -885,23 +1354,23 @@ func determineDependency(v, dep *cfg.Dependency, dest, req string) *cfg.Dependen
 			singleWarn("Conflict: %s version is %s, but also asked for %s\n", v.Name, v.Reference, dep.Reference)
 			displayCommitInfo(repo, v)
 			singleInfo("Keeping %s %s", v.Name, v.Reference)
-			return v
+			return keepDependency(v, cfg.RuleKept)
 		}
 
 		if con.Check(ver) {
 			singleInfo("Keeping %s %s because it fits constraint '%s'", v.Name, v.Reference, dep.Reference)
-			return v
+			return keepDependency(v, cfg.RuleFits)
 		}
 		singleWarn("Conflict: %s version is %s but does not meet constraint '%s'\n", v.Name, v.Reference, dep.Reference)
 		singleInfo("Keeping %s %s", v.Name, v.Reference)
-		return v
+		return keepDependency(v, cfg.RuleKept)
 	} else if depIsRef {
 
 		con, err := semver.NewConstraint(v.Reference)
 		if err != nil {
 			singleWarn("Version issue for %s: '%s' is neither a reference or semantic version constraint\n", v.Name, v.Reference)
 			singleInfo("Keeping %s %s", v.Name, v.Reference)
-			return v
+			return keepDependency(v, cfg.RuleKept)
 		}
 
 		ver, err := semver.NewVersion(dep.Reference)
@@ -909,17 +1378,18 @@ func determineDependency(v, dep *cfg.Dependency, dest, req string) *cfg.Dependen
 			singleWarn("Conflict: %s version is %s, but also asked for %s\n", v.Name, v.Reference, dep.Reference)
 			displayCommitInfo(repo, dep)
 			singleInfo("Keeping %s %s", v.Name, v.Reference)
-			return v
+			return keepDependency(v, cfg.RuleKept)
 		}
 
 		if con.Check(ver) {
 			v.Reference = dep.Reference
+			v.Rule = cfg.RuleFits
 			singleInfo("Using %s %s because it fits constraint '%s'", v.Name, v.Reference, v.Reference)
 			return v
 		}
 		singleWarn("Conflict: %s semantic version constraint is %s but '%s' does not meet the constraint\n", v.Name, v.Reference, v.Reference)
 		singleInfo("Keeping %s %s", v.Name, v.Reference)
-		return v
+		return keepDependency(v, cfg.RuleKept)
 	}
 	// Neither is a vcs reference and both could be semantic version
 	// constraints that are different.
@@ -929,7 +1399,7 @@ func determineDependency(v, dep *cfg.Dependency, dest, req string) *cfg.Dependen
 		// dd.Reference is not a reference or a valid constraint.
 		singleWarn("Version %s %s is not a reference or valid semantic version constraint\n", dep.Name, dep.Reference)
 		singleInfo("Keeping %s %s", v.Name, v.Reference)
-		return v
+		return keepDependency(v, cfg.RuleKept)
 	}
 
 	_, err = semver.NewConstraint(v.Reference)
@@ -940,6 +1410,7 @@ func determineDependency(v, dep *cfg.Dependency, dest, req string) *cfg.Dependen
 
 		v.Reference = dep.Reference
 		v.Pin = ""
+		v.Rule = cfg.RuleFirst
 		singleInfo("Using %s %s because it is a valid version", v.Name, v.Reference)
 		return v
 	}
@@ -953,14 +1424,34 @@ func determineDependency(v, dep *cfg.Dependency, dest, req string) *cfg.Dependen
 		newRef := v.Reference + ", " + dep.Reference
 		v.Reference = newRef
 		v.Pin = ""
+		v.Rule = cfg.RuleCombined
 		singleInfo("Combining %s semantic version constraints %s and %s", v.Name, v.Reference, dep.Reference)
 		return v
 	}
 	singleWarn("Conflict: %s version is %s, but also asked for %s\n", v.Name, v.Reference, dep.Reference)
 	singleInfo("Keeping %s %s", v.Name, v.Reference)
+	return keepDependency(v, cfg.RuleKept)
+}
+
+// keepDependency keeps the version already chosen for a dependency. A version
+// set in the glide.yaml file keeps that as its rule.
+func keepDependency(v *cfg.Dependency, rule string) *cfg.Dependency {
+	if v.Rule != cfg.RuleConfig {
+		v.Rule = rule
+	}
 	return v
 }
 
+// sameCommit returns true if two references are the same commit.
+func sameCommit(repo vcs.Repo, a, b string) bool {
+	ca, err := repo.CommitInfo(a)
+	if err != nil {
+		return false
+	}
+	cb, err := repo.CommitInfo(b)
+	return err == nil && ca.Commit == cb.Commit
+}
+
 var warningMessage = make(map[string]bool)
 var infoMessage = make(map[string]bool)
 
@@ -985,12 +1476,17 @@ func singleInfo(ft string, v ...interface{}) {
 type importCache struct {
 	cache map[string]*cfg.Dependency
 	from  map[string]string
+
+	// reqs holds every package that asked for a dependency, not only the
+	// first one.
+	reqs map[string]cfg.Requirements
 }
 
 func newImportCache() *importCache {
 	return &importCache{
 		cache: make(map[string]*cfg.Dependency),
 		from:  make(map[string]string),
+		reqs:  make(map[string]cfg.Requirements),
 	}
 }
 
@@ -1008,6 +1504,16 @@ func (i *importCache) Add(name string, dep *cfg.Dependency, root string) {
 	i.from[name] = root
 }
 
+// Require records that root asked for the named dependency at a version.
+func (i *importCache) Require(name, root, version string) {
+	i.reqs[name] = i.reqs[name].Add(root, version)
+}
+
+// Requirements returns the packages that asked for the named dependency.
+func (i *importCache) Requirements(name string) cfg.Requirements {
+	return i.reqs[name].Clone()
+}
+
 var displayCommitInfoPrefix = msg.Default.Color(msg.Green, "[INFO] ")
 var displayCommitInfoTemplate = "%s reference %s:\n" +
 	displayCommitInfoPrefix + "- author: %s\n" +
@@ -0,0 +1,190 @@
+package repo
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/Masterminds/glide/cache"
+	"github.com/Masterminds/glide/cfg"
+	"github.com/Masterminds/glide/dependency"
+)
+
+func TestUpdateBacktrack(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	dir, err := ioutil.TempDir("", "glide-update")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	defer testHome(t, dir)()
+
+	repo := func(name string) string {
+		return "file://" + filepath.Join(dir, "repos", name)
+	}
+	for _, name := range []string{"b", "c", "d"} {
+		versions := []string{"1.0.0", "2.0.0"}
+		gitRepo(t, filepath.Join(dir, "repos", name), versions, map[string]map[string]string{
+			"1.0.0": {name + ".go": "package " + name + "\n"},
+		})
+	}
+	// The newest a imports c and needs a b the project does not allow. The
+	// older one imports d instead.
+	gitRepo(t, filepath.Join(dir, "repos", "a"), []string{"1.0.0", "2.0.0"}, map[string]map[string]string{
+		"1.0.0": {
+			"a.go":       "package a\n\nimport _ \"example.com/d\"\n",
+			"glide.yaml": "package: example.com/a\nimport:\n- package: example.com/b\n  version: ^1.0.0\n- package: example.com/d\n  repo: " + repo("d") + "\n  vcs: git\n",
+		},
+		"2.0.0": {
+			"a.go":       "package a\n\nimport _ \"example.com/c\"\n",
+			"glide.yaml": "package: example.com/a\nimport:\n- package: example.com/b\n  version: ^2.0.0\n- package: example.com/c\n  repo: " + repo("c") + "\n  vcs: git\n",
+		},
+	})
+
+	app := filepath.Join(dir, "app")
+	if err := os.MkdirAll(app, 0755); err != nil {
+		t.Fatal(err)
+	}
+	main := "package main\n\nimport (\n\t_ \"example.com/a\"\n\t_ \"example.com/b\"\n)\n\nfunc main() {}\n"
+	if err := ioutil.WriteFile(filepath.Join(app, "main.go"), []byte(main), 0644); err != nil {
+		t.Fatal(err)
+	}
+	yml := "package: example.com/app\nimport:\n- package: example.com/a\n  version: '>=1.0.0'\n  repo: " + repo("a") + "\n  vcs: git\n- package: example.com/b\n  version: ^1.0.0\n  repo: " + repo("b") + "\n  vcs: git\n"
+	if err := ioutil.WriteFile(filepath.Join(app, "glide.yaml"), []byte(yml), 0644); err != nil {
+		t.Fatal(err)
+	}
+	conf, err := cfg.ConfigFromYaml([]byte(yml))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(app); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	i := NewInstaller()
+	i.Backtrack = true
+	i.Graph = dependency.NewImportGraph()
+	// As glide up does, the dependencies listed are checked out first.
+	if err := i.Checkout(conf); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetReference(conf, false); err != nil {
+		t.Fatal(err)
+	}
+	if err := i.Update(conf); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	// The packages are those imported at the versions solved.
+	for name, ref := range map[string]string{"example.com/a": "1.0.0", "example.com/b": "1.0.0"} {
+		if d := conf.Imports.Get(name); d == nil || d.Reference != ref {
+			t.Errorf("Expected %s %s but got %v", name, ref, d)
+		}
+	}
+	if !conf.Imports.Has("example.com/d") {
+		t.Error("Expected example.com/d, imported by the version of a solved, to be resolved")
+	}
+	if conf.Imports.Has("example.com/c") {
+		t.Error("Expected example.com/c, only imported by a version of a not used, to be dropped")
+	}
+	if _, ok := i.Graph.Imports["example.com/c"]; ok {
+		t.Error("Expected the import graph to be of the versions solved")
+	}
+}
+
+func TestUpdateRequiredBy(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	dir, err := ioutil.TempDir("", "glide-update")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	defer testHome(t, dir)()
+
+	// a imports c in its code but does not list it in a manifest.
+	gitRepo(t, filepath.Join(dir, "repos", "a"), []string{"1.0.0"}, map[string]map[string]string{
+		"1.0.0": {"a.go": "package a\n\nimport _ \"example.com/c\"\n"},
+	})
+	gitRepo(t, filepath.Join(dir, "repos", "c"), []string{"1.0.0"}, map[string]map[string]string{
+		"1.0.0": {"c.go": "package c\n"},
+	})
+	// c has no repo to fetch it from so it is put in the cache beforehand.
+	cache.Setup()
+	key, err := cache.Key("https://example.com/c")
+	if err != nil {
+		t.Fatal(err)
+	}
+	git(t, dir, "clone", "-q", filepath.Join(dir, "repos", "c"), filepath.Join(cache.Location(), "src", key))
+
+	app := filepath.Join(dir, "app")
+	if err := os.MkdirAll(app, 0755); err != nil {
+		t.Fatal(err)
+	}
+	main := "package main\n\nimport _ \"example.com/a\"\n\nfunc main() {}\n"
+	if err := ioutil.WriteFile(filepath.Join(app, "main.go"), []byte(main), 0644); err != nil {
+		t.Fatal(err)
+	}
+	yml := "package: example.com/app\nimport:\n- package: example.com/a\n  version: ^1.0.0\n  repo: file://" + filepath.Join(dir, "repos", "a") + "\n  vcs: git\n"
+	if err := ioutil.WriteFile(filepath.Join(app, "glide.yaml"), []byte(yml), 0644); err != nil {
+		t.Fatal(err)
+	}
+	conf, err := cfg.ConfigFromYaml([]byte(yml))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(app); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	i := NewInstaller()
+	if err := i.Checkout(conf); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetReference(conf, false); err != nil {
+		t.Fatal(err)
+	}
+	if err := i.Update(conf); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	tests := []struct {
+		name, by, version, rule string
+	}{
+		{"example.com/a", "example.com/app", "^1.0.0", cfg.RuleConfig},
+		{"example.com/c", "example.com/a", "", cfg.RuleDefault},
+	}
+	for _, tt := range tests {
+		d := conf.Imports.Get(tt.name)
+		if d == nil {
+			t.Errorf("Expected %s to be resolved", tt.name)
+			continue
+		}
+		if len(d.RequiredBy) != 1 || d.RequiredBy[0].Package != tt.by || d.RequiredBy[0].Version != tt.version {
+			t.Errorf("Expected %s to be required by %s at %q but got %v", tt.name, tt.by, tt.version, d.RequiredBy)
+		}
+		if d.Rule != tt.rule {
+			t.Errorf("Expected the rule for %s to be %s but got %s", tt.name, tt.rule, d.Rule)
+		}
+	}
+	if i.Graph != nil {
+		t.Error("Expected the import graph used for the requirements to be cleared")
+	}
+}
@@ -0,0 +1,99 @@
+package repo
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/Masterminds/glide/cfg"
+	"github.com/Masterminds/glide/msg"
+	gpath "github.com/Masterminds/glide/path"
+	"github.com/Masterminds/vcs"
+)
+
+// LocalPath returns the directory a dependency with a local path override
+// uses in place of its cached repository.
+func LocalPath(dep *cfg.Dependency) string {
+	p := filepath.FromSlash(dep.Path)
+	if filepath.IsAbs(p) {
+		return p
+	}
+	return filepath.Join(gpath.Basepath(), p)
+}
+
+// checkLocal makes sure the local path a dependency is overridden with exists.
+func checkLocal(dep *cfg.Dependency) error {
+	p := LocalPath(dep)
+	fi, err := os.Stat(p)
+	if err != nil || !fi.IsDir() {
+		return fmt.Errorf("Local path %s for %s is not a directory", dep.Path, dep.Name)
+	}
+	msg.Info("--> Using local path %s for %s", dep.Path, dep.Name)
+	return nil
+}
+
+// pinLocal pins a dependency with a local path override to the version
+// checked out in the local path. When the local path is not a VCS checkout
+// the dependency is left unpinned.
+func pinLocal(dep *cfg.Dependency) error {
+	if err := checkLocal(dep); err != nil {
+		return err
+	}
+	repo, err := LocalRepo(LocalPath(dep))
+	if err != nil {
+		msg.Debug("Local path %s for %s is not a VCS checkout", dep.Path, dep.Name)
+		return nil
+	}
+	dep.Pin, err = repo.Version()
+	if err != nil {
+		return err
+	}
+	if repo.IsDirty() {
+		msg.Warn("Local path %s for %s has uncommitted changes", dep.Path, dep.Name)
+	}
+	return nil
+}
+
+// exportLocal copies a local path override to dest. VCS metadata is skipped.
+func exportLocal(dep *cfg.Dependency, dest string) error {
+	src := LocalPath(dep)
+	return filepath.Walk(src, func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dest, rel)
+		if fi.IsDir() {
+			if path != src && digestSkipDirs[fi.Name()] {
+				return filepath.SkipDir
+			}
+			return os.MkdirAll(target, fi.Mode()|0700)
+		}
+		return gpath.CopyFile(path, target)
+	})
+}
+
+// LocalRepo returns a VCS repo for a directory based solely on what is on
+// disk. No remote location is used so no network access is needed.
+func LocalRepo(dir string) (vcs.Repo, error) {
+	t, err := vcs.DetectVcsFromFS(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	switch t {
+	case vcs.Git:
+		return vcs.NewGitRepo("", dir)
+	case vcs.Svn:
+		return vcs.NewSvnRepo("", dir)
+	case vcs.Hg:
+		return vcs.NewHgRepo("", dir)
+	case vcs.Bzr:
+		return vcs.NewBzrRepo("", dir)
+	}
+
+	return nil, vcs.ErrCannotDetectVCS
+}
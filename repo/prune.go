@@ -0,0 +1,356 @@
+package repo
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Masterminds/glide/cfg"
+	"github.com/Masterminds/glide/dependency"
+	"github.com/Masterminds/glide/gomod"
+	"github.com/Masterminds/glide/msg"
+	gpath "github.com/Masterminds/glide/path"
+)
+
+// buildExts are the extensions of the files the go tool reads when building a
+// package, other than Go files.
+var buildExts = map[string]bool{
+	".go": true, ".s": true, ".S": true, ".sx": true, ".c": true, ".h": true,
+	".cc": true, ".cpp": true, ".cxx": true, ".hh": true, ".hpp": true,
+	".hxx": true, ".m": true, ".f": true, ".F": true, ".for": true,
+	".f90": true, ".syso": true, ".swig": true, ".swigcxx": true,
+}
+
+// legalPrefixes are the starts of the names of license and similar files.
+// They are kept whatever the prune rules.
+var legalPrefixes = []string{
+	"license", "licence", "copying", "unlicense", "copyright", "copyleft",
+	"legal", "notice", "disclaimer", "patent", "authors", "contributors",
+}
+
+// pruneVendor removes the files selected by the prune rules of the config from
+// the dependencies exported to the vendor directory vp. What was removed from
+// each is recorded in the prune file in vp. Dependencies copied from a local
+// path are left as they are.
+func (i *Installer) pruneVendor(conf *cfg.Config, deps cfg.Dependencies, vp string) error {
+	var reached map[string]bool
+	for _, d := range deps {
+		if d.Path == "" && conf.PruneRules(d.Name).UnusedPackages {
+			var err error
+			reached, err = i.reachedPackages(conf, deps, vp)
+			if err != nil {
+				msg.Warn("Unable to find the packages in use, not removing unused packages: %s", err)
+			}
+			break
+		}
+	}
+
+	roots := make(map[string]bool, len(deps))
+	for _, d := range deps {
+		roots[d.Name] = true
+	}
+
+	msg.Info("Pruning the vendor directory...")
+	m := &cfg.PruneManifest{}
+	for _, d := range deps {
+		rules := conf.PruneRules(d.Name)
+		if d.Path != "" || !rules.Any() {
+			continue
+		}
+		if reached == nil {
+			rules.UnusedPackages = false
+		}
+		dir := VendoredDir(vp, d.Name, d.Subpackages)
+		removed, err := pruneDir(vp, dir, rules, reached, roots)
+		if err != nil {
+			return err
+		}
+		pd, err := Digest(dir)
+		if err != nil {
+			return err
+		}
+		msg.Debug("Pruned %d files from %s", len(removed), d.Name)
+		m.Pruned = append(m.Pruned, &cfg.PrunedDependency{
+			Name:         d.Name,
+			Digest:       d.Digest,
+			PrunedDigest: pd,
+			Removed:      removed,
+		})
+	}
+	if len(m.Pruned) == 0 {
+		return nil
+	}
+	return m.WriteFile(filepath.Join(vp, gpath.PruneFile))
+}
+
+// reachedPackages returns the directories, relative to vp, of the packages
+// reached from the packages of the project through the dependencies exported
+// to vp and the packages of those dependencies listed in the config.
+func (i *Installer) reachedPackages(conf *cfg.Config, deps cfg.Dependencies, vp string) (map[string]bool, error) {
+	r, err := dependency.NewResolver(filepath.Dir(i.VendorPath()))
+	if err != nil {
+		return nil, err
+	}
+	// The resolver adds the packages it finds to its config.
+	r.Config = conf.Clone()
+	r.VendorDir = vp
+	r.Handler = pruneHandler{prefix: vp}
+	r.ResolveTest = i.ResolveTest
+	r.Graph = dependency.NewImportGraph()
+
+	imps, timps, err := r.ResolveLocal(false)
+	if err != nil {
+		return nil, err
+	}
+	all := append(cfg.Dependencies{}, deps...)
+	for _, p := range append(imps, timps...) {
+		all = append(all, &cfg.Dependency{Name: r.Stripv(p)})
+	}
+	if _, err := r.ResolveAll(all, false); err != nil {
+		return nil, err
+	}
+
+	// The packages are recorded by the directories they are in as the major
+	// version suffix of a major branch is not one.
+	reached := make(map[string]bool, len(r.Graph.Imports))
+	for p := range r.Graph.Imports {
+		reached[gomod.ImportDir(vp, p)] = true
+	}
+	return reached, nil
+}
+
+// pruneDir removes the files selected by the rules from the directory of a
+// dependency in the vendor directory vp. The directories of the other
+// dependencies in roots are skipped. The slash separated paths, relative to
+// dir, of the files removed are returned in order.
+func pruneDir(vp, dir string, rules cfg.PruneRules, reached, roots map[string]bool) ([]string, error) {
+	importPath := func(p string) string {
+		rel, _ := filepath.Rel(vp, p)
+		return filepath.ToSlash(rel)
+	}
+
+	// Find the Go packages and the files they embed first as the files of a
+	// package may be embedded by a package above it.
+	pkgs := map[string]bool{}
+	embedded := map[string]bool{}
+	err := filepath.Walk(dir, func(p string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !fi.IsDir() {
+			return nil
+		}
+		if p != dir && roots[importPath(p)] {
+			return filepath.SkipDir
+		}
+		goPkg, patterns, err := scanGoFiles(p)
+		if err != nil {
+			return err
+		}
+		pkgs[p] = goPkg
+		for _, pat := range patterns {
+			matches, _ := filepath.Glob(filepath.Join(p, filepath.FromSlash(pat)))
+			for _, mp := range matches {
+				embedded[mp] = true
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	isEmbedded := func(p string) bool {
+		for e := p; e != dir && e != filepath.Dir(e); e = filepath.Dir(e) {
+			if embedded[e] {
+				return true
+			}
+		}
+		return false
+	}
+
+	var removed []string
+	var dirs []string
+	err = filepath.Walk(dir, func(p string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if fi.IsDir() {
+			if p == dir {
+				return nil
+			}
+			if roots[importPath(p)] {
+				return filepath.SkipDir
+			}
+			dirs = append(dirs, p)
+			if rules.Testdata && fi.Name() == "testdata" {
+				files, err := removeAll(p)
+				for _, f := range files {
+					rel, _ := filepath.Rel(dir, f)
+					removed = append(removed, filepath.ToSlash(rel))
+				}
+				if err != nil {
+					return err
+				}
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		name := fi.Name()
+		if isLegalFile(name) {
+			return nil
+		}
+		pdir := filepath.Dir(p)
+		rel, _ := filepath.Rel(dir, p)
+		rel = filepath.ToSlash(rel)
+		nested := strings.HasPrefix(rel, "vendor/") || strings.Contains(rel, "/vendor/")
+
+		remove := false
+		switch {
+		case rules.UnusedPackages && pkgs[pdir] && !nested && !reached[importPath(pdir)]:
+			remove = true
+		case rules.GoTests && strings.HasSuffix(name, "_test.go"):
+			remove = true
+		case rules.NonGoFiles && !buildExts[filepath.Ext(name)] && name != "go.mod" && !isEmbedded(p):
+			remove = true
+		}
+		if !remove {
+			return nil
+		}
+		if err := os.Remove(p); err != nil {
+			return err
+		}
+		removed = append(removed, rel)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Remove the directories left empty, deepest first.
+	sort.Sort(sort.Reverse(sort.StringSlice(dirs)))
+	for _, d := range dirs {
+		if f, err := os.Open(d); err == nil {
+			_, err = f.Readdirnames(1)
+			f.Close()
+			if err != nil {
+				os.Remove(d)
+			}
+		}
+	}
+
+	sort.Strings(removed)
+	return removed, nil
+}
+
+// scanGoFiles reports whether a directory holds a Go package, other than its
+// tests, and returns the patterns of the files embedded by its Go files.
+func scanGoFiles(dir string) (bool, []string, error) {
+	f, err := os.Open(dir)
+	if err != nil {
+		return false, nil, err
+	}
+	names, err := f.Readdirnames(-1)
+	f.Close()
+	if err != nil {
+		return false, nil, err
+	}
+
+	goPkg := false
+	var patterns []string
+	for _, n := range names {
+		if filepath.Ext(n) != ".go" || strings.HasSuffix(n, "_test.go") {
+			continue
+		}
+		goPkg = true
+		ps, err := embedPatterns(filepath.Join(dir, n))
+		if err != nil {
+			return false, nil, err
+		}
+		patterns = append(patterns, ps...)
+	}
+	return goPkg, patterns, nil
+}
+
+// embedPatterns returns the patterns of the //go:embed directives in a Go
+// file. The all: prefix is dropped.
+func embedPatterns(file string) ([]string, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var patterns []string
+	s := bufio.NewScanner(f)
+	s.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		if !strings.HasPrefix(line, "//go:embed ") {
+			continue
+		}
+		for _, p := range strings.Fields(strings.TrimPrefix(line, "//go:embed ")) {
+			if uq, err := strconv.Unquote(p); err == nil {
+				p = uq
+			}
+			patterns = append(patterns, strings.TrimPrefix(p, "all:"))
+		}
+	}
+	return patterns, s.Err()
+}
+
+// isLegalFile returns true if a file name looks like a license or similar
+// file.
+func isLegalFile(name string) bool {
+	n := strings.ToLower(name)
+	for _, p := range legalPrefixes {
+		if strings.HasPrefix(n, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// removeAll removes a directory and returns the files that were in it.
+func removeAll(dir string) ([]string, error) {
+	var files []string
+	err := filepath.Walk(dir, func(p string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !fi.IsDir() {
+			files = append(files, p)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return files, os.RemoveAll(dir)
+}
+
+// pruneHandler finds packages in the vendor directory being exported without
+// reporting those missing from it, such as packages for other platforms.
+type pruneHandler struct {
+	prefix string
+}
+
+func (pruneHandler) NotFound(pkg string, addTest bool) (bool, error) {
+	return false, nil
+}
+
+func (pruneHandler) OnGopath(pkg string, addTest bool) (bool, error) {
+	return false, nil
+}
+
+func (pruneHandler) InVendor(pkg string, addTest bool) error {
+	return nil
+}
+
+func (h pruneHandler) PkgPath(pkg string) string {
+	return filepath.Join(h.prefix, filepath.FromSlash(gomod.ImportDir(h.prefix, pkg)))
+}
@@ -0,0 +1,113 @@
+package repo
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/Masterminds/glide/cfg"
+)
+
+func TestPruneDir(t *testing.T) {
+	vp, err := ioutil.TempDir("", "glide-prune")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(vp)
+
+	files := map[string]string{
+		"example.com/foo/foo.go":              "package foo\n\n//go:embed static\nvar static string\n",
+		"example.com/foo/foo_test.go":         "package foo\n",
+		"example.com/foo/README.md":           "# foo\n",
+		"example.com/foo/LICENSE":             "MIT\n",
+		"example.com/foo/go.mod":              "module example.com/foo\n",
+		"example.com/foo/cgo.c":               "int x;\n",
+		"example.com/foo/static/index.html":   "<html></html>\n",
+		"example.com/foo/testdata/in.txt":     "in\n",
+		"example.com/foo/unused/unused.go":    "package unused\n",
+		"example.com/foo/unused/NOTICE":       "notice\n",
+		"example.com/foo/docs/guide.md":       "guide\n",
+		"example.com/foo/vendor/a/b/b.go":     "package b\n",
+		"example.com/foo/bar/bar.go":          "package bar\n",
+		"example.com/foo/bar/bar_test.go":     "package bar\n",
+		"example.com/foo/other/other.go":      "package other\n",
+		"example.com/foo/other/other_test.go": "package other\n",
+	}
+	for name, content := range files {
+		p := filepath.Join(vp, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	reached := map[string]bool{
+		"example.com/foo":     true,
+		"example.com/foo/bar": true,
+	}
+	roots := map[string]bool{
+		"example.com/foo":       true,
+		"example.com/foo/other": true,
+	}
+	dir := filepath.Join(vp, "example.com", "foo")
+	removed, err := pruneDir(vp, dir, cfg.AllPruneRules, reached, roots)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		"README.md",
+		"bar/bar_test.go",
+		"docs/guide.md",
+		"foo_test.go",
+		"testdata/in.txt",
+		"unused/unused.go",
+	}
+	if !reflect.DeepEqual(removed, expected) {
+		t.Errorf("Expected %v to be removed but got %v", expected, removed)
+	}
+	for _, name := range []string{"docs", "testdata"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); !os.IsNotExist(err) {
+			t.Errorf("Expected the directory %s to be removed", name)
+		}
+	}
+	for _, name := range []string{
+		"foo.go", "LICENSE", "go.mod", "cgo.c", "static/index.html",
+		"unused/NOTICE", "vendor/a/b/b.go", "bar/bar.go", "other/other_test.go",
+	} {
+		if _, err := os.Stat(filepath.Join(dir, filepath.FromSlash(name))); err != nil {
+			t.Errorf("Expected %s to be kept: %s", name, err)
+		}
+	}
+
+	// Only the rules given are used.
+	removed, err = pruneDir(vp, filepath.Join(dir, "other"), cfg.PruneRules{GoTests: true}, nil, roots)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(removed, []string{"other_test.go"}) {
+		t.Errorf("Expected only the test to be removed but got %v", removed)
+	}
+}
+
+func TestIsLegalFile(t *testing.T) {
+	tests := map[string]bool{
+		"LICENSE":      true,
+		"license.txt":  true,
+		"COPYING":      true,
+		"NOTICE.md":    true,
+		"AUTHORS":      true,
+		"README.md":    false,
+		"Makefile":     false,
+		"licensing.go": false,
+	}
+	for name, expected := range tests {
+		if isLegalFile(name) != expected {
+			t.Errorf("Expected isLegalFile(%q) to be %t", name, expected)
+		}
+	}
+}
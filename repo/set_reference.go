@@ -13,7 +13,8 @@ import (
 // a project.
 func SetReference(conf *cfg.Config, resolveTest bool) error {
 
-	if len(conf.Imports) == 0 && len(conf.DevImports) == 0 {
+	groups := conf.GroupDependencies()
+	if len(conf.Imports) == 0 && len(conf.DevImports) == 0 && len(groups) == 0 {
 		msg.Info("No references set.\n")
 		return nil
 	}
@@ -79,6 +80,13 @@ func SetReference(conf *cfg.Config, resolveTest bool) error {
 		}
 	}
 
+	for _, dep := range groups {
+		if !conf.HasIgnore(dep.Name) {
+			wg.Add(1)
+			in <- dep
+		}
+	}
+
 	wg.Wait()
 	// Close goroutines setting the version
 	for i := 0; i < concurrentWorkers; i++ {
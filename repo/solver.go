@@ -0,0 +1,568 @@
+package repo
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Masterminds/glide/cache"
+	"github.com/Masterminds/glide/cfg"
+	"github.com/Masterminds/glide/importer"
+	"github.com/Masterminds/glide/msg"
+	"github.com/Masterminds/semver"
+)
+
+// maxSolveSteps limits the number of versions the solver tries before giving
+// up. Backtracking is exponential in the worst case.
+const maxSolveSteps = 10000
+
+// maxSolveRounds limits the number of times the imports are walked again at
+// the versions solved before giving up. The versions solved change the
+// packages imported, which may change the versions solved.
+const maxSolveRounds = 10
+
+// SolverSource provides the versions of dependencies and what each version
+// requires of other dependencies.
+type SolverSource interface {
+	// Versions returns the references of a dependency, its branches and
+	// tags.
+	Versions(name string) ([]string, error)
+
+	// Requirements returns the versions a dependency at a version asks for,
+	// keyed by the name of the dependency they are asked of. An empty version
+	// is the version checked out when no version is asked for.
+	Requirements(name, version string) (map[string]string, error)
+}
+
+// SolveRequirement is a version a package asks of a dependency.
+type SolveRequirement struct {
+	// Name is the dependency the version is asked of.
+	Name string
+
+	// Version is a semantic version constraint or a reference, such as a
+	// branch or commit id. It is empty when any version will do.
+	Version string
+
+	// By is the package asking for the version and ByVersion its version.
+	// The version is empty for the project itself.
+	By        string
+	ByVersion string
+}
+
+// SolveError is returned when no versions satisfy every requirement. The
+// requirements listed are a minimal set that cannot be satisfied together:
+// dropping any one of them would allow a solution.
+type SolveError struct {
+	Requirements []SolveRequirement
+
+	// Incomplete is set when the solver gave up before finding a solution or
+	// proving there is none.
+	Incomplete bool
+}
+
+func (e *SolveError) Error() string {
+	if e.Incomplete {
+		return fmt.Sprintf("Gave up after trying %d versions without finding versions that satisfy every requirement", maxSolveSteps)
+	}
+	lines := []string{"No versions of the dependencies satisfy all of these requirements:"}
+	for _, r := range e.Requirements {
+		v := r.Version
+		if v == "" {
+			v = "(any version)"
+		}
+		by := r.By
+		if r.ByVersion != "" {
+			by = by + " " + r.ByVersion
+		}
+		lines = append(lines, fmt.Sprintf("\t%s %s required by %s", r.Name, v, by))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Solver chooses a version for each dependency that satisfies every package
+// asking for it. When a choice leaves no version of another dependency that
+// fits, it backtracks and tries an older version.
+type Solver struct {
+	Source SolverSource
+
+	// Root is the name of the project.
+	Root string
+
+	root     []SolveRequirement
+	versions map[string][]string
+	reqs     map[string][]SolveRequirement
+	seen     []SolveRequirement
+	known    map[SolveRequirement]bool
+	removed  map[SolveRequirement]bool
+	steps    int
+}
+
+// NewSolver returns a Solver for a project. This is the constructor.
+func NewSolver(root string, src SolverSource) *Solver {
+	return &Solver{
+		Source:   src,
+		Root:     root,
+		versions: make(map[string][]string),
+		reqs:     make(map[string][]SolveRequirement),
+		known:    make(map[SolveRequirement]bool),
+	}
+}
+
+// Solve returns a version for every dependency asked for, starting with the
+// versions the project asks for. Dependencies nothing asks a version of are
+// given an empty version.
+//
+// Params:
+//  - reqs (map[string]string): The versions the project asks for, keyed by name
+func (s *Solver) Solve(reqs map[string]string) (map[string]string, error) {
+	s.root = s.requirements(s.Root, "", reqs)
+
+	sol, ok := s.satisfiable()
+	if ok {
+		return sol, nil
+	}
+	if s.steps > maxSolveSteps {
+		return nil, &SolveError{Incomplete: true}
+	}
+	return nil, &SolveError{Requirements: s.explain()}
+}
+
+// Required returns the requirements of the project and of each dependency at
+// its version in a solution.
+func (s *Solver) Required(sol map[string]string) []SolveRequirement {
+	names := make([]string, 0, len(sol))
+	for name := range sol {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	rs := append([]SolveRequirement{}, s.root...)
+	for _, name := range names {
+		r, _ := s.requirementsOf(name, sol[name])
+		rs = append(rs, r...)
+	}
+	return rs
+}
+
+// satisfiable searches for a solution ignoring removed requirements.
+func (s *Solver) satisfiable() (map[string]string, bool) {
+	s.steps = 0
+	assigned := map[string]string{}
+	if s.search(assigned, nil) {
+		return assigned, true
+	}
+	return nil, false
+}
+
+func (s *Solver) search(assigned map[string]string, stack []string) bool {
+	act := s.active(stack, assigned)
+
+	next := ""
+	for _, r := range act {
+		if _, ok := assigned[r.Name]; !ok {
+			next = r.Name
+			break
+		}
+	}
+	if next == "" {
+		return true
+	}
+
+	var on []SolveRequirement
+	for _, r := range act {
+		if r.Name == next {
+			on = append(on, r)
+		}
+	}
+
+	for _, c := range s.candidates(next, on) {
+		s.steps++
+		if s.steps > maxSolveSteps {
+			return false
+		}
+		if !allowsAll(on, c) {
+			continue
+		}
+		rs, err := s.requirementsOf(next, c)
+		if err != nil {
+			msg.Debug("Unable to read the requirements of %s %s: %s", next, c, err)
+			continue
+		}
+		fits := true
+		for _, r := range rs {
+			if v, ok := assigned[r.Name]; ok && !s.removed[r] && !allows(r.Version, v) {
+				fits = false
+				break
+			}
+		}
+		if !fits {
+			continue
+		}
+
+		assigned[next] = c
+		if s.search(assigned, append(stack, next)) {
+			return true
+		}
+		delete(assigned, next)
+	}
+	return false
+}
+
+// active returns the requirements of the project and of the dependencies
+// assigned so far, in the order they were assigned.
+func (s *Solver) active(stack []string, assigned map[string]string) []SolveRequirement {
+	var act []SolveRequirement
+	for _, r := range s.root {
+		if !s.removed[r] {
+			act = append(act, r)
+		}
+	}
+	for _, name := range stack {
+		rs, _ := s.requirementsOf(name, assigned[name])
+		for _, r := range rs {
+			if !s.removed[r] {
+				act = append(act, r)
+			}
+		}
+	}
+	return act
+}
+
+// candidates returns the versions of a dependency to try, best first. The
+// references asked for come first as nothing else can satisfy them. When no
+// version is asked for the version checked out is preferred. The semantic
+// versions follow, newest first, and then the other references, such as
+// branches.
+func (s *Solver) candidates(name string, on []SolveRequirement) []string {
+	var c []string
+	add := func(v string) {
+		for _, e := range c {
+			if e == v {
+				return
+			}
+		}
+		c = append(c, v)
+	}
+
+	constrained := false
+	for _, r := range on {
+		if r.Version == "" {
+			continue
+		}
+		constrained = true
+		if _, err := semver.NewConstraint(r.Version); err != nil {
+			add(r.Version)
+		}
+	}
+	if !constrained {
+		add("")
+	}
+
+	vers, ok := s.versions[name]
+	if !ok {
+		var err error
+		vers, err = s.Source.Versions(name)
+		if err != nil {
+			msg.Debug("Unable to list the versions of %s: %s", name, err)
+		}
+		svs := getSemVers(vers)
+		sort.Sort(sort.Reverse(semver.Collection(svs)))
+		sorted := make([]string, 0, len(vers))
+		for _, v := range svs {
+			sorted = append(sorted, v.Original())
+		}
+		for _, v := range vers {
+			if _, err := semver.NewVersion(v); err != nil {
+				sorted = append(sorted, v)
+			}
+		}
+		vers = sorted
+		s.versions[name] = vers
+	}
+	for _, v := range vers {
+		add(v)
+	}
+	return c
+}
+
+func (s *Solver) requirementsOf(name, version string) ([]SolveRequirement, error) {
+	key := name + "@" + version
+	if rs, ok := s.reqs[key]; ok {
+		return rs, nil
+	}
+	m, err := s.Source.Requirements(name, version)
+	if err != nil {
+		return nil, err
+	}
+	rs := s.requirements(name, version, m)
+	s.reqs[key] = rs
+	return rs, nil
+}
+
+// requirements converts the versions a package asks for into requirements,
+// sorted by name, and records them for explaining a conflict.
+func (s *Solver) requirements(by, version string, m map[string]string) []SolveRequirement {
+	var rs []SolveRequirement
+	for name, v := range m {
+		if name == by || name == s.Root {
+			continue
+		}
+		rs = append(rs, SolveRequirement{Name: name, Version: v, By: by, ByVersion: version})
+	}
+	sort.Slice(rs, func(i, j int) bool { return rs[i].Name < rs[j].Name })
+	for _, r := range rs {
+		if !s.known[r] {
+			s.known[r] = true
+			s.seen = append(s.seen, r)
+		}
+	}
+	return rs
+}
+
+// explain finds a minimal set of requirements that cannot be satisfied
+// together. Each requirement is dropped in turn and kept dropped when the
+// rest still cannot be satisfied. Requirements found while searching are
+// appended to those to try.
+func (s *Solver) explain() []SolveRequirement {
+	s.removed = make(map[SolveRequirement]bool)
+	for i := 0; i < len(s.seen); i++ {
+		r := s.seen[i]
+		if r.Version == "" {
+			// Asking for any version constrains nothing.
+			s.removed[r] = true
+			continue
+		}
+		s.removed[r] = true
+		if _, ok := s.satisfiable(); ok || s.steps > maxSolveSteps {
+			delete(s.removed, r)
+		}
+	}
+
+	var core []SolveRequirement
+	for _, r := range s.seen {
+		if !s.removed[r] {
+			core = append(core, r)
+		}
+	}
+	return core
+}
+
+func allowsAll(rs []SolveRequirement, version string) bool {
+	for _, r := range rs {
+		if !allows(r.Version, version) {
+			return false
+		}
+	}
+	return true
+}
+
+// allows returns true if a version satisfies a version asked for, either by
+// being the same reference or by fitting the semantic version constraint.
+func allows(want, version string) bool {
+	if want == "" || want == version {
+		return true
+	}
+	con, err := semver.NewConstraint(want)
+	if err != nil {
+		return false
+	}
+	sv, err := semver.NewVersion(version)
+	return err == nil && con.Check(sv)
+}
+
+// cacheSource reads the versions of dependencies, and the configuration of each
+// version, from the repositories in the cache.
+type cacheSource struct {
+	conf     *cfg.Config
+	importer importer.Importer
+
+	// current holds the version each repository had checked out before the
+	// solver checked out others. They are checked out again by restore.
+	current map[string]string
+}
+
+func newCacheSource(conf *cfg.Config, imp importer.Importer) *cacheSource {
+	return &cacheSource{
+		conf:     conf,
+		importer: imp,
+		current:  make(map[string]string),
+	}
+}
+
+func (c *cacheSource) dependency(name string) *cfg.Dependency {
+	for _, d := range solverDependencies(c.conf) {
+		if d.Name == name {
+			return d
+		}
+	}
+	return nil
+}
+
+func (c *cacheSource) Versions(name string) ([]string, error) {
+	dep := c.dependency(name)
+	if dep == nil || dep.Path != "" {
+		return nil, nil
+	}
+	key, err := cache.Key(dep.Remote())
+	if err != nil {
+		return nil, err
+	}
+	repo, err := dep.GetRepo(filepath.Join(cache.Location(), "src", key))
+	if err != nil {
+		return nil, err
+	}
+	return getAllVcsRefs(repo)
+}
+
+func (c *cacheSource) Requirements(name, version string) (map[string]string, error) {
+	dep := c.dependency(name)
+	if dep == nil {
+		return nil, nil
+	}
+
+	dir := LocalPath(dep)
+	if dep.Path == "" {
+		key, err := cache.Key(dep.Remote())
+		if err != nil {
+			return nil, err
+		}
+		dir = filepath.Join(cache.Location(), "src", key)
+		cache.Lock(key)
+		defer cache.Unlock(key)
+
+		repo, err := dep.GetRepo(dir)
+		if err != nil {
+			return nil, err
+		}
+		cur, ok := c.current[name]
+		if !ok {
+			if cur, err = repo.Version(); err != nil {
+				return nil, err
+			}
+			c.current[name] = cur
+		}
+		if version == "" {
+			version = cur
+		}
+		if err := repo.UpdateVersion(version); err != nil {
+			return nil, err
+		}
+	} else if version != "" {
+		return nil, fmt.Errorf("%s is overridden by a local path", name)
+	}
+
+	found, deps, err := c.importer.Import(dir)
+	if err != nil || !found {
+		return nil, err
+	}
+	m := map[string]string{}
+	for _, d := range deps {
+		// Only dependencies the project uses are solved for. A dependency
+		// overridden by a local path is used whatever version is asked for.
+		if pd := c.dependency(d.Name); pd != nil && pd.Path != "" {
+			m[d.Name] = ""
+		} else if pd != nil {
+			m[d.Name] = d.Reference
+		}
+	}
+	return m, nil
+}
+
+// restore checks out the versions the repositories had before the solver
+// checked out others.
+func (c *cacheSource) restore() {
+	names := make([]string, 0, len(c.current))
+	for name := range c.current {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if err := c.checkout(name, c.current[name]); err != nil {
+			msg.Warn("Unable to check out %s %s again after solving versions: %s", name, c.current[name], err)
+		}
+	}
+}
+
+// checkout checks out a version of a dependency in the cache.
+func (c *cacheSource) checkout(name, version string) error {
+	dep := c.dependency(name)
+	if dep == nil || dep.Path != "" {
+		return nil
+	}
+	key, err := cache.Key(dep.Remote())
+	if err != nil {
+		return err
+	}
+	cache.Lock(key)
+	defer cache.Unlock(key)
+
+	repo, err := dep.GetRepo(filepath.Join(cache.Location(), "src", key))
+	if err != nil {
+		return err
+	}
+	return repo.UpdateVersion(version)
+}
+
+// solverDependencies returns the dependencies in every section of a config.
+func solverDependencies(conf *cfg.Config) cfg.Dependencies {
+	deps := append(append(cfg.Dependencies{}, conf.Imports...), conf.DevImports...)
+	for _, name := range conf.GroupNames() {
+		deps = append(deps, conf.Groups[name]...)
+	}
+	return append(deps, conf.Tools...)
+}
+
+// solveVersions chooses the versions of the dependencies of a resolved config
+// with the backtracking solver and sets them as the references to check out.
+// It returns true if any reference changed, in which case the imports were
+// walked at other versions than those solved.
+func solveVersions(conf *cfg.Config, ic *importCache, imp importer.Importer) (bool, error) {
+	msg.Info("Solving versions")
+
+	reqs := map[string]string{}
+	deps := solverDependencies(conf)
+	for _, d := range deps {
+		for _, r := range ic.Requirements(d.Name) {
+			if r.Package == conf.Name && d.Path == "" {
+				reqs[d.Name] = r.Version
+			} else if r.Package == conf.Name {
+				reqs[d.Name] = ""
+			}
+		}
+	}
+
+	src := newCacheSource(conf, imp)
+	// The solver checks out the versions it tries in the cache. The versions
+	// chosen are checked out once the references are set.
+	defer src.restore()
+	s := NewSolver(conf.Name, src)
+	sol, err := s.Solve(reqs)
+	if err != nil {
+		return false, err
+	}
+
+	// The packages that asked for each dependency are now those at the solved
+	// versions rather than those the imports were first read from.
+	ic.reqs = make(map[string]cfg.Requirements)
+	for _, r := range s.Required(sol) {
+		ic.Require(r.Name, r.By, r.Version)
+	}
+
+	changed := false
+	for _, d := range deps {
+		v := sol[d.Name]
+		if v == "" || d.Path != "" {
+			continue
+		}
+		if v != d.Reference {
+			msg.Debug("--> Solved %s to %s", d.Name, v)
+			changed = true
+		}
+		d.Reference = v
+		d.Pin = ""
+		keepDependency(d, cfg.RuleSolved)
+	}
+	return changed, nil
+}
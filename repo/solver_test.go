@@ -0,0 +1,271 @@
+package repo
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Masterminds/glide/cache"
+	"github.com/Masterminds/glide/cfg"
+	"github.com/Masterminds/glide/importer"
+	gpath "github.com/Masterminds/glide/path"
+)
+
+// mapSource is a SolverSource of made up dependencies. The requirements are
+// keyed by name@version.
+type mapSource struct {
+	versions map[string][]string
+	reqs     map[string]map[string]string
+}
+
+func (m *mapSource) Versions(name string) ([]string, error) {
+	return m.versions[name], nil
+}
+
+func (m *mapSource) Requirements(name, version string) (map[string]string, error) {
+	return m.reqs[name+"@"+version], nil
+}
+
+func TestSolver(t *testing.T) {
+	src := &mapSource{
+		versions: map[string][]string{
+			"a": {"1.0.0", "1.1.0", "2.0.0"},
+			"b": {"1.0.0", "1.5.0", "2.0.0"},
+		},
+		reqs: map[string]map[string]string{
+			// The newest a needs a b the project does not allow.
+			"a@2.0.0": {"b": "^2.0.0"},
+			"a@1.1.0": {"b": "^1.5.0"},
+			"a@1.0.0": {"b": "^1.0.0"},
+		},
+	}
+
+	sol, err := NewSolver("app", src).Solve(map[string]string{"a": ">=1.0.0", "b": "^1.0.0"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	expected := map[string]string{"a": "1.1.0", "b": "1.5.0"}
+	if !reflect.DeepEqual(sol, expected) {
+		t.Errorf("Expected %v but got %v", expected, sol)
+	}
+
+	// The requirements are those of the solved versions.
+	s := NewSolver("app", src)
+	sol, _ = s.Solve(map[string]string{"a": ">=1.0.0", "b": "^1.0.0"})
+	reqs := []SolveRequirement{
+		{Name: "a", Version: ">=1.0.0", By: "app"},
+		{Name: "b", Version: "^1.0.0", By: "app"},
+		{Name: "b", Version: "^1.5.0", By: "a", ByVersion: "1.1.0"},
+	}
+	if r := s.Required(sol); !reflect.DeepEqual(r, reqs) {
+		t.Errorf("Expected the requirements %v but got %v", reqs, r)
+	}
+
+	// Constraints containing || are solved like any other.
+	sol, err = NewSolver("app", src).Solve(map[string]string{"a": "^1.0.0 || ^2.0.0", "b": "1.0.0 || 2.0.0"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	expected = map[string]string{"a": "2.0.0", "b": "2.0.0"}
+	if !reflect.DeepEqual(sol, expected) {
+		t.Errorf("Expected %v but got %v", expected, sol)
+	}
+
+	// Dependencies nothing asks a version of keep the version checked out.
+	sol, err = NewSolver("app", src).Solve(map[string]string{"c": ""})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if v, ok := sol["c"]; !ok || v != "" {
+		t.Errorf("Expected c to have an empty version but got %v", sol)
+	}
+}
+
+func TestSolverBranches(t *testing.T) {
+	src := &mapSource{
+		versions: map[string][]string{
+			"a": {"master", "stable", "1.0.0"},
+			"b": {"1.0.0", "2.0.0"},
+		},
+		reqs: map[string]map[string]string{
+			// The version checked out and the only tag need b 2.
+			"a@":       {"b": "^2.0.0"},
+			"a@1.0.0":  {"b": "^2.0.0"},
+			"a@master": {"b": "^2.0.0"},
+			"a@stable": {"b": "^1.0.0"},
+		},
+	}
+
+	s := NewSolver("app", src)
+	sol, err := s.Solve(map[string]string{"a": "", "b": "^1.0.0"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	expected := map[string]string{"a": "stable", "b": "1.0.0"}
+	if !reflect.DeepEqual(sol, expected) {
+		t.Errorf("Expected %v but got %v", expected, sol)
+	}
+
+	// The tags are tried, newest first, before the branches.
+	c := s.candidates("a", nil)
+	if !reflect.DeepEqual(c, []string{"", "1.0.0", "master", "stable"}) {
+		t.Errorf("Unexpected candidates %v", c)
+	}
+}
+
+func TestSolverConflict(t *testing.T) {
+	src := &mapSource{
+		versions: map[string][]string{
+			"a": {"1.0.0", "1.1.0"},
+			"b": {"1.0.0", "2.0.0"},
+			"c": {"1.0.0"},
+		},
+		reqs: map[string]map[string]string{
+			"a@1.0.0": {"b": "^2.0.0", "c": "^1.0.0"},
+			"a@1.1.0": {"b": "^2.0.0"},
+		},
+	}
+
+	_, err := NewSolver("app", src).Solve(map[string]string{"a": "^1.0.0", "b": "^1.0.0", "c": ""})
+	serr, ok := err.(*SolveError)
+	if !ok {
+		t.Fatalf("Expected a SolveError but got %v", err)
+	}
+
+	// Every version of a the project allows needs b 2 which the project does
+	// not allow. What the project asks of c plays no part.
+	expected := []SolveRequirement{
+		{Name: "a", Version: "^1.0.0", By: "app"},
+		{Name: "b", Version: "^1.0.0", By: "app"},
+		{Name: "b", Version: "^2.0.0", By: "a", ByVersion: "1.1.0"},
+		{Name: "b", Version: "^2.0.0", By: "a", ByVersion: "1.0.0"},
+	}
+	if !reflect.DeepEqual(serr.Requirements, expected) {
+		t.Errorf("Expected the requirements %v but got %v", expected, serr.Requirements)
+	}
+}
+
+// git runs a git command in a directory and returns its output.
+func git(t *testing.T, dir string, args ...string) string {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME=glide", "GIT_AUTHOR_EMAIL=glide@example.com",
+		"GIT_COMMITTER_NAME=glide", "GIT_COMMITTER_EMAIL=glide@example.com",
+	)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s failed: %s\n%s", strings.Join(args, " "), err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// gitRepo creates a git repository with a commit for each version, tagged
+// with it, holding the files given for it.
+func gitRepo(t *testing.T, dir string, versions []string, files map[string]map[string]string) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	git(t, dir, "init", "-q")
+	for _, v := range versions {
+		for name, content := range files[v] {
+			p := filepath.Join(dir, filepath.FromSlash(name))
+			if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := ioutil.WriteFile(p, []byte(content), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		git(t, dir, "add", "-A")
+		git(t, dir, "commit", "-q", "--allow-empty", "-m", v)
+		git(t, dir, "tag", v)
+	}
+}
+
+// testHome sets up an empty Glide home for a test and returns the function
+// restoring the previous one.
+func testHome(t *testing.T, dir string) func() {
+	old := gpath.Home()
+	gpath.SetHome(filepath.Join(dir, "home"))
+	cache.SetupReset()
+	return func() {
+		gpath.SetHome(old)
+		cache.SetupReset()
+	}
+}
+
+func TestCacheSource(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	dir, err := ioutil.TempDir("", "glide-solver")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	defer testHome(t, dir)()
+
+	gitRepo(t, filepath.Join(dir, "a"), []string{"1.0.0", "2.0.0"}, map[string]map[string]string{
+		"1.0.0": {"glide.yaml": "package: example.com/a\nimport:\n- package: example.com/b\n  version: ^1.0.0\n"},
+		"2.0.0": {"glide.yaml": "package: example.com/a\nimport:\n- package: example.com/b\n  version: ^2.0.0\n"},
+	})
+
+	conf := &cfg.Config{
+		Name: "example.com/app",
+		Imports: cfg.Dependencies{
+			{Name: "example.com/a", Repository: "file://" + filepath.Join(dir, "a"), VcsType: "git"},
+			{Name: "example.com/b"},
+		},
+	}
+	git(t, filepath.Join(dir, "a"), "branch", "stable", "1.0.0")
+	d := conf.Imports[0]
+	if err := VcsGet(d); err != nil {
+		t.Fatal(err)
+	}
+	key, err := cache.Key(d.Remote())
+	if err != nil {
+		t.Fatal(err)
+	}
+	cached := filepath.Join(cache.Location(), "src", key)
+	head := git(t, cached, "rev-parse", "HEAD")
+
+	imp, err := importer.New(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	src := newCacheSource(conf, imp)
+	reqs, err := src.Requirements("example.com/a", "1.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reqs["example.com/b"] != "^1.0.0" {
+		t.Errorf("Expected a 1.0.0 to ask for b ^1.0.0 but got %v", reqs)
+	}
+	if git(t, cached, "rev-parse", "HEAD") == head {
+		t.Fatal("Expected the version asked for to be checked out")
+	}
+
+	vers, err := src.Versions("example.com/a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range []string{"1.0.0", "2.0.0", "stable"} {
+		found := false
+		for _, ver := range vers {
+			found = found || ver == v
+		}
+		if !found {
+			t.Errorf("Expected %s in the versions of a but got %v", v, vers)
+		}
+	}
+
+	src.restore()
+	if h := git(t, cached, "rev-parse", "HEAD"); h != head {
+		t.Errorf("Expected %s to be checked out again but got %s", head, h)
+	}
+}
@@ -8,7 +8,6 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
-	"runtime"
 	"sort"
 	"strings"
 
@@ -16,6 +15,7 @@ import (
 	"github.com/Masterminds/glide/cfg"
 	"github.com/Masterminds/glide/msg"
 	gpath "github.com/Masterminds/glide/path"
+	"github.com/Masterminds/glide/util"
 	"github.com/Masterminds/semver"
 	v "github.com/Masterminds/vcs"
 )
@@ -30,15 +30,22 @@ func VcsUpdate(dep *cfg.Dependency, force bool, updated *UpdateTracker) error {
 		return nil
 	}
 
+	// A dependency filtered out is not marked as updated so it is still
+	// fetched when resolving for a platform it is used on.
+	if filterArchOs(dep) {
+		msg.Info("%s is not used for %s/%s.\n", dep.Name, util.GOOS, util.GOARCH)
+		return nil
+	}
+
 	if updated.Check(dep.Name) {
 		msg.Debug("%s was already updated, skipping", dep.Name)
 		return nil
 	}
 	updated.Add(dep.Name)
 
-	if filterArchOs(dep) {
-		msg.Info("%s is not used for %s/%s.\n", dep.Name, runtime.GOOS, runtime.GOARCH)
-		return nil
+	// Local path overrides are used as they are on disk.
+	if dep.Path != "" {
+		return checkLocal(dep)
 	}
 
 	key, err := cp.Key(dep.Remote())
@@ -155,6 +162,10 @@ func VcsVersion(dep *cfg.Dependency) error {
 		return nil
 	}
 
+	if dep.Path != "" {
+		return pinLocal(dep)
+	}
+
 	key, err := cp.Key(dep.Remote())
 	if err != nil {
 		msg.Die("Cache key generation error: %s", err)
@@ -162,6 +173,11 @@ func VcsVersion(dep *cfg.Dependency) error {
 	location := cp.Location()
 	cwd := filepath.Join(location, "src", key)
 
+	// A dependency not used on this platform may not be in the cache.
+	if _, err := os.Stat(cwd); os.IsNotExist(err) && filterArchOs(dep) {
+		return nil
+	}
+
 	// If there is no reference configured there is nothing to set.
 	if dep.Reference == "" {
 		// Before exiting update the pinned version
@@ -294,37 +310,12 @@ func VcsGet(dep *cfg.Dependency) error {
 }
 
 // filterArchOs indicates a dependency should be filtered out because it is
-// the wrong GOOS or GOARCH.
+// the wrong GOOS or GOARCH. The platform is the one dependencies are being
+// resolved for, which is the current one unless another is selected.
 //
 // FIXME: Should this be moved to the dependency package?
 func filterArchOs(dep *cfg.Dependency) bool {
-	found := false
-	if len(dep.Arch) > 0 {
-		for _, a := range dep.Arch {
-			if a == runtime.GOARCH {
-				found = true
-			}
-		}
-		// If it's not found, it should be filtered out.
-		if !found {
-			return true
-		}
-	}
-
-	found = false
-	if len(dep.Os) > 0 {
-		for _, o := range dep.Os {
-			if o == runtime.GOOS {
-				found = true
-			}
-		}
-		if !found {
-			return true
-		}
-
-	}
-
-	return false
+	return !dep.ForPlatform(util.GOOS, util.GOARCH)
 }
 
 // isBranch returns true if the given string is a branch in VCS.
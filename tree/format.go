@@ -0,0 +1,141 @@
+package tree
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// WriteDot writes the graph in the DOT language of Graphviz. The packages are
+// grouped in a cluster for each repository. Packages only imported through
+// tests are dashed and imports that lead to a cycle are red.
+func (g *Graph) WriteDot(w io.Writer) error {
+	var b bytes.Buffer
+	b.WriteString("digraph dependencies {\n")
+	b.WriteString("\tnode [shape=box];\n")
+
+	byRepo := map[string][]*Node{}
+	var loose []*Node
+	for _, n := range g.Packages.Nodes {
+		if n.Repo == "" {
+			loose = append(loose, n)
+			continue
+		}
+		byRepo[n.Repo] = append(byRepo[n.Repo], n)
+	}
+	for i, r := range g.Repositories.Nodes {
+		label := r.Name
+		if r.Version != "" {
+			label += "\n" + r.Version
+		}
+		fmt.Fprintf(&b, "\tsubgraph cluster_%d {\n", i)
+		fmt.Fprintf(&b, "\t\tlabel=%s;\n", strconv.Quote(label))
+		if r.Test {
+			b.WriteString("\t\tstyle=dashed;\n")
+		}
+		for _, n := range byRepo[r.Name] {
+			b.WriteString("\t\t" + dotNode(n))
+		}
+		b.WriteString("\t}\n")
+	}
+	for _, n := range loose {
+		b.WriteString("\t" + dotNode(n))
+	}
+
+	for _, e := range g.Packages.Edges {
+		var attrs []string
+		if e.Test {
+			attrs = append(attrs, "style=dashed")
+		}
+		if e.Cycle {
+			attrs = append(attrs, "color=red", `label="cycle"`)
+		}
+		fmt.Fprintf(&b, "\t%s -> %s%s;\n", strconv.Quote(e.From), strconv.Quote(e.To), dotAttrs(attrs))
+	}
+	b.WriteString("}\n")
+
+	_, err := w.Write(b.Bytes())
+	return err
+}
+
+func dotNode(n *Node) string {
+	attrs := []string{"tooltip=" + strconv.Quote(n.Loc)}
+	if n.Test {
+		attrs = append(attrs, "style=dashed")
+	}
+	return fmt.Sprintf("%s%s;\n", strconv.Quote(n.Name), dotAttrs(attrs))
+}
+
+func dotAttrs(attrs []string) string {
+	if len(attrs) == 0 {
+		return ""
+	}
+	s := " ["
+	for i, a := range attrs {
+		if i > 0 {
+			s += ", "
+		}
+		s += a
+	}
+	return s + "]"
+}
+
+// WriteGraphML writes the graph as GraphML. The package graph and the
+// repository graph are written as two graphs in the document. The ids of the
+// nodes are the names of the packages and repositories prefixed with p: and
+// r: respectively.
+func (g *Graph) WriteGraphML(w io.Writer) error {
+	var b bytes.Buffer
+	b.WriteString(xml.Header)
+	b.WriteString(`<graphml xmlns="http://graphml.graphdrawing.org/xmlns">` + "\n")
+	for _, k := range []struct{ id, on, name, typ string }{
+		{"loc", "node", "loc", "string"},
+		{"path", "node", "path", "string"},
+		{"repo", "node", "repo", "string"},
+		{"version", "node", "version", "string"},
+		{"test", "node", "test", "boolean"},
+		{"etest", "edge", "test", "boolean"},
+		{"cycle", "edge", "cycle", "boolean"},
+	} {
+		fmt.Fprintf(&b, "  <key id=%q for=%q attr.name=%q attr.type=%q/>\n", k.id, k.on, k.name, k.typ)
+	}
+	graphML(&b, "packages", "p:", g.Packages)
+	graphML(&b, "repositories", "r:", g.Repositories)
+	b.WriteString("</graphml>\n")
+
+	_, err := w.Write(b.Bytes())
+	return err
+}
+
+func graphML(b *bytes.Buffer, id, prefix string, l Layer) {
+	fmt.Fprintf(b, "  <graph id=%q edgedefault=\"directed\">\n", id)
+	for _, n := range l.Nodes {
+		fmt.Fprintf(b, "    <node id=\"%s\">\n", xmlEscape(prefix+n.Name))
+		data := func(key, value string) {
+			if value != "" {
+				fmt.Fprintf(b, "      <data key=%q>%s</data>\n", key, xmlEscape(value))
+			}
+		}
+		data("loc", n.Loc)
+		data("path", n.Path)
+		data("repo", n.Repo)
+		data("version", n.Version)
+		data("test", strconv.FormatBool(n.Test))
+		b.WriteString("    </node>\n")
+	}
+	for _, e := range l.Edges {
+		fmt.Fprintf(b, "    <edge source=\"%s\" target=\"%s\">\n", xmlEscape(prefix+e.From), xmlEscape(prefix+e.To))
+		fmt.Fprintf(b, "      <data key=\"etest\">%t</data>\n", e.Test)
+		fmt.Fprintf(b, "      <data key=\"cycle\">%t</data>\n", e.Cycle)
+		b.WriteString("    </edge>\n")
+	}
+	b.WriteString("  </graph>\n")
+}
+
+func xmlEscape(s string) string {
+	var b bytes.Buffer
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
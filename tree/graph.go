@@ -0,0 +1,271 @@
+package tree
+
+import (
+	"container/list"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Masterminds/glide/dependency"
+	"github.com/Masterminds/glide/msg"
+	"github.com/Masterminds/glide/util"
+)
+
+// Graph is the import graph of a project. It holds the graph of the packages
+// and the graph of the repositories they are in.
+type Graph struct {
+	Packages     Layer `json:"packages"`
+	Repositories Layer `json:"repositories"`
+}
+
+// Layer is a graph of packages or of repositories.
+type Layer struct {
+	Nodes []*Node `json:"nodes"`
+	Edges []*Edge `json:"edges"`
+}
+
+// Node is a package or a repository in a graph. Test is set when it is only
+// imported through the tests of the project.
+type Node struct {
+	Name    string `json:"name"`
+	Loc     string `json:"loc"`
+	Path    string `json:"path,omitempty"`
+	Repo    string `json:"repo,omitempty"`
+	Version string `json:"version,omitempty"`
+	Test    bool   `json:"test"`
+}
+
+// Edge is an import between two nodes. Test is set when the import is only
+// made through the tests of the project and Cycle when it leads back to a
+// package that imports it.
+type Edge struct {
+	From  string `json:"from"`
+	To    string `json:"to"`
+	Test  bool   `json:"test"`
+	Cycle bool   `json:"cycle"`
+}
+
+type builder struct {
+	b        *util.BuildCtxt
+	core     bool
+	g        *Graph
+	nodes    map[string]*Node
+	edges    map[string]*Edge
+	expanded map[string]bool
+}
+
+// Build builds the package graph of a project. The imports of the packages
+// of the project are followed first and then the imports of their tests so
+// the packages only reached through tests are marked as such. The repository
+// graph is left empty. See Graph.Group.
+//
+// Params:
+//  - b (*util.BuildCtxt): The build context to find packages with
+//  - basedir (string): The directory of the project
+//  - myName (string): The import path of the project
+//  - core (bool): Whether to include the packages of the standard library
+func Build(b *util.BuildCtxt, basedir, myName string, core bool) *Graph {
+	gb := &builder{
+		b:        b,
+		core:     core,
+		g:        &Graph{Packages: Layer{Nodes: []*Node{}, Edges: []*Edge{}}, Repositories: Layer{Nodes: []*Node{}, Edges: []*Edge{}}},
+		nodes:    map[string]*Node{},
+		edges:    map[string]*Edge{},
+		expanded: map[string]bool{},
+	}
+
+	type local struct {
+		name, dir string
+	}
+	var locals []local
+	filepath.Walk(basedir, func(p string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !dependency.IsSrcDir(fi) {
+			if fi.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		rel, err := filepath.Rel(basedir, p)
+		if err != nil {
+			return nil
+		}
+		name := path.Join(myName, filepath.ToSlash(rel))
+		if _, err := b.ImportDir(p, 0); err != nil && strings.HasPrefix(err.Error(), "no buildable Go source") {
+			return nil
+		}
+		locals = append(locals, local{name, p})
+		gb.node(name, dependency.LocLocal.String(), p, false)
+		gb.expanded[name] = true
+		return nil
+	})
+
+	for _, l := range locals {
+		imps, _ := packageImports(b, l.dir)
+		gb.visitAll(l.name, l.dir, imps, false)
+	}
+	for _, l := range locals {
+		_, timps := packageImports(b, l.dir)
+		gb.visitAll(l.name, l.dir, timps, true)
+	}
+
+	sort.Slice(gb.g.Packages.Nodes, func(i, j int) bool { return gb.g.Packages.Nodes[i].Name < gb.g.Packages.Nodes[j].Name })
+	return gb.g
+}
+
+func (gb *builder) visitAll(from, dir string, imps []string, test bool) {
+	l := list.New()
+	l.PushBack(from)
+	for _, imp := range imps {
+		gb.visit(from, imp, dir, test, l)
+	}
+}
+
+// visit adds an import to the graph and follows the imports of the imported
+// package. The stack holds the packages imported along the way so an import
+// of one of them is marked as a cycle rather than followed.
+func (gb *builder) visit(from, name, cwd string, test bool, stack *list.List) {
+	if name == from {
+		return
+	}
+	n, ok := gb.nodes[name]
+	if !ok {
+		found := findPkg(gb.b, name, cwd)
+		if !gb.core && found.Loc == dependency.LocGoroot || found.Loc == dependency.LocCgo {
+			return
+		}
+		n = gb.node(name, found.Loc.String(), found.Path, test)
+	} else if !test {
+		n.Test = false
+	}
+
+	e := gb.edge(from, name, test)
+	if findInList(name, stack) {
+		e.Cycle = true
+		return
+	}
+
+	if gb.expanded[name] || n.Path == "" {
+		return
+	}
+	gb.expanded[name] = true
+
+	imps, _ := packageImports(gb.b, n.Path)
+	cl := copyList(stack)
+	cl.PushBack(name)
+	for _, imp := range imps {
+		gb.visit(name, imp, n.Path, test, cl)
+	}
+}
+
+func (gb *builder) node(name, loc, p string, test bool) *Node {
+	n := &Node{Name: name, Loc: loc, Path: p, Test: test}
+	gb.nodes[name] = n
+	gb.g.Packages.Nodes = append(gb.g.Packages.Nodes, n)
+	return n
+}
+
+func (gb *builder) edge(from, to string, test bool) *Edge {
+	key := from + " " + to
+	if e, ok := gb.edges[key]; ok {
+		if !test {
+			e.Test = false
+		}
+		return e
+	}
+	e := &Edge{From: from, To: to, Test: test}
+	gb.edges[key] = e
+	gb.g.Packages.Edges = append(gb.g.Packages.Edges, e)
+	return e
+}
+
+// packageImports returns the imports, and the test imports, of the package in
+// a directory. Its subdirectories are not included.
+func packageImports(b *util.BuildCtxt, dir string) ([]string, []string) {
+	var imps, timps []string
+	pkg, err := b.ImportDir(dir, 0)
+	if err != nil && strings.HasPrefix(err.Error(), "found packages ") {
+		imps, timps, err = dependency.IterativeScan(dir)
+		if err != nil {
+			msg.Err("Error walking dependencies for %s: %s", dir, err)
+		}
+	} else if err != nil {
+		if !strings.HasPrefix(err.Error(), "no buildable Go source") {
+			msg.Warn("Error: %s (%s)", err, dir)
+		}
+	} else {
+		imps = pkg.Imports
+		for _, t := range append(pkg.TestImports, pkg.XTestImports...) {
+			if !containsString(timps, t) {
+				timps = append(timps, t)
+			}
+		}
+	}
+	sort.Strings(imps)
+	sort.Strings(timps)
+	return imps, timps
+}
+
+// Group fills in the repository of each package and builds the repository
+// graph. Packages in the standard library are left out of it. An edge
+// between repositories is a test one when all of the imports it stands for
+// are and a cycle when any of them is.
+//
+// Params:
+//  - root (func(string) string): Returns the root package of a package
+//  - version (func(string) string): Returns the version of a root package
+func (g *Graph) Group(root func(string) string, version func(string) string) {
+	repos := map[string]*Node{}
+	for _, n := range g.Packages.Nodes {
+		switch n.Loc {
+		case dependency.LocGoroot.String(), dependency.LocCgo.String(), dependency.LocAppengine.String(), dependency.LocRelative.String():
+			continue
+		}
+		n.Repo = root(n.Name)
+		n.Version = version(n.Repo)
+		r, ok := repos[n.Repo]
+		if !ok {
+			r = &Node{Name: n.Repo, Loc: n.Loc, Version: n.Version, Test: true}
+			repos[n.Repo] = r
+			g.Repositories.Nodes = append(g.Repositories.Nodes, r)
+		}
+		if !n.Test {
+			r.Test = false
+		}
+	}
+	sort.Slice(g.Repositories.Nodes, func(i, j int) bool { return g.Repositories.Nodes[i].Name < g.Repositories.Nodes[j].Name })
+
+	pkgs := map[string]*Node{}
+	for _, n := range g.Packages.Nodes {
+		pkgs[n.Name] = n
+	}
+	edges := map[string]*Edge{}
+	for _, e := range g.Packages.Edges {
+		from, to := pkgs[e.From].Repo, pkgs[e.To].Repo
+		if from == "" || to == "" || from == to {
+			continue
+		}
+		key := from + " " + to
+		re, ok := edges[key]
+		if !ok {
+			re = &Edge{From: from, To: to, Test: true}
+			edges[key] = re
+			g.Repositories.Edges = append(g.Repositories.Edges, re)
+		}
+		re.Test = re.Test && e.Test
+		re.Cycle = re.Cycle || e.Cycle
+	}
+}
+
+func containsString(l []string, s string) bool {
+	for _, v := range l {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
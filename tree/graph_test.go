@@ -0,0 +1,121 @@
+package tree
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+)
+
+func testGraph() *Graph {
+	g := &Graph{}
+	g.Packages.Nodes = []*Node{
+		{Name: "example.com/app", Loc: "local"},
+		{Name: "example.com/a", Loc: "vendor"},
+		{Name: "example.com/a/sub", Loc: "vendor"},
+		{Name: "example.com/b", Loc: "vendor", Test: true},
+		{Name: "fmt", Loc: "goroot"},
+	}
+	g.Packages.Edges = []*Edge{
+		{From: "example.com/app", To: "example.com/a"},
+		{From: "example.com/app", To: "fmt"},
+		{From: "example.com/a", To: "example.com/a/sub"},
+		{From: "example.com/a/sub", To: "example.com/a", Cycle: true},
+		{From: "example.com/app", To: "example.com/b", Test: true},
+		{From: "example.com/b", To: "example.com/a/sub", Test: true},
+	}
+	g.Group(func(pkg string) string {
+		if strings.HasPrefix(pkg, "example.com/a/") {
+			return "example.com/a"
+		}
+		return pkg
+	}, func(root string) string {
+		if root == "example.com/a" {
+			return "1234abcd"
+		}
+		return ""
+	})
+	return g
+}
+
+func TestGraphGroup(t *testing.T) {
+	g := testGraph()
+
+	if g.Packages.Nodes[2].Repo != "example.com/a" || g.Packages.Nodes[2].Version != "1234abcd" {
+		t.Errorf("Expected example.com/a/sub to be in example.com/a at 1234abcd but got %+v", g.Packages.Nodes[2])
+	}
+	if g.Packages.Nodes[4].Repo != "" {
+		t.Errorf("Expected fmt to be in no repository but got %s", g.Packages.Nodes[4].Repo)
+	}
+
+	var repos []string
+	for _, n := range g.Repositories.Nodes {
+		repos = append(repos, n.Name)
+		if n.Name == "example.com/b" && !n.Test {
+			t.Error("Expected example.com/b to only be imported by tests")
+		}
+	}
+	if strings.Join(repos, " ") != "example.com/a example.com/app example.com/b" {
+		t.Errorf("Unexpected repositories %v", repos)
+	}
+
+	// Imports within a repository, including the cycle, are not edges between
+	// repositories.
+	expected := []Edge{
+		{From: "example.com/app", To: "example.com/a"},
+		{From: "example.com/app", To: "example.com/b", Test: true},
+		{From: "example.com/b", To: "example.com/a", Test: true},
+	}
+	if len(g.Repositories.Edges) != len(expected) {
+		t.Fatalf("Expected %d repository edges but got %d", len(expected), len(g.Repositories.Edges))
+	}
+	for i, e := range g.Repositories.Edges {
+		if *e != expected[i] {
+			t.Errorf("Expected the edge %+v but got %+v", expected[i], *e)
+		}
+	}
+}
+
+func TestGraphFormats(t *testing.T) {
+	g := testGraph()
+
+	var b bytes.Buffer
+	if err := g.WriteDot(&b); err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{
+		`label="example.com/a\n1234abcd";`,
+		`"example.com/a/sub" -> "example.com/a" [color=red, label="cycle"];`,
+		`"example.com/app" -> "example.com/b" [style=dashed];`,
+		`"fmt" [tooltip="goroot"];`,
+	} {
+		if !strings.Contains(b.String(), s) {
+			t.Errorf("Expected the dot output to contain %s but got:\n%s", s, b.String())
+		}
+	}
+
+	b.Reset()
+	if err := g.WriteGraphML(&b); err != nil {
+		t.Fatal(err)
+	}
+	var doc struct {
+		Graphs []struct {
+			ID    string `xml:"id,attr"`
+			Nodes []struct {
+				ID string `xml:"id,attr"`
+			} `xml:"node"`
+			Edges []struct {
+				Source string `xml:"source,attr"`
+			} `xml:"edge"`
+		} `xml:"graph"`
+	}
+	if err := xml.Unmarshal(b.Bytes(), &doc); err != nil {
+		t.Fatalf("Unable to parse the GraphML output: %s", err)
+	}
+	if len(doc.Graphs) != 2 || len(doc.Graphs[0].Nodes) != 5 || len(doc.Graphs[1].Edges) != 3 {
+		t.Errorf("Unexpected GraphML output:\n%s", b.String())
+	}
+	if doc.Graphs[1].Nodes[0].ID != "r:example.com/a" {
+		t.Errorf("Expected the repository ids to be prefixed but got %s", doc.Graphs[1].Nodes[0].ID)
+	}
+}
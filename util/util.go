@@ -11,6 +11,7 @@ import (
 	"os/exec"
 	"path/filepath"
 	"regexp"
+	"runtime"
 	"strings"
 
 	"github.com/Masterminds/vcs"
@@ -22,6 +23,16 @@ import (
 // other needs arise it may need to be re-written.
 var ResolveCurrent = false
 
+// GOOS and GOARCH are the platform dependencies are resolved for when
+// ResolveCurrent is set and the platform dependencies limited to an os or arch
+// are checked against. They default to the current platform and are changed
+// while resolving for each of several platforms. Like ResolveCurrent they are
+// not concurrently safe.
+var (
+	GOOS   = runtime.GOOS
+	GOARCH = runtime.GOARCH
+)
+
 // goRoot caches the GOROOT variable for build contexts. If $GOROOT is not set in
 // the user's environment, then the context's root path is 'go env GOROOT'.
 var goRoot string
@@ -299,6 +310,12 @@ func GetBuildContext() (*BuildCtxt, error) {
 		// This tells the context scanning to skip filtering on +build flags or
 		// file names.
 		buildContext.UseAllFiles = true
+	} else if GOOS != runtime.GOOS || GOARCH != runtime.GOARCH {
+		// Resolve for another platform. Cgo is only used when building for
+		// the current one.
+		buildContext.GOOS = GOOS
+		buildContext.GOARCH = GOARCH
+		buildContext.CgoEnabled = false
 	}
 
 	buildContext.GOROOT = goRoot
@@ -0,0 +1,92 @@
+// Package vndr provides basic importing of the vendor.conf files used by vndr
+// and trash.
+package vndr
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Masterminds/glide/cfg"
+	"github.com/Masterminds/glide/msg"
+	gpath "github.com/Masterminds/glide/path"
+	"github.com/Masterminds/glide/util"
+)
+
+// Has returns true if this dir has a vendor.conf file.
+func Has(dir string) bool {
+	path := filepath.Join(dir, "vendor.conf")
+	fi, err := os.Stat(path)
+	return err == nil && !fi.IsDir()
+}
+
+// Parse parses a vendor.conf file.
+//
+// Each line lists an import path, a version, and optionally the repository to
+// fetch it from. Blank lines and # comments are skipped.
+func Parse(dir string) ([]*cfg.Dependency, error) {
+	path := filepath.Join(dir, "vendor.conf")
+	if fi, err := os.Stat(path); err != nil || fi.IsDir() {
+		return []*cfg.Dependency{}, nil
+	}
+
+	msg.Info("Found vendor.conf file in %s", gpath.StripBasepath(dir))
+	msg.Info("--> Parsing vendor.conf metadata...")
+	buf := []*cfg.Dependency{}
+	file, err := os.Open(path)
+	if err != nil {
+		return buf, err
+	}
+	defer file.Close()
+
+	seen := map[string]*cfg.Dependency{}
+	scanner := bufio.NewScanner(file)
+	n := 0
+	for scanner.Scan() {
+		n++
+		line := scanner.Text()
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		parts := strings.Fields(line)
+		if len(parts) == 0 {
+			continue
+		}
+		if len(parts) < 2 || len(parts) > 3 {
+			return buf, fmt.Errorf("%s:%d: expected an import path, a version, and an optional repository", path, n)
+		}
+
+		pkg, sub := util.NormalizeName(parts[0])
+		dep, ok := seen[pkg]
+		if !ok {
+			dep = &cfg.Dependency{
+				Name:      pkg,
+				Reference: parts[1],
+			}
+			if len(parts) == 3 {
+				dep.Repository = repository(parts[2])
+			}
+			seen[pkg] = dep
+			buf = append(buf, dep)
+		}
+		if len(sub) > 0 && !dep.HasSubpackage(sub) {
+			dep.Subpackages = append(dep.Subpackages, sub)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return buf, err
+	}
+
+	return buf, nil
+}
+
+// repository converts the repository column to a URL. It may be a URL or an
+// import path style location such as github.com/fork/bar.
+func repository(r string) string {
+	if strings.Contains(r, "://") || strings.Contains(r, "@") {
+		return r
+	}
+	return "https://" + r
+}
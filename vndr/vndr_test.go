@@ -0,0 +1,58 @@
+package vndr
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	dir, err := ioutil.TempDir("", "glide-vndr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	data := `# dependencies
+github.com/foo/bar v1.2.0
+github.com/foo/baz 3864e76763d94a6df2f9960b16a20a33da9f9a66 https://github.com/fork/baz.git # a fork
+
+github.com/foo/qux/sub master github.com/fork/qux
+`
+	if err := ioutil.WriteFile(filepath.Join(dir, "vendor.conf"), []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if !Has(dir) {
+		t.Fatal("Expected vendor.conf to be found")
+	}
+
+	deps, err := Parse(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []struct{ name, ref, repo string }{
+		{"github.com/foo/bar", "v1.2.0", ""},
+		{"github.com/foo/baz", "3864e76763d94a6df2f9960b16a20a33da9f9a66", "https://github.com/fork/baz.git"},
+		{"github.com/foo/qux", "master", "https://github.com/fork/qux"},
+	}
+	if len(deps) != len(expected) {
+		t.Fatalf("Expected %d dependencies but got %d", len(expected), len(deps))
+	}
+	for i, e := range expected {
+		d := deps[i]
+		if d.Name != e.name || d.Reference != e.ref || d.Repository != e.repo {
+			t.Errorf("Expected %s %q %q but got %s %q %q", e.name, e.ref, e.repo, d.Name, d.Reference, d.Repository)
+		}
+	}
+	if len(deps[2].Subpackages) != 1 || deps[2].Subpackages[0] != "sub" {
+		t.Errorf("Unexpected subpackages %v", deps[2].Subpackages)
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(dir, "vendor.conf"), []byte("github.com/foo/bar\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Parse(dir); err == nil {
+		t.Error("Expected an error for a line without a version")
+	}
+}